@@ -0,0 +1,64 @@
+package apply
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/commands/certs"
+	"github.com/daticahealth/cli/commands/files"
+	"github.com/daticahealth/cli/commands/maintenance"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/commands/sites"
+	"github.com/daticahealth/cli/commands/vars"
+	"github.com/daticahealth/cli/commands/worker"
+	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/lib/prompts"
+)
+
+func CmdApply(filePath string, skipConfirm bool, ip prompts.IPrompts, is services.IServices, iv vars.IVars, isites sites.ISites, ic certs.ICerts, iw worker.IWorker, ifiles files.IFiles, im maintenance.IMaintenance, ij jobs.IJobs) error {
+	m, err := ReadManifest(filePath)
+	if err != nil {
+		return err
+	}
+	p := &planner{is: is, iv: iv, isites: isites, ic: ic, iw: iw, ifiles: ifiles, im: im, ij: ij}
+	changes, err := p.Plan(m)
+	if err != nil {
+		return err
+	}
+	printPlan(changes)
+	pending := 0
+	for _, c := range changes {
+		if c.apply != nil {
+			pending++
+		}
+	}
+	if pending == 0 {
+		return nil
+	}
+	if !skipConfirm {
+		if err = ip.YesNo("", "Would you like to apply these changes? (y/n) "); err != nil {
+			return err
+		}
+	}
+	sitesChanged := false
+	applied := 0
+	for _, c := range changes {
+		if c.apply == nil {
+			continue
+		}
+		logrus.Printf("%s %s", actionSymbols[c.Action], c.Resource)
+		if err = c.apply(); err != nil {
+			return fmt.Errorf("Failed to %s %s after applying %d of %d changes: %s", c.Action, c.Resource, applied, pending, err)
+		}
+		applied++
+		if strings.HasPrefix(c.Resource, "site ") || strings.HasPrefix(c.Resource, "cert ") {
+			sitesChanged = true
+		}
+	}
+	logrus.Printf("Applied %d changes", applied)
+	if sitesChanged {
+		logrus.Println("To make your site and cert changes go live, you must redeploy your service proxy with the \"datica redeploy service_proxy\" command")
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daticahealth/cli/commands/certs"
+	"github.com/daticahealth/cli/commands/files"
+	"github.com/daticahealth/cli/commands/maintenance"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/commands/sites"
+	"github.com/daticahealth/cli/commands/vars"
+	"github.com/daticahealth/cli/commands/worker"
+	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/test"
+)
+
+const proxyID = "proxy"
+
+var applyTests = []struct {
+	manifest  string
+	changes   int
+	expectErr bool
+}{
+	{"", 0, false},
+	{"vars:\n  " + test.SvcLabel + ":\n    A: \"1\"\n", 1, false},
+	{"vars:\n  " + test.SvcLabel + ":\n    A: \"1\"\n    B: \"2\"\n", 2, false},
+	{"vars:\n  " + test.SvcLabel + ":\n    A: \"2\"\n    B: \"2\"\n    C: \"3\"\n", 2, false},
+	{"maintenance:\n  " + test.SvcLabel + ": true\n", 1, false},
+	{"maintenance:\n  " + test.SvcLabel + ": false\n", 0, false},
+	{"vars:\n  invalid-svc:\n    A: \"1\"\n", 0, true},
+	{"worker:\n  " + test.SvcLabel + ":\n    worker: 0\n", 0, true},
+	{"vars: [", 0, true},
+}
+
+func TestApply(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	dir, err := ioutil.TempDir("", "apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var sets, unsets, enables int
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s","type":"code"},{"id":"%s","label":"%s","type":"utility"}]`, test.SvcID, test.SvcLabel, proxyID, serviceProxy))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+test.SvcID+"/env",
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case "GET":
+				fmt.Fprint(w, `{"A":"1","C":"3"}`)
+			case "POST":
+				sets++
+				fmt.Fprint(w, `{}`)
+			default:
+				t.Errorf("Unexpected method %s", r.Method)
+			}
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+test.SvcID+"/env/C",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "DELETE")
+			unsets++
+			w.WriteHeader(204)
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+proxyID+"/maintenance",
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case "GET":
+				fmt.Fprint(w, `[]`)
+			case "POST":
+				enables++
+				fmt.Fprint(w, `{}`)
+			default:
+				t.Errorf("Unexpected method %s", r.Method)
+			}
+		},
+	)
+
+	for _, data := range applyTests {
+		t.Logf("Data: %+v", data)
+		sets, unsets, enables = 0, 0, 0
+		filePath := filepath.Join(dir, "datica.yml")
+		if err := ioutil.WriteFile(filePath, []byte(data.manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// test
+		err := CmdApply(filePath, true, &test.FakePrompts{}, services.New(settings), vars.New(settings), sites.New(settings), certs.New(settings), worker.New(settings), files.New(settings), maintenance.New(settings), jobs.New(settings))
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if applied := sets + unsets + enables; applied != data.changes {
+			t.Errorf("Expected %d changes to be applied, got %d", data.changes, applied)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package apply
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/commands/certs"
+	"github.com/daticahealth/cli/commands/files"
+	"github.com/daticahealth/cli/commands/maintenance"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/commands/sites"
+	"github.com/daticahealth/cli/commands/vars"
+	"github.com/daticahealth/cli/commands/worker"
+	"github.com/daticahealth/cli/config"
+	"github.com/daticahealth/cli/lib/auth"
+	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/daticahealth/cli/lib/prompts"
+	"github.com/daticahealth/cli/models"
+	"github.com/jault3/mow.cli"
+)
+
+const manifestHelp = "The manifest is a YAML file describing the desired state of an environment. Every section is optional and only the sections given are compared against the environment:\n\n" +
+	"<pre>\n" +
+	"vars:\n" +
+	"  app01:\n" +
+	"    RAILS_ENV: production\n" +
+	"certs:\n" +
+	"  - name: example.com\n" +
+	"    pub_key: ./example.com.crt\n" +
+	"    priv_key: ./example.com.key\n" +
+	"sites:\n" +
+	"  - name: example.com\n" +
+	"    service: app01\n" +
+	"    cert: example.com\n" +
+	"    values:\n" +
+	"      clientMaxBodySize: 20m\n" +
+	"worker:\n" +
+	"  app01:\n" +
+	"    worker: 2\n" +
+	"files:\n" +
+	"  - name: /etc/nginx/sites-enabled/example.conf\n" +
+	"    path: ./example.conf\n" +
+	"    mode: \"0644\"\n" +
+	"maintenance:\n" +
+	"  app01: false\n" +
+	"</pre>\n\n" +
+	"For each service listed under <code>vars</code> or <code>worker</code>, and for the list of <code>sites</code>, the manifest is the complete desired state, so environment variables, worker targets, and sites not in the manifest are removed. " +
+	"Certs and service files are only ever created. Relative paths are resolved against the directory of the manifest. "
+
+// Cmd is the contract between the user and the CLI. This specifies the command
+// name, arguments, and required/optional arguments and flags for the command.
+var Cmd = models.Command{
+	Name:      "apply",
+	ShortHelp: "Make an environment match a manifest",
+	LongHelp: "<code>apply</code> compares a manifest against your environment, prints the plan, and makes only the changes needed for the environment to match the manifest. " +
+		"You will be asked to confirm the plan before any changes are made. " +
+		manifestHelp +
+		"Here is a sample command\n\n" +
+		"<pre>\ndatica -E \"<your_env_name>\" apply -f datica.yml\n</pre>",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(cmd *cli.Cmd) {
+			filePath := cmd.StringOpt("f file", "datica.yml", "The path to the manifest")
+			skipConfirm := cmd.BoolOpt("y yes", false, "Skip the confirmation prompt")
+			cmd.Action = func() {
+				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				if err := config.CheckRequiredAssociation(settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				err := CmdApply(*filePath, *skipConfirm, prompts.New(), services.New(settings), vars.New(settings), sites.New(settings), certs.New(settings), worker.New(settings), files.New(settings), maintenance.New(settings), jobs.New(settings))
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			cmd.Spec = "[-f] [-y]"
+		}
+	},
+}
+
+// PlanCmd is the contract between the user and the CLI. This specifies the
+// command name, arguments, and required/optional arguments and flags for the
+// command.
+var PlanCmd = models.Command{
+	Name:      "plan",
+	ShortHelp: "Show the changes needed for an environment to match a manifest",
+	LongHelp: "<code>plan</code> compares a manifest against your environment and prints every difference without changing anything. " +
+		"Lines starting with <code>+</code> are created, <code>~</code> are updated, <code>-</code> are removed, and <code>!</code> are differences that <code>apply</code> cannot fix. " +
+		manifestHelp +
+		"Here is a sample command\n\n" +
+		"<pre>\ndatica -E \"<your_env_name>\" plan -f datica.yml\n</pre>",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(cmd *cli.Cmd) {
+			filePath := cmd.StringOpt("f file", "datica.yml", "The path to the manifest")
+			cmd.Action = func() {
+				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				if err := config.CheckRequiredAssociation(settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				err := CmdPlan(*filePath, output.New(settings), services.New(settings), vars.New(settings), sites.New(settings), certs.New(settings), worker.New(settings), files.New(settings), maintenance.New(settings), jobs.New(settings))
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			cmd.Spec = "[-f]"
+		}
+	},
+}
@@ -0,0 +1,104 @@
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Manifest describes the desired state of an environment. Only the sections
+// present in the manifest are compared against the environment. Within vars,
+// worker, and sites, each listed service or site list is the complete desired
+// state, so anything extra found in the environment is removed. Certs and files
+// are only ever created.
+type Manifest struct {
+	Vars        map[string]map[string]string `yaml:"vars"`
+	Sites       []Site                       `yaml:"sites"`
+	Certs       []Cert                       `yaml:"certs"`
+	Worker      map[string]map[string]int    `yaml:"worker"`
+	Files       []File                       `yaml:"files"`
+	Maintenance map[string]bool              `yaml:"maintenance"`
+}
+
+// Site is a site on the service proxy along with its nginx site values
+type Site struct {
+	Name    string                 `yaml:"name"`
+	Service string                 `yaml:"service"`
+	Cert    string                 `yaml:"cert"`
+	Values  map[string]interface{} `yaml:"values"`
+}
+
+// Cert is a cert on the service proxy. Either both key paths or lets_encrypt
+// must be given.
+type Cert struct {
+	Name        string `yaml:"name"`
+	PubKey      string `yaml:"pub_key"`
+	PrivKey     string `yaml:"priv_key"`
+	LetsEncrypt bool   `yaml:"lets_encrypt"`
+}
+
+// File is a service file whose contents are read from a local path
+type File struct {
+	Name    string `yaml:"name"`
+	Path    string `yaml:"path"`
+	Mode    string `yaml:"mode"`
+	Service string `yaml:"service"`
+}
+
+// ReadManifest parses the manifest at the given path. Relative paths inside the
+// manifest are resolved against the manifest's directory.
+func ReadManifest(filePath string) (*Manifest, error) {
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err = yaml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("Invalid manifest %s: %s", filePath, err)
+	}
+	dir := filepath.Dir(filePath)
+	for i, c := range m.Certs {
+		if c.Name == "" {
+			return nil, fmt.Errorf("Invalid manifest %s: every cert needs a name", filePath)
+		}
+		if !c.LetsEncrypt && (c.PubKey == "" || c.PrivKey == "") {
+			return nil, fmt.Errorf("Invalid manifest %s: cert %s needs either pub_key and priv_key or lets_encrypt", filePath, c.Name)
+		}
+		m.Certs[i].PubKey = resolvePath(dir, c.PubKey)
+		m.Certs[i].PrivKey = resolvePath(dir, c.PrivKey)
+	}
+	for i, f := range m.Files {
+		if f.Name == "" || f.Path == "" {
+			return nil, fmt.Errorf("Invalid manifest %s: every file needs a name and a path", filePath)
+		}
+		if f.Service == "" {
+			m.Files[i].Service = serviceProxy
+		}
+		if f.Mode == "" {
+			m.Files[i].Mode = "0644"
+		}
+		m.Files[i].Path = resolvePath(dir, f.Path)
+	}
+	for _, s := range m.Sites {
+		if s.Name == "" || s.Service == "" {
+			return nil, fmt.Errorf("Invalid manifest %s: every site needs a name and a service", filePath)
+		}
+	}
+	for svcName, targets := range m.Worker {
+		for target, scale := range targets {
+			if scale <= 0 {
+				return nil, fmt.Errorf("Invalid manifest %s: worker target %s for service %s must have a scale greater than 0. Leave the target out to remove it", filePath, target, svcName)
+			}
+		}
+	}
+	return &m, nil
+}
+
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
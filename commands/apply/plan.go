@@ -0,0 +1,538 @@
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/commands/certs"
+	"github.com/daticahealth/cli/commands/files"
+	"github.com/daticahealth/cli/commands/maintenance"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/commands/sites"
+	"github.com/daticahealth/cli/commands/vars"
+	"github.com/daticahealth/cli/commands/worker"
+	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/daticahealth/cli/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+const serviceProxy = "service_proxy"
+
+// Actions a change can take. Replace removes a resource and creates it again
+// because the API has no way to update it in place. Drift is a difference that
+// apply cannot fix at all.
+const (
+	Create  = "create"
+	Update  = "update"
+	Replace = "replace"
+	Delete  = "delete"
+	Drift   = "drift"
+)
+
+var actionSymbols = map[string]string{
+	Create:  "+",
+	Update:  "~",
+	Replace: "-/+",
+	Delete:  "-",
+	Drift:   "!",
+}
+
+// Change is a single difference between the manifest and the environment
+type Change struct {
+	Action   string `json:"action"`
+	Resource string `json:"resource"`
+	Detail   string `json:"detail,omitempty"`
+
+	apply func() error
+}
+
+// planner compares a manifest against the environment using the same
+// interfaces as the individual commands.
+type planner struct {
+	is     services.IServices
+	iv     vars.IVars
+	isites sites.ISites
+	ic     certs.ICerts
+	iw     worker.IWorker
+	ifiles files.IFiles
+	im     maintenance.IMaintenance
+	ij     jobs.IJobs
+
+	services map[string]models.Service
+}
+
+func CmdPlan(filePath string, out output.IOutput, is services.IServices, iv vars.IVars, isites sites.ISites, ic certs.ICerts, iw worker.IWorker, ifiles files.IFiles, im maintenance.IMaintenance, ij jobs.IJobs) error {
+	m, err := ReadManifest(filePath)
+	if err != nil {
+		return err
+	}
+	p := &planner{is: is, iv: iv, isites: isites, ic: ic, iw: iw, ifiles: ifiles, im: im, ij: ij}
+	changes, err := p.Plan(m)
+	if err != nil {
+		return err
+	}
+	return out.Render(changes, func() error {
+		printPlan(changes)
+		return nil
+	})
+}
+
+// Plan builds the list of changes needed to bring the environment in line with
+// the manifest.
+func (p *planner) Plan(m *Manifest) ([]Change, error) {
+	svcs, err := p.is.List()
+	if err != nil {
+		return nil, err
+	}
+	p.services = map[string]models.Service{}
+	for _, svc := range *svcs {
+		p.services[svc.Label] = svc
+	}
+	changes := []Change{}
+	for _, section := range []func(*Manifest) ([]Change, error){p.planVars, p.planCerts, p.planSites, p.planWorkers, p.planFiles, p.planMaintenance} {
+		c, err := section(m)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c...)
+	}
+	return changes, nil
+}
+
+func (p *planner) service(label string) (*models.Service, error) {
+	svc, ok := p.services[label]
+	if !ok {
+		return nil, fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"datica services list\" command.", label)
+	}
+	return &svc, nil
+}
+
+func (p *planner) planVars(m *Manifest) ([]Change, error) {
+	changes := []Change{}
+	for _, svcName := range sortedKeys(m.Vars) {
+		svc, err := p.service(svcName)
+		if err != nil {
+			return nil, err
+		}
+		live, err := p.iv.List(svc.ID)
+		if err != nil {
+			return nil, err
+		}
+		desired := m.Vars[svcName]
+		for _, key := range sortedKeys(desired) {
+			value := desired[key]
+			action := Create
+			if liveValue, ok := live[key]; ok {
+				if liveValue == value {
+					continue
+				}
+				action = Update
+			}
+			svcID, key := svc.ID, key
+			changes = append(changes, Change{
+				Action:   action,
+				Resource: fmt.Sprintf("var %s %s", svcName, key),
+				apply: func() error {
+					return p.iv.Set(svcID, map[string]string{key: value})
+				},
+			})
+		}
+		for _, key := range sortedKeys(live) {
+			if _, ok := desired[key]; ok {
+				continue
+			}
+			svcID, key := svc.ID, key
+			changes = append(changes, Change{
+				Action:   Delete,
+				Resource: fmt.Sprintf("var %s %s", svcName, key),
+				apply: func() error {
+					return p.iv.Unset(svcID, key)
+				},
+			})
+		}
+	}
+	return changes, nil
+}
+
+func (p *planner) planCerts(m *Manifest) ([]Change, error) {
+	changes := []Change{}
+	if len(m.Certs) == 0 {
+		return changes, nil
+	}
+	proxy, err := p.service(serviceProxy)
+	if err != nil {
+		return nil, err
+	}
+	live, err := p.ic.List(proxy.ID)
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	for _, c := range *live {
+		existing[c.Name] = true
+	}
+	for _, c := range m.Certs {
+		if existing[c.Name] {
+			continue
+		}
+		c := c
+		detail := "from " + c.PubKey
+		if c.LetsEncrypt {
+			detail = "Let's Encrypt"
+		}
+		changes = append(changes, Change{
+			Action:   Create,
+			Resource: fmt.Sprintf("cert %s", c.Name),
+			Detail:   detail,
+			apply: func() error {
+				if c.LetsEncrypt {
+					return p.ic.CreateLetsEncrypt(c.Name, proxy.ID)
+				}
+				pubKey, err := ioutil.ReadFile(c.PubKey)
+				if err != nil {
+					return err
+				}
+				privKey, err := ioutil.ReadFile(c.PrivKey)
+				if err != nil {
+					return err
+				}
+				return p.ic.Create(c.Name, string(pubKey), string(privKey), proxy.ID)
+			},
+		})
+	}
+	return changes, nil
+}
+
+func (p *planner) planSites(m *Manifest) ([]Change, error) {
+	changes := []Change{}
+	if m.Sites == nil {
+		return changes, nil
+	}
+	proxy, err := p.service(serviceProxy)
+	if err != nil {
+		return nil, err
+	}
+	live, err := p.isites.List(proxy.ID)
+	if err != nil {
+		return nil, err
+	}
+	liveSites := map[string]models.Site{}
+	for _, s := range *live {
+		liveSites[s.Name] = s
+	}
+	desired := map[string]bool{}
+	for _, s := range m.Sites {
+		desired[s.Name] = true
+		upstream, err := p.service(s.Service)
+		if err != nil {
+			return nil, err
+		}
+		s, upstreamID := s, upstream.ID
+		create := func() error {
+			_, err := p.isites.Create(s.Name, s.Cert, upstreamID, proxy.ID, s.Values)
+			return err
+		}
+		liveSite, ok := liveSites[s.Name]
+		if !ok {
+			changes = append(changes, Change{
+				Action:   Create,
+				Resource: fmt.Sprintf("site %s", s.Name),
+				Detail:   fmt.Sprintf("for %s", s.Service),
+				apply:    create,
+			})
+			continue
+		}
+		// the list does not always include site values
+		site, err := p.isites.Retrieve(liveSite.ID, proxy.ID)
+		if err != nil {
+			return nil, err
+		}
+		diff := siteDiff(site, &s, upstreamID)
+		if diff == "" {
+			continue
+		}
+		original := *site
+		changes = append(changes, Change{
+			Action:   Replace,
+			Resource: fmt.Sprintf("site %s", s.Name),
+			Detail:   diff + ", the site will be removed and created again",
+			apply: func() error {
+				if err := p.isites.Rm(original.ID, proxy.ID); err != nil {
+					return err
+				}
+				if err := create(); err != nil {
+					return p.restoreSite(&original, proxy.ID, err)
+				}
+				return nil
+			},
+		})
+	}
+	for _, s := range *live {
+		if desired[s.Name] {
+			continue
+		}
+		siteID := s.ID
+		changes = append(changes, Change{
+			Action:   Delete,
+			Resource: fmt.Sprintf("site %s", s.Name),
+			apply: func() error {
+				return p.isites.Rm(siteID, proxy.ID)
+			},
+		})
+	}
+	return changes, nil
+}
+
+// restoreSite creates a removed site again with its original settings after
+// the replacement site could not be created, so a failed apply does not leave
+// the site missing.
+func (p *planner) restoreSite(original *models.Site, proxyID string, createErr error) error {
+	if _, err := p.isites.Create(original.Name, original.Cert, original.UpstreamService, proxyID, original.SiteValues); err != nil {
+		return fmt.Errorf("%s (the original site %s was removed and could not be restored: %s. Recreate it with the \"datica sites create\" command)", createErr, original.Name, err)
+	}
+	return fmt.Errorf("%s (the original site %s was restored)", createErr, original.Name)
+}
+
+// siteDiff describes what differs between a live site and the manifest, or
+// returns an empty string when they match.
+func siteDiff(live *models.Site, desired *Site, upstreamID string) string {
+	if live.Cert != desired.Cert {
+		return fmt.Sprintf("cert %s => %s", live.Cert, desired.Cert)
+	}
+	if live.UpstreamService != upstreamID {
+		return fmt.Sprintf("upstream service => %s", desired.Service)
+	}
+	keys := map[string]bool{}
+	for k := range live.SiteValues {
+		keys[k] = true
+	}
+	for k := range desired.Values {
+		keys[k] = true
+	}
+	for _, k := range sortedKeys(keys) {
+		liveValue, liveOK := live.SiteValues[k]
+		desiredValue, desiredOK := desired.Values[k]
+		if liveOK != desiredOK || fmt.Sprintf("%v", liveValue) != fmt.Sprintf("%v", desiredValue) {
+			return fmt.Sprintf("site value %s changed", k)
+		}
+	}
+	return ""
+}
+
+func (p *planner) planWorkers(m *Manifest) ([]Change, error) {
+	changes := []Change{}
+	for _, svcName := range sortedKeys(m.Worker) {
+		svc, err := p.service(svcName)
+		if err != nil {
+			return nil, err
+		}
+		live, err := p.iw.Retrieve(svc.ID)
+		if err != nil {
+			return nil, err
+		}
+		desired := m.Worker[svcName]
+		for _, target := range sortedKeys(desired) {
+			scale := desired[target]
+			existing, ok := live.Workers[target]
+			if ok && existing == scale {
+				continue
+			}
+			action := Create
+			if ok {
+				action = Update
+			}
+			svcID, target := svc.ID, target
+			changes = append(changes, Change{
+				Action:   action,
+				Resource: fmt.Sprintf("worker %s %s", svcName, target),
+				Detail:   fmt.Sprintf("scale %d => %d", existing, scale),
+				apply: func() error {
+					return p.scaleWorker(svcID, target, scale)
+				},
+			})
+		}
+		for _, target := range sortedKeys(live.Workers) {
+			if _, ok := desired[target]; ok {
+				continue
+			}
+			svcID, target := svc.ID, target
+			changes = append(changes, Change{
+				Action:   Delete,
+				Resource: fmt.Sprintf("worker %s %s", svcName, target),
+				Detail:   fmt.Sprintf("scale %d => 0", live.Workers[target]),
+				apply: func() error {
+					return p.scaleWorker(svcID, target, 0)
+				},
+			})
+		}
+	}
+	return changes, nil
+}
+
+// scaleWorker sets the scale of a worker target the same way the worker scale
+// and worker rm commands do, deploying new workers or stopping existing ones.
+// A scale of 0 removes the target.
+func (p *planner) scaleWorker(svcID, target string, scale int) error {
+	workers, err := p.iw.Retrieve(svcID)
+	if err != nil {
+		return err
+	}
+	existing := workers.Workers[target]
+	if scale < existing {
+		jobs, err := p.ij.RetrieveByTarget(svcID, target, 1, 1000)
+		if err != nil {
+			return err
+		}
+		deleted := 0
+		for _, j := range *jobs {
+			if deleted == existing-scale {
+				break
+			}
+			if err = p.ij.Delete(j.ID, svcID); err != nil {
+				return err
+			}
+			deleted++
+		}
+	}
+	if scale == 0 {
+		delete(workers.Workers, target)
+	} else {
+		workers.Workers[target] = scale
+	}
+	if err = p.iw.Update(svcID, workers); err != nil {
+		return err
+	}
+	if scale > existing {
+		return p.ij.DeployTarget(target, svcID)
+	}
+	return nil
+}
+
+func (p *planner) planFiles(m *Manifest) ([]Change, error) {
+	changes := []Change{}
+	for _, f := range m.Files {
+		svc, err := p.service(f.Service)
+		if err != nil {
+			return nil, err
+		}
+		contents, err := ioutil.ReadFile(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		live, err := p.ifiles.Retrieve(f.Name, svc.ID)
+		if err != nil {
+			return nil, err
+		}
+		if live == nil {
+			f, svcID := f, svc.ID
+			changes = append(changes, Change{
+				Action:   Create,
+				Resource: fmt.Sprintf("file %s %s", f.Service, f.Name),
+				Detail:   fmt.Sprintf("from %s", f.Path),
+				apply: func() error {
+					_, err := p.ifiles.Create(svcID, f.Path, f.Name, f.Mode)
+					return err
+				},
+			})
+		} else if live.Contents != string(contents) || live.Mode != f.Mode {
+			changes = append(changes, Change{
+				Action:   Drift,
+				Resource: fmt.Sprintf("file %s %s", f.Service, f.Name),
+				Detail:   "differs from " + f.Path + " but existing service files cannot be updated, please contact Datica support",
+			})
+		}
+	}
+	return changes, nil
+}
+
+func (p *planner) planMaintenance(m *Manifest) ([]Change, error) {
+	changes := []Change{}
+	if len(m.Maintenance) == 0 {
+		return changes, nil
+	}
+	proxy, err := p.service(serviceProxy)
+	if err != nil {
+		return nil, err
+	}
+	live, err := p.im.List(proxy.ID)
+	if err != nil {
+		return nil, err
+	}
+	enabled := map[string]bool{}
+	for _, mm := range *live {
+		enabled[mm.UpstreamID] = true
+	}
+	for _, svcName := range sortedKeys(m.Maintenance) {
+		svc, err := p.service(svcName)
+		if err != nil {
+			return nil, err
+		}
+		if svc.Type != "code" {
+			return nil, fmt.Errorf("Maintenance mode can only be set for code services, not %s services", svc.Type)
+		}
+		desired := m.Maintenance[svcName]
+		if enabled[svc.ID] == desired {
+			continue
+		}
+		svcID := svc.ID
+		change := Change{
+			Resource: fmt.Sprintf("maintenance %s", svcName),
+		}
+		if desired {
+			change.Action = Create
+			change.Detail = "enable maintenance mode"
+			change.apply = func() error { return p.im.Enable(proxy.ID, svcID) }
+		} else {
+			change.Action = Delete
+			change.Detail = "disable maintenance mode"
+			change.apply = func() error { return p.im.Disable(proxy.ID, svcID) }
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// printPlan prints each change along with a summary line
+func printPlan(changes []Change) {
+	if len(changes) == 0 {
+		logrus.Println("No changes. The environment matches the manifest.")
+		return
+	}
+	data := [][]string{}
+	counts := map[string]int{}
+	for _, c := range changes {
+		data = append(data, []string{actionSymbols[c.Action], c.Resource, c.Detail})
+		counts[c.Action]++
+	}
+	table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetAutoWrapText(false)
+	table.AppendBulk(data)
+	table.Render()
+	logrus.Printf("\nPlan: %d to create, %d to update, %d to replace, %d to delete.", counts[Create], counts[Update], counts[Replace], counts[Delete])
+	if counts[Replace] > 0 {
+		logrus.Warnf("%d sites cannot be updated in place and will be removed and created again. If creating a site fails, apply restores the original site.", counts[Replace])
+	}
+	if counts[Drift] > 0 {
+		logrus.Printf("%d differences cannot be fixed by apply.", counts[Drift])
+	}
+}
+
+// sortedKeys returns the keys of any map with string keys in sorted order so
+// plans are always printed in the same order.
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
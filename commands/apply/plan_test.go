@@ -0,0 +1,234 @@
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daticahealth/cli/commands/certs"
+	"github.com/daticahealth/cli/commands/files"
+	"github.com/daticahealth/cli/commands/maintenance"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/commands/sites"
+	"github.com/daticahealth/cli/commands/vars"
+	"github.com/daticahealth/cli/commands/worker"
+	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/models"
+	"github.com/daticahealth/cli/test"
+)
+
+var planTests = []struct {
+	manifest string
+	expected []string
+}{
+	// an empty list is the complete desired state, so every site is removed
+	{"sites: []\n", []string{"delete site a.com", "delete site b.com"}},
+	{"sites:\n", []string{}},
+	{"sites:\n  - name: a.com\n    service: " + test.SvcLabel + "\n    cert: a\n  - name: b.com\n    service: " + test.SvcLabel + "\n    cert: b\n", []string{}},
+	{"sites:\n  - name: a.com\n    service: " + test.SvcLabel + "\n    cert: a\n  - name: b.com\n    service: " + test.SvcLabel + "\n    cert: a\n  - name: c.com\n    service: " + test.SvcLabel + "\n    cert: c\n", []string{"replace site b.com", "create site c.com"}},
+	{"sites:\n  - name: a.com\n    service: " + test.SvcLabel + "\n    cert: a\n    values:\n      client_max_body_size: 20\n", []string{"replace site a.com", "delete site b.com"}},
+	{"certs:\n  - name: a\n    lets_encrypt: true\n", []string{}},
+	{"certs:\n  - name: a\n    lets_encrypt: true\n  - name: c\n    lets_encrypt: true\n", []string{"create cert c"}},
+	{"worker:\n  " + test.SvcLabel + ":\n    worker: 2\n    beat: 1\n", []string{}},
+	{"worker:\n  " + test.SvcLabel + ":\n    worker: 3\n", []string{"update worker " + test.SvcLabel + " worker", "delete worker " + test.SvcLabel + " beat"}},
+	{"worker:\n  " + test.SvcLabel + ":\n    worker: 2\n    beat: 1\n    mail: 1\n", []string{"create worker " + test.SvcLabel + " mail"}},
+	{"files:\n  - name: nginx.conf\n    path: same.conf\n", []string{}},
+	{"files:\n  - name: nginx.conf\n    path: changed.conf\n", []string{"drift file " + serviceProxy + " nginx.conf"}},
+	{"files:\n  - name: nginx.conf\n    path: same.conf\n    mode: \"0600\"\n", []string{"drift file " + serviceProxy + " nginx.conf"}},
+	{"files:\n  - name: extra.conf\n    path: same.conf\n", []string{"create file " + serviceProxy + " extra.conf"}},
+}
+
+// setupPlanMux serves the services, one cert, two worker targets and one
+// service file for the plan tests
+func setupPlanMux(t *testing.T, mux *http.ServeMux) {
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s","type":"code"},{"id":"%s","label":"%s","type":"utility"}]`, test.SvcID, test.SvcLabel, proxyID, serviceProxy))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+proxyID+"/certs",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, `[{"name":"a"}]`)
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+test.SvcID+"/workers",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, `{"workers":{"worker":2,"beat":1}}`)
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+proxyID+"/files",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, `[{"id":1,"name":"nginx.conf"}]`)
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+proxyID+"/files/1",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, `{"id":1,"name":"nginx.conf","contents":"server {}\n","mode":"0644"}`)
+		},
+	)
+}
+
+func TestPlan(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	dir, err := ioutil.TempDir("", "apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "same.conf"), []byte("server {}\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "changed.conf"), []byte("server { listen 80; }\n"), 0644)
+
+	setupPlanMux(t, mux)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+proxyID+"/sites/1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":1,"name":"a.com","cert":"a","upstreamService":"%s","site_values":{}}`, test.SvcID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+proxyID+"/sites/2",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":2,"name":"b.com","cert":"b","upstreamService":"%s","site_values":{}}`, test.SvcID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+proxyID+"/sites",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":1,"name":"a.com","cert":"a","upstreamService":"%s"},{"id":2,"name":"b.com","cert":"b","upstreamService":"%s"}]`, test.SvcID, test.SvcID))
+		},
+	)
+
+	for _, data := range planTests {
+		t.Logf("Data: %+v", data)
+		filePath := filepath.Join(dir, "datica.yml")
+		if err := ioutil.WriteFile(filePath, []byte(data.manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+		m, err := ReadManifest(filePath)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		p := &planner{is: services.New(settings), iv: vars.New(settings), isites: sites.New(settings), ic: certs.New(settings), iw: worker.New(settings), ifiles: files.New(settings), im: maintenance.New(settings), ij: jobs.New(settings)}
+
+		// test
+		changes, err := p.Plan(m)
+
+		// assert
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		actual := []string{}
+		for _, c := range changes {
+			actual = append(actual, c.Action+" "+c.Resource)
+		}
+		test.AssertEquals(t, strings.Join(data.expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+var siteReplaceTests = []struct {
+	createFailures int
+	restored       bool
+	expectErr      string
+}{
+	{0, false, ""},
+	{1, true, "(the original site b.com was restored)"},
+	{2, false, "(the original site b.com was removed and could not be restored"},
+}
+
+func TestApplySiteReplace(t *testing.T) {
+	for _, data := range siteReplaceTests {
+		t.Logf("Data: %+v", data)
+		mux, server, baseURL := test.Setup()
+		settings := test.GetSettings(baseURL.String())
+		dir, err := ioutil.TempDir("", "apply")
+		if err != nil {
+			t.Fatal(err)
+		}
+		setupPlanMux(t, mux)
+		removed := false
+		failures := data.createFailures
+		var created []models.Site
+		mux.HandleFunc("/environments/"+test.EnvID+"/services/"+proxyID+"/sites",
+			func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case "GET":
+					fmt.Fprint(w, fmt.Sprintf(`[{"id":2,"name":"b.com","cert":"b","upstreamService":"%s"}]`, test.SvcID))
+				case "POST":
+					if !removed {
+						t.Errorf("Expected the site to be removed before it is created")
+					}
+					var site models.Site
+					json.NewDecoder(r.Body).Decode(&site)
+					created = append(created, site)
+					if failures > 0 {
+						failures--
+						w.WriteHeader(400)
+						fmt.Fprint(w, `{"title":"Bad Request","description":"Invalid cert","code":400}`)
+						return
+					}
+					fmt.Fprint(w, `{}`)
+				default:
+					t.Errorf("Unexpected method %s", r.Method)
+				}
+			},
+		)
+		mux.HandleFunc("/environments/"+test.EnvID+"/services/"+proxyID+"/sites/2",
+			func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case "GET":
+					fmt.Fprint(w, fmt.Sprintf(`{"id":2,"name":"b.com","cert":"b","upstreamService":"%s","site_values":{"client_max_body_size":"20m"}}`, test.SvcID))
+				case "DELETE":
+					removed = true
+					w.WriteHeader(204)
+				default:
+					t.Errorf("Unexpected method %s", r.Method)
+				}
+			},
+		)
+		filePath := filepath.Join(dir, "datica.yml")
+		manifest := "sites:\n  - name: b.com\n    service: " + test.SvcLabel + "\n    cert: c\n"
+		if err := ioutil.WriteFile(filePath, []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// test
+		err = CmdApply(filePath, true, &test.FakePrompts{}, services.New(settings), vars.New(settings), sites.New(settings), certs.New(settings), worker.New(settings), files.New(settings), maintenance.New(settings), jobs.New(settings))
+		test.Teardown(server)
+		os.RemoveAll(dir)
+
+		// assert
+		if data.expectErr == "" {
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), data.expectErr) {
+			t.Errorf("Expected an error containing %q but got %v", data.expectErr, err)
+		}
+		if len(created) == 0 {
+			t.Errorf("Expected the site to be created")
+			continue
+		}
+		test.AssertEquals(t, "c", created[0].Cert)
+		if data.restored {
+			if len(created) != 2 {
+				t.Errorf("Expected the original site to be restored")
+				continue
+			}
+			test.AssertEquals(t, "b", created[1].Cert)
+			test.AssertEquals(t, test.SvcID, created[1].UpstreamService)
+			test.AssertEquals(t, "20m", fmt.Sprintf("%v", created[1].SiteValues["client_max_body_size"]))
+		}
+	}
+}
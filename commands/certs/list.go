@@ -5,11 +5,12 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
 
-func CmdList(ic ICerts, is services.IServices, downStream string) error {
+func CmdList(ic ICerts, is services.IServices, downStream string, out output.IOutput) error {
 	service, err := is.RetrieveByLabel(downStream)
 	if err != nil {
 		return err
@@ -18,6 +19,12 @@ func CmdList(ic ICerts, is services.IServices, downStream string) error {
 	if err != nil {
 		return err
 	}
+	return out.Render(certs, func() error {
+		return printCerts(certs)
+	})
+}
+
+func printCerts(certs *[]models.Cert) error {
 	if certs == nil || len(*certs) == 0 {
 		logrus.Println("No certs found")
 		return nil
@@ -42,7 +49,7 @@ func CmdList(ic ICerts, is services.IServices, downStream string) error {
 
 func (c *SCerts) List(svcID string) (*[]models.Cert, error) {
 	headers := c.Settings.HTTPManager.GetHeaders(c.Settings.SessionToken, c.Settings.Version, c.Settings.Pod, c.Settings.UsersID)
-	resp, statusCode, err := c.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/certs", c.Settings.PaasHost, c.Settings.PaasHostVersion, c.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := c.Settings.HTTPManager.Get(c.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/certs", c.Settings.PaasHost, c.Settings.PaasHostVersion, c.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -1,18 +1,25 @@
 package certs
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/test"
+	"gopkg.in/yaml.v2"
 )
 
 func TestCertsList(t *testing.T) {
 	mux, server, baseURL := test.Setup()
 	defer test.Teardown(server)
 	settings := test.GetSettings(baseURL.String())
+	oldOut, oldFormatter := logrus.StandardLogger().Out, logrus.StandardLogger().Formatter
 	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+test.SvcID+"/certs",
 		func(w http.ResponseWriter, r *http.Request) {
 			test.AssertEquals(t, r.Method, "GET")
@@ -26,11 +33,58 @@ func TestCertsList(t *testing.T) {
 		},
 	)
 
-	// test
-	err := CmdList(New(settings), services.New(settings), test.DownStream)
+	for _, format := range output.Formats {
+		t.Logf("Format: %s", format)
+		settings.OutputFormat = format
+		var buf bytes.Buffer
+		logrus.SetOutput(&buf)
+		logrus.SetFormatter(&messageFormatter{})
 
-	// assert
-	if err != nil {
-		t.Errorf("Unexpected error: %s", err)
+		// test
+		err := CmdList(New(settings), services.New(settings), test.DownStream, output.New(settings))
+		logrus.SetOutput(oldOut)
+		logrus.SetFormatter(oldFormatter)
+
+		// assert
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		var certs []map[string]interface{}
+		switch format {
+		case output.JSON:
+			err = json.Unmarshal(buf.Bytes(), &certs)
+		case output.YAML:
+			err = yaml.Unmarshal(buf.Bytes(), &certs)
+		default:
+			for i := 0; i < 4; i++ {
+				if !strings.Contains(buf.String(), fmt.Sprintf("cert%d", i)) {
+					t.Errorf("Expected cert%d in the table:\n%s", i, buf.String())
+				}
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Output is not valid %s: %s\n%s", format, err, buf.String())
+			continue
+		}
+		if len(certs) != 4 {
+			t.Errorf("Expected 4 certs but got %d", len(certs))
+			continue
+		}
+		for i, cert := range certs {
+			test.AssertEquals(t, fmt.Sprintf("cert%d", i), fmt.Sprint(cert["name"]))
+			if _, ok := cert["letsEncrypt"]; !ok && i > 0 {
+				t.Errorf("Expected the letsEncrypt field in %+v", cert)
+			}
+		}
 	}
 }
+
+// messageFormatter prints only the message of each entry, like the CLI's own
+// formatter does for info logs
+type messageFormatter struct{}
+
+func (f *messageFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return []byte(entry.Message + "\n"), nil
+}
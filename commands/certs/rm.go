@@ -27,7 +27,7 @@ func CmdRm(name string, ic ICerts, is services.IServices, downStream string) err
 
 func (c *SCerts) Rm(name, svcID string) error {
 	headers := c.Settings.HTTPManager.GetHeaders(c.Settings.SessionToken, c.Settings.Version, c.Settings.Pod, c.Settings.UsersID)
-	resp, statusCode, err := c.Settings.HTTPManager.Delete(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/certs/%s", c.Settings.PaasHost, c.Settings.PaasHostVersion, c.Settings.EnvironmentID, svcID, name), headers)
+	resp, statusCode, err := c.Settings.HTTPManager.Delete(c.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/certs/%s", c.Settings.PaasHost, c.Settings.PaasHostVersion, c.Settings.EnvironmentID, svcID, name), headers)
 	if err != nil {
 		return err
 	}
@@ -1,11 +1,34 @@
 package clear
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 
+	"github.com/daticahealth/cli/config"
 	"github.com/daticahealth/cli/test"
 )
 
+// useTempSettingsFile points the settings file and the credential store at
+// temporary files so clearing never touches the real home directory
+func useTempSettingsFile(t *testing.T) func() {
+	f, err := ioutil.TempFile("", "datica-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	oldSettingsFile := config.SettingsFile
+	config.SettingsFile = f.Name()
+	oldCredentials := config.Credentials
+	config.Credentials = config.FileCredentialStore{}
+	return func() {
+		config.SettingsFile = oldSettingsFile
+		config.Credentials = oldCredentials
+		os.Remove(f.Name())
+		os.Remove(f.Name() + ".credentials")
+	}
+}
+
 var clearTests = []struct {
 	privKey bool
 	session bool
@@ -21,6 +44,7 @@ var clearTests = []struct {
 }
 
 func TestClear(t *testing.T) {
+	defer useTempSettingsFile(t)()
 	for _, data := range clearTests {
 		t.Logf("Data: %+v", data)
 		settings := test.GetSettings("")
@@ -43,4 +67,7 @@ func TestClear(t *testing.T) {
 			t.Errorf("Pods should have been cleared")
 		}
 	}
+	if info, err := os.Stat(config.SettingsFile); err != nil || info.Size() == 0 {
+		t.Errorf("Expected the settings to be saved to the temporary file")
+	}
 }
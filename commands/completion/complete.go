@@ -0,0 +1,186 @@
+package completion
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/jault3/mow.cli"
+)
+
+// command is what completion needs to know about a single command, taken from
+// the options and arguments registered on it
+type command struct {
+	cmd         *cli.Cmd
+	subcommands []string
+	options     map[string]bool // option name => whether it takes a value
+	args        []string
+}
+
+// CmdComplete prints the completions for the last of the given words, which
+// are everything typed after "datica". The values func is only called when
+// the completions depend on the settings file or the current environment.
+func CmdComplete(app *cli.Cli, words []string, values func(profile, env string) IValues) {
+	for _, c := range Complete(app, words, values) {
+		fmt.Println(c)
+	}
+}
+
+// Complete returns the completions for the last of the given words
+func Complete(app *cli.Cli, words []string, values func(profile, env string) IValues) []string {
+	cur := ""
+	if len(words) > 0 {
+		cur = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	if err := app.DoInit(); err != nil {
+		return nil
+	}
+	c := describe(app.Cmd)
+	globals := map[string]string{}
+	positional := []string{}
+	pendingOpt := ""
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if strings.HasPrefix(w, "-") && len(w) > 1 {
+			if strings.Contains(w, "=") || !c.options[w] {
+				continue
+			}
+			if i+1 == len(words) {
+				pendingOpt = w
+				break
+			}
+			i++
+			if c.cmd == app.Cmd {
+				globals[w] = words[i]
+			}
+			continue
+		}
+		if sub := c.subcommand(w); sub != nil && len(positional) == 0 {
+			if err := sub.DoInit(); err != nil {
+				return nil
+			}
+			c = describe(sub)
+			continue
+		}
+		positional = append(positional, w)
+	}
+
+	lazyValues := func() IValues {
+		return values(first(globals["--profile"]), first(globals["-E"], globals["--env"]))
+	}
+	var candidates []string
+	switch {
+	case pendingOpt == "-E" || pendingOpt == "--env":
+		candidates = lazyValues().Environments()
+	case pendingOpt == "--profile":
+		candidates = lazyValues().Profiles()
+	case pendingOpt == "--output":
+		candidates = output.Formats
+	case pendingOpt != "":
+	case strings.HasPrefix(cur, "-"):
+		for name := range c.options {
+			candidates = append(candidates, name)
+		}
+	case len(c.subcommands) > 0 && len(positional) == 0:
+		candidates = c.subcommands
+	case len(positional) < len(c.args):
+		candidates = c.argValues(c.args[len(positional)], positional, lazyValues)
+	}
+	return filter(candidates, cur)
+}
+
+// argValues looks up the possible values of a positional argument. Releases
+// and backups belong to the service given in an earlier argument.
+func (c *command) argValues(name string, positional []string, values func() IValues) []string {
+	switch name {
+	case "SERVICE_NAME", "SERVICE", "DATABASE_NAME":
+		return values().Services()
+	case "RELEASE_NAME":
+		if svcName := c.argValue("SERVICE_NAME", positional); svcName != "" {
+			return values().Releases(svcName)
+		}
+	case "BACKUP_ID":
+		if databaseName := c.argValue("DATABASE_NAME", positional); databaseName != "" {
+			return values().Backups(databaseName)
+		}
+	}
+	return nil
+}
+
+// argValue returns the value typed for the named positional argument
+func (c *command) argValue(name string, positional []string) string {
+	for i, arg := range c.args {
+		if arg == name && i < len(positional) {
+			return positional[i]
+		}
+	}
+	return ""
+}
+
+func (c *command) subcommand(name string) *cli.Cmd {
+	for _, sub := range c.cmd.Commands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// boolValued is implemented by the values of bool options, which never take a
+// separate value
+var boolValued = reflect.TypeOf((*interface {
+	IsBoolFlag() bool
+})(nil)).Elem()
+
+// describe reads the subcommands, options, and arguments of an initialized
+// command. mow.cli does not export the options and arguments registered on a
+// command, so they are read with reflection.
+func describe(cmd *cli.Cmd) *command {
+	c := &command{
+		cmd:     cmd,
+		options: map[string]bool{},
+	}
+	for _, sub := range cmd.Commands {
+		c.subcommands = append(c.subcommands, sub.Name)
+	}
+	v := reflect.ValueOf(cmd).Elem()
+	options := v.FieldByName("options")
+	for i := 0; i < options.Len(); i++ {
+		opt := options.Index(i).Elem()
+		value := opt.FieldByName("value")
+		takesValue := value.IsNil() || !value.Elem().Type().Implements(boolValued)
+		names := opt.FieldByName("names")
+		for j := 0; j < names.Len(); j++ {
+			c.options[names.Index(j).String()] = takesValue
+		}
+	}
+	args := v.FieldByName("args")
+	for i := 0; i < args.Len(); i++ {
+		c.args = append(c.args, args.Index(i).Elem().FieldByName("name").String())
+	}
+	return c
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// filter returns the sorted candidates that start with prefix
+func filter(candidates []string, prefix string) []string {
+	result := []string{}
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			result = append(result, c)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
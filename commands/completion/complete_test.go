@@ -0,0 +1,92 @@
+package completion
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jault3/mow.cli"
+)
+
+type fakeValues struct{}
+
+func (f *fakeValues) Backups(databaseName string) []string {
+	return []string{databaseName + "-backup"}
+}
+func (f *fakeValues) Environments() []string {
+	return []string{"production", "staging"}
+}
+func (f *fakeValues) Profiles() []string {
+	return []string{"default", "work"}
+}
+func (f *fakeValues) Releases(svcName string) []string {
+	return []string{svcName + "-v1", svcName + "-v2"}
+}
+func (f *fakeValues) Services() []string {
+	return []string{"code-1", "db01"}
+}
+
+func testApp() *cli.Cli {
+	app := cli.App("datica", "")
+	app.String(cli.StringOpt{Name: "E env", Desc: "The environment", HideValue: true})
+	app.String(cli.StringOpt{Name: "output", Value: "table", Desc: "The format"})
+	app.Command("db", "Tasks for databases", func(cmd *cli.Cmd) {
+		cmd.Command("backup", "Create a backup", func(subCmd *cli.Cmd) {
+			subCmd.StringArg("DATABASE_NAME", "", "The database")
+			subCmd.BoolOpt("s skip-poll", false, "Skip polling")
+			subCmd.String(cli.StringOpt{Name: "compress", Value: "false", Desc: "A value that looks like a bool"})
+		})
+		cmd.Command("restore", "Restore a backup", func(subCmd *cli.Cmd) {
+			subCmd.StringArg("DATABASE_NAME", "", "The database")
+			subCmd.StringArg("BACKUP_ID", "", "The backup")
+			subCmd.Spec = "DATABASE_NAME BACKUP_ID"
+		})
+	})
+	app.Command("deploy", "Deploy an image", func(cmd *cli.Cmd) {
+		cmd.StringArg("SERVICE_NAME", "", "The service")
+		cmd.StringArg("RELEASE_NAME", "", "The release")
+		cmd.Spec = "SERVICE_NAME RELEASE_NAME"
+	})
+	return app
+}
+
+var completeTests = []struct {
+	words    []string
+	expected []string
+}{
+	{[]string{""}, []string{"db", "deploy"}},
+	{[]string{"de"}, []string{"deploy"}},
+	{[]string{"db", ""}, []string{"backup", "restore"}},
+	{[]string{"db", "backup", "-"}, []string{"--compress", "--skip-poll", "-s"}},
+	{[]string{"db", "backup", ""}, []string{"code-1", "db01"}},
+	{[]string{"db", "backup", "-s", "d"}, []string{"db01"}},
+	{[]string{"db", "backup", "--compress", ""}, []string{}},
+	{[]string{"db", "backup", "--compress", "gzip", "d"}, []string{"db01"}},
+	{[]string{"db", "restore", "db01", ""}, []string{"db01-backup"}},
+	{[]string{"db", "restore", "db01", "db01-backup", ""}, []string{}},
+	{[]string{"deploy", "code-1", ""}, []string{"code-1-v1", "code-1-v2"}},
+	{[]string{"-E", ""}, []string{"production", "staging"}},
+	{[]string{"-E", "staging", "--output", "j"}, []string{"json"}},
+	{[]string{"-E", "staging", "db", ""}, []string{"backup", "restore"}},
+	{[]string{"unknown", ""}, []string{}},
+}
+
+func TestComplete(t *testing.T) {
+	for _, data := range completeTests {
+		t.Logf("Data: %+v", data)
+
+		// test
+		var env string
+		actual := Complete(testApp(), data.words, func(p, e string) IValues {
+			env = e
+			return &fakeValues{}
+		})
+
+		// assert
+		if !reflect.DeepEqual(actual, data.expected) {
+			t.Errorf("Expected %v, got %v", data.expected, actual)
+		}
+		if len(data.words) > 2 && data.words[0] == "-E" && env != "" && env != data.words[1] {
+			t.Errorf("Expected the environment %s to be used, got %s", data.words[1], env)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package completion
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/models"
+	"github.com/jault3/mow.cli"
+)
+
+// CompleteArg is the hidden first argument shell completion scripts call the
+// CLI with to get the completions for the words typed so far.
+const CompleteArg = "__complete"
+
+// Cmd is the contract between the user and the CLI. This specifies the command
+// name, arguments, and required/optional arguments and flags for the command.
+var Cmd = models.Command{
+	Name:      "completion",
+	ShortHelp: "Print a shell completion script",
+	LongHelp: "<code>completion</code> prints a script that enables tab completion of commands, options, and arguments in your shell. " +
+		"Supported shells are bash, zsh, and fish. " +
+		"Along with commands and options, service names, environment names, release names, and backup IDs are completed for the environment you are using. " +
+		"Values that need to be looked up are only completed once you are signed in. " +
+		"To enable completion for the current shell session, run one of these commands\n\n" +
+		"<pre>\nsource <(datica completion bash)\nsource <(datica completion zsh)\ndatica completion fish | source\n</pre>\n\n" +
+		"To enable completion for every session, add the same command to your <code>~/.bashrc</code>, <code>~/.zshrc</code>, or <code>~/.config/fish/config.fish</code>.",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(cmd *cli.Cmd) {
+			shell := cmd.StringArg("SHELL", "", "The shell to print a completion script for (bash, zsh, or fish)")
+			cmd.Action = func() {
+				err := CmdCompletion(*shell)
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			cmd.Spec = "SHELL"
+		}
+	},
+}
+
+// IValues looks up the values of arguments and options that depend on the
+// settings file or the current environment
+type IValues interface {
+	Backups(databaseName string) []string
+	Environments() []string
+	Profiles() []string
+	Releases(svcName string) []string
+	Services() []string
+}
+
+// SValues is a concrete implementation of IValues
+type SValues struct {
+	Settings *models.Settings
+}
+
+// New returns an instance of IValues
+func New(settings *models.Settings) IValues {
+	return &SValues{
+		Settings: settings,
+	}
+}
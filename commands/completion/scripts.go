@@ -0,0 +1,44 @@
+package completion
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+)
+
+var scripts = map[string]string{
+	"bash": `# bash completion for datica
+_datica_completion() {
+  local IFS=$'\n'
+  COMPREPLY=( $(datica ` + CompleteArg + ` "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null) )
+}
+complete -o default -F _datica_completion datica`,
+	"zsh": `#compdef datica
+# zsh completion for datica
+_datica() {
+  local -a completions
+  completions=(${(f)"$(datica ` + CompleteArg + ` "${(@)words[2,$CURRENT]}" 2>/dev/null)"})
+  if (( ${#completions} )); then
+    compadd -- $completions
+  else
+    _files
+  fi
+}
+compdef _datica datica`,
+	"fish": `# fish completion for datica
+function __datica_complete
+  set -l args (commandline -opc) (commandline -ct)
+  datica ` + CompleteArg + ` $args[2..-1] 2>/dev/null
+end
+complete -c datica -f -a '(__datica_complete)'`,
+}
+
+// CmdCompletion prints the completion script for the given shell
+func CmdCompletion(shell string) error {
+	script, ok := scripts[shell]
+	if !ok {
+		return fmt.Errorf("Unsupported shell \"%s\". Please specify one of: bash, zsh, fish", shell)
+	}
+	logrus.Println(script)
+	return nil
+}
@@ -0,0 +1,129 @@
+package completion
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/daticahealth/cli/commands/db"
+	"github.com/daticahealth/cli/commands/releases"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/config"
+	"github.com/daticahealth/cli/lib/compress"
+	"github.com/daticahealth/cli/lib/crypto"
+	"github.com/daticahealth/cli/lib/httpclient"
+	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/models"
+)
+
+// NewFromSettingsFile reads the settings file for the given profile and
+// environment, the same as any other command, and returns an IValues for it.
+// Nothing is ever prompted for, so values that need the API are only looked up
+// when a session token is already stored. All requests use ctx.
+func NewFromSettingsFile(ctx context.Context, profile, env string) IValues {
+	if profile == "" {
+		profile = os.Getenv(config.DaticaProfileEnvVar)
+	}
+	if env == "" {
+		env = os.Getenv(config.DaticaEnvironmentEnvVar)
+	}
+	r := config.FileSettingsRetriever{}
+	settings, err := r.GetSettings(profile, env, "", os.Getenv(config.AccountsHostEnvVar), os.Getenv(config.AuthHostEnvVar), "", os.Getenv(config.PaasHostEnvVar), "", "", "")
+	if err != nil {
+		return New(&models.Settings{})
+	}
+	// with a single environment there is no question which one is meant
+	if settings.EnvironmentID == "" && len(settings.Environments) == 1 {
+		for _, e := range settings.Environments {
+			config.SetGivenEnv(e.EnvironmentID, settings)
+		}
+	}
+	skip, _ := strconv.ParseBool(os.Getenv(config.SkipVerifyEnvVar))
+	retryPolicy := httpclient.DefaultRetryPolicy()
+	retryPolicy.MaxRetries = 0
+	settings.HTTPManager = httpclient.NewTLSHTTPManagerWithRetryPolicy(skip, retryPolicy)
+	settings.Context = ctx
+	return New(settings)
+}
+
+// canLookup is true when the API can be called without signing in
+func (v *SValues) canLookup() bool {
+	return v.Settings.SessionToken != "" && v.Settings.EnvironmentID != "" && v.Settings.HTTPManager != nil
+}
+
+// Environments returns the names of the environments in the settings file
+func (v *SValues) Environments() []string {
+	names := []string{}
+	for _, e := range v.Settings.Environments {
+		names = append(names, e.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profiles returns the names of the profiles in the settings file
+func (v *SValues) Profiles() []string {
+	names := []string{}
+	for name := range v.Settings.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Services returns the labels of the services in the current environment
+func (v *SValues) Services() []string {
+	labels := []string{}
+	if !v.canLookup() {
+		return labels
+	}
+	svcs, err := services.New(v.Settings).List()
+	if err != nil {
+		return labels
+	}
+	for _, svc := range *svcs {
+		labels = append(labels, svc.Label)
+	}
+	return labels
+}
+
+// Releases returns the names of the releases of a code service
+func (v *SValues) Releases(svcName string) []string {
+	names := []string{}
+	if !v.canLookup() {
+		return names
+	}
+	svc, err := services.New(v.Settings).RetrieveByLabel(svcName)
+	if err != nil || svc == nil {
+		return names
+	}
+	rls, err := releases.New(v.Settings).List(svc.ID)
+	if err != nil {
+		return names
+	}
+	for _, r := range *rls {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// Backups returns the IDs of the most recent backups of a database service
+func (v *SValues) Backups(databaseName string) []string {
+	ids := []string{}
+	if !v.canLookup() {
+		return ids
+	}
+	svc, err := services.New(v.Settings).RetrieveByLabel(databaseName)
+	if err != nil || svc == nil {
+		return ids
+	}
+	backups, err := db.New(v.Settings, crypto.New(), compress.New(), jobs.New(v.Settings)).List("backup", 1, 100, svc)
+	if err != nil {
+		return ids
+	}
+	for _, b := range *backups {
+		ids = append(ids, b.ID)
+	}
+	return ids
+}
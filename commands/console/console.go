@@ -68,7 +68,7 @@ func (c *SConsole) Open(command string, service *models.Service) error {
 	logrus.StandardLogger().Out.Write([]byte(fmt.Sprintf("Waiting for the console (job ID = %s) to be ready. This might take a minute.", job.ID)))
 
 	validStatuses := []string{"running", "finished", "failed"}
-	status, err := c.Jobs.PollForStatus(validStatuses, job.ID, service.ID)
+	status, err := c.Jobs.PollForStatus(c.Settings.Context, validStatuses, job.ID, service.ID)
 	if err != nil {
 		return err
 	}
@@ -131,7 +131,7 @@ func (c *SConsole) Request(command string, service *models.Service) (*models.Job
 		return nil, err
 	}
 	headers := c.Settings.HTTPManager.GetHeaders(c.Settings.SessionToken, c.Settings.Version, c.Settings.Pod, c.Settings.UsersID)
-	resp, statusCode, err := c.Settings.HTTPManager.Post(b, fmt.Sprintf("%s%s/environments/%s/services/%s/console", c.Settings.PaasHost, c.Settings.PaasHostVersion, c.Settings.EnvironmentID, service.ID), headers)
+	resp, statusCode, err := c.Settings.HTTPManager.Post(c.Settings.Context, b, fmt.Sprintf("%s%s/environments/%s/services/%s/console", c.Settings.PaasHost, c.Settings.PaasHostVersion, c.Settings.EnvironmentID, service.ID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -145,7 +145,7 @@ func (c *SConsole) Request(command string, service *models.Service) (*models.Job
 
 func (c *SConsole) RetrieveTokens(jobID string, service *models.Service) (*models.ConsoleCredentials, error) {
 	headers := c.Settings.HTTPManager.GetHeaders(c.Settings.SessionToken, c.Settings.Version, c.Settings.Pod, c.Settings.UsersID)
-	resp, statusCode, err := c.Settings.HTTPManager.Post(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/jobs/%s/console-token", c.Settings.PaasHost, c.Settings.PaasHostVersion, c.Settings.EnvironmentID, service.ID, jobID), headers)
+	resp, statusCode, err := c.Settings.HTTPManager.Post(c.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/jobs/%s/console-token", c.Settings.PaasHost, c.Settings.PaasHostVersion, c.Settings.EnvironmentID, service.ID, jobID), headers)
 	if err != nil {
 		return nil, err
 	}
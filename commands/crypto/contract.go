@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"os"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/lib/crypto"
+	"github.com/daticahealth/cli/models"
+	"github.com/jault3/mow.cli"
+)
+
+// Cmd is the contract between the user and the CLI. This specifies the command
+// name, arguments, and required/optional arguments and flags for the command.
+var Cmd = models.Command{
+	Name:      "crypto",
+	ShortHelp: "Tasks for files encrypted to your own keys",
+	LongHelp: "The <code>crypto</code> command works with files that the CLI encrypted to your own public key, such as backups saved with <code>db download --encrypt-to</code>. " +
+		"The crypto command can not be run directly but has subcommands.",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(cmd *cli.Cmd) {
+			cmd.CommandLong(DecryptSubCmd.Name, DecryptSubCmd.ShortHelp, DecryptSubCmd.LongHelp, DecryptSubCmd.CmdFunc(settings))
+		}
+	},
+}
+
+var DecryptSubCmd = models.Command{
+	Name:      "decrypt",
+	ShortHelp: "Decrypt a file encrypted to your public key",
+	LongHelp: "<code>crypto decrypt</code> decrypts a file that was encrypted to your public key with the <code>--encrypt-to</code> option, such as a backup from <code>db download</code> or <code>db export</code>. " +
+		"The private key is either an X25519 private key file starting with <code>AGE-SECRET-KEY-1</code>, such as one made by <code>age-keygen</code>, or an RSA private key in PEM format. " +
+		"These files use the CLI's own encrypted format rather than the age file format, so they cannot be opened with <code>age -d</code>, and this command cannot open files encrypted by age. " +
+		"Every chunk of the file is authenticated, and a file that was modified or cut short fails to decrypt. " +
+		"Be careful using this command as the decrypted file could contain PHI. Here is a sample command\n\n" +
+		"<pre>\ndatica crypto decrypt -i ~/keys/backups.txt ./db.sql.enc ./db.sql\n</pre>\n\n" +
+		"Use <code>-</code> as either file path to read from stdin or write to stdout instead, such as to pipe a backup into another program without the decrypted data touching the disk.\n\n" +
+		"<pre>\ndatica crypto decrypt -i ~/.ssh/backups.pem ./db.sql.enc - | psql localdb\n</pre>",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			identityPath := subCmd.StringOpt("i identity", "", "The path to the X25519 or RSA PEM private key file")
+			inPath := subCmd.StringArg("INPUT", "", "The encrypted file, or - for stdin")
+			outPath := subCmd.StringArg("OUTPUT", "", "The location to save the decrypted file, or - for stdout. This location must NOT already exist unless -f is specified")
+			force := subCmd.BoolOpt("f force", false, "If a file previously exists at OUTPUT, overwrite it")
+			subCmd.Action = func() {
+				if *outPath == StdFilePath {
+					logrus.SetOutput(os.Stderr)
+				}
+				err := CmdDecrypt(*identityPath, *inPath, *outPath, *force, crypto.New())
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "-i INPUT OUTPUT [-f]"
+		}
+	},
+}
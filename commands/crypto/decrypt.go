@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/lib/crypto"
+)
+
+// StdFilePath is the INPUT or OUTPUT that reads from stdin or writes to stdout
+// instead of a file
+const StdFilePath = "-"
+
+// stdin and stdout are swapped out in tests
+var (
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+)
+
+func CmdDecrypt(identityPath, inPath, outPath string, force bool, ic crypto.ICrypto) error {
+	if outPath != StdFilePath {
+		if _, err := os.Stat(outPath); err == nil && !force {
+			return fmt.Errorf("File already exists at path '%s'. Specify `--force` to overwrite", outPath)
+		}
+	}
+	identity, err := crypto.LoadIdentity(identityPath)
+	if err != nil {
+		return err
+	}
+	in := stdin
+	if inPath != StdFilePath {
+		file, err := os.Open(inPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+	r, err := ic.NewDecryptFromReader(in, identity)
+	if err != nil {
+		return err
+	}
+	if outPath == StdFilePath {
+		if _, err = io.Copy(stdout, r); err != nil {
+			return fmt.Errorf("The file could not be fully decrypted, so the output is incomplete: %s", err)
+		}
+		return nil
+	}
+	// only move the decrypted file into place once all of it is authenticated
+	tmpPath := outPath + ".tmp"
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err = os.Rename(tmpPath, outPath); err != nil {
+		return err
+	}
+	logrus.Printf("Decrypted %s to %s", inPath, outPath)
+	return nil
+}
@@ -0,0 +1,99 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/daticahealth/cli/lib/crypto"
+)
+
+const (
+	encryptedFilePath = "crypto-decrypt.enc"
+	decryptedFilePath = "crypto-decrypt.sql"
+	identityFilePath  = "crypto-decrypt.pem"
+	publicKeyFilePath = "crypto-decrypt.pub.pem"
+)
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+var decryptTests = []struct {
+	description string
+	inPath      string
+	outPath     string
+	force       bool
+	identity    string
+	expectErr   bool
+}{
+	{"to a file", encryptedFilePath, decryptedFilePath, false, identityFilePath, false},
+	{"existing file without force", encryptedFilePath, decryptedFilePath, false, identityFilePath, true},
+	{"existing file with force", encryptedFilePath, decryptedFilePath, true, identityFilePath, false},
+	{"from stdin to stdout", StdFilePath, StdFilePath, false, identityFilePath, false},
+	{"wrong key", encryptedFilePath, decryptedFilePath, true, publicKeyFilePath, true},
+	{"missing file", "does-not-exist.enc", decryptedFilePath, true, identityFilePath, true},
+}
+
+func TestCryptoDecrypt(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	ioutil.WriteFile(publicKeyFilePath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0600)
+	ioutil.WriteFile(identityFilePath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600)
+	defer os.Remove(publicKeyFilePath)
+	defer os.Remove(identityFilePath)
+
+	plaintext := []byte("INSERT INTO patients VALUES (1, 'test');\n")
+	recipient, err := crypto.ParseRecipient(publicKeyFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := nopCloser{&bytes.Buffer{}}
+	w, err := crypto.New().NewEncryptToWriteCloser(encrypted, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(plaintext)
+	w.Close()
+	ioutil.WriteFile(encryptedFilePath, encrypted.Bytes(), 0600)
+	defer os.Remove(encryptedFilePath)
+	defer os.Remove(decryptedFilePath)
+
+	oldStdin, oldStdout := stdin, stdout
+	defer func() { stdin, stdout = oldStdin, oldStdout }()
+	for _, data := range decryptTests {
+		t.Logf("Data: %+v", data)
+		stdin = bytes.NewReader(encrypted.Bytes())
+		var buf bytes.Buffer
+		stdout = &buf
+
+		// test
+		err := CmdDecrypt(data.identity, data.inPath, data.outPath, data.force, crypto.New())
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if data.expectErr {
+			continue
+		}
+		actual := buf.Bytes()
+		if data.outPath != StdFilePath {
+			actual, _ = ioutil.ReadFile(data.outPath)
+		}
+		if !bytes.Equal(actual, plaintext) {
+			t.Errorf("Unexpected decrypted file. Expected: %q, actual: %q", plaintext, actual)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Sirupsen/logrus"
@@ -9,7 +10,7 @@ import (
 	"github.com/daticahealth/cli/models"
 )
 
-func CmdBackup(databaseName string, skipPoll bool, id IDb, is services.IServices, ij jobs.IJobs) error {
+func CmdBackup(ctx context.Context, databaseName string, skipPoll bool, id IDb, is services.IServices, ij jobs.IJobs) error {
 	service, err := is.RetrieveByLabel(databaseName)
 	if err != nil {
 		return err
@@ -28,18 +29,20 @@ func CmdBackup(databaseName string, skipPoll bool, id IDb, is services.IServices
 		logrus.StandardLogger().Out.Write([]byte("Polling until backup finishes."))
 		if isSnapshotBackup {
 			logrus.StandardLogger().Out.Write([]byte(fmt.Sprintf("\nThis is a snapshot backup, it may be a while before this backup shows up in the \"datica db list %s\" command.", databaseName)))
-			err = ij.WaitToAppear(job.ID, service.ID)
+			err = ij.WaitToAppear(ctx, job.ID, service.ID)
 			if err != nil {
 				return err
 			}
 		}
-		status, err := ij.PollTillFinished(job.ID, service.ID)
+		tail := id.TailLogs(ctx, "backup", job, service)
+		defer tail.Stop()
+		status, err := ij.PollTillFinished(ctx, job.ID, service.ID)
 		if err != nil {
 			return err
 		}
 		job.Status = status
 		logrus.Printf("\nEnded in status '%s'", job.Status)
-		err = id.DumpLogs("backup", job, service)
+		err = tail.Finish()
 		if err != nil {
 			return err
 		}
@@ -56,7 +59,7 @@ func CmdBackup(databaseName string, skipPoll bool, id IDb, is services.IServices
 // Backup creates a new backup
 func (d *SDb) Backup(service *models.Service) (*models.Job, error) {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Post(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/backup", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Post(d.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/backup", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
 	if err != nil {
 		return nil, err
 	}
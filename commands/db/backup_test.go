@@ -71,7 +71,7 @@ func TestDbBackup(t *testing.T) {
 		queriedJob = false
 
 		// test
-		err := CmdBackup(data.databaseName, data.skipPoll, New(settings, crypto.New(), compress.New(), jobs.New(settings)), services.New(settings), jobs.New(settings))
+		err := CmdBackup(settings.Context, data.databaseName, data.skipPoll, New(settings, crypto.New(), compress.New(), jobs.New(settings)), services.New(settings), jobs.New(settings))
 
 		// assert
 		if err != nil != data.expectErr {
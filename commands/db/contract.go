@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"io"
 
 	"github.com/Sirupsen/logrus"
@@ -13,7 +14,9 @@ import (
 	"github.com/daticahealth/cli/lib/compress"
 	"github.com/daticahealth/cli/lib/crypto"
 	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/lib/prompts"
+	"github.com/daticahealth/cli/lib/redact"
 	"github.com/daticahealth/cli/lib/transfer"
 	"github.com/daticahealth/cli/models"
 
@@ -33,8 +36,11 @@ var Cmd = models.Command{
 			cmd.CommandLong(DownloadSubCmd.Name, DownloadSubCmd.ShortHelp, DownloadSubCmd.LongHelp, DownloadSubCmd.CmdFunc(settings))
 			cmd.CommandLong(ExportSubCmd.Name, ExportSubCmd.ShortHelp, ExportSubCmd.LongHelp, ExportSubCmd.CmdFunc(settings))
 			cmd.CommandLong(ImportSubCmd.Name, ImportSubCmd.ShortHelp, ImportSubCmd.LongHelp, ImportSubCmd.CmdFunc(settings))
+			cmd.CommandLong(CopySubCmd.Name, CopySubCmd.ShortHelp, CopySubCmd.LongHelp, CopySubCmd.CmdFunc(settings))
 			cmd.CommandLong(ListSubCmd.Name, ListSubCmd.ShortHelp, ListSubCmd.LongHelp, ListSubCmd.CmdFunc(settings))
 			cmd.CommandLong(LogsSubCmd.Name, LogsSubCmd.ShortHelp, LogsSubCmd.LongHelp, LogsSubCmd.CmdFunc(settings))
+			cmd.CommandLong(PruneSubCmd.Name, PruneSubCmd.ShortHelp, PruneSubCmd.LongHelp, PruneSubCmd.CmdFunc(settings))
+			cmd.CommandLong(VerifySubCmd.Name, VerifySubCmd.ShortHelp, VerifySubCmd.LongHelp, VerifySubCmd.CmdFunc(settings))
 		}
 	},
 }
@@ -58,7 +64,7 @@ var BackupSubCmd = models.Command{
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdBackup(*databaseName, *skipPoll, New(settings, crypto.New(), compress.New(), jobs.New(settings)), services.New(settings), jobs.New(settings))
+				err := CmdBackup(settings.Context, *databaseName, *skipPoll, New(settings, crypto.New(), compress.New(), jobs.New(settings)), services.New(settings), jobs.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
@@ -74,29 +80,40 @@ var DownloadSubCmd = models.Command{
 	LongHelp: "<code>db download</code> downloads a previously created backup to your local hard drive. " +
 		"Be careful using this command as it could download PHI. " +
 		"Be sure that all hard drive encryption and necessary precautions have been taken before performing a download. " +
+		"If the download is interrupted, running the same command again resumes it where it left off. " +
+		"The backup is only decrypted and saved once it has been fully downloaded and verified. " +
 		"The ID of the backup is found by first running the db list command. Here is a sample command\n\n" +
 		"<pre>\ndatica -E \"<your_env_name>\" db download db01 cd2b4bce-2727-42d1-89e0-027bf3f1a203 ./db.sql\n</pre>\n\n" +
 		"This assumes you are downloading a MySQL or PostgreSQL backup which takes the <code>.sql</code> file format. If you are downloading a mongo backup, the command might look like this\n\n" +
-		"<pre>\ndatica -E \"<your_env_name>\" db download db01 cd2b4bce-2727-42d1-89e0-027bf3f1a203 ./db.tar.gz\n</pre>",
+		"<pre>\ndatica -E \"<your_env_name>\" db download db01 cd2b4bce-2727-42d1-89e0-027bf3f1a203 ./db.tar.gz\n</pre>\n\n" +
+		"Use <code>-</code> as the file path to write the backup to stdout instead. All other output is then written to stderr, and an interrupted download cannot be resumed.\n\n" +
+		"Use <code>--encrypt-to</code> to encrypt the backup to your own public key as it is decrypted, so it is never saved in plaintext. " +
+		"The key is either an X25519 public key starting with <code>age1</code>, such as one made by <code>age-keygen</code>, or the path to a file holding one or an RSA public key in PEM format. " +
+		"The backup is written in the CLI's own encrypted format, not the age file format, so it can only be opened with the <code>crypto decrypt</code> command and the matching private key, not with <code>age -d</code>.\n\n" +
+		"<pre>\ndatica -E \"<your_env_name>\" db download db01 cd2b4bce-2727-42d1-89e0-027bf3f1a203 ./db.sql.enc --encrypt-to ~/.ssh/backups.pub.pem\n</pre>",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database service which was backed up (e.g. 'db01')")
 			backupID := subCmd.StringArg("BACKUP_ID", "", "The ID of the backup to download (found from \"datica backup list\")")
-			filePath := subCmd.StringArg("FILEPATH", "", "The location to save the downloaded backup to. This location must NOT already exist unless -f is specified")
+			filePath := subCmd.StringArg("FILEPATH", "", "The location to save the downloaded backup to, or - for stdout. This location must NOT already exist unless -f is specified")
 			force := subCmd.BoolOpt("f force", false, "If a file previously exists at \"filepath\", overwrite it and download the backup")
+			encryptTo := subCmd.StringOpt("encrypt-to", "", "An X25519 public key (age1...), or the path to an X25519 or RSA PEM public key file, to encrypt the backup to")
 			subCmd.Action = func() {
+				if *filePath == StdoutFilePath {
+					RedirectStdout()
+				}
 				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
 					logrus.Fatal(err.Error())
 				}
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdDownload(*databaseName, *backupID, *filePath, *force, New(settings, crypto.New(), compress.New(), jobs.New(settings)), prompts.New(), services.New(settings))
+				err := CmdDownload(*databaseName, *backupID, *filePath, *force, *encryptTo, New(settings, crypto.New(), compress.New(), jobs.New(settings)), prompts.New(), services.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 			}
-			subCmd.Spec = "DATABASE_NAME BACKUP_ID FILEPATH [-f]"
+			subCmd.Spec = "DATABASE_NAME BACKUP_ID FILEPATH [-f] [--encrypt-to]"
 		}
 	},
 }
@@ -145,25 +162,50 @@ var ExportSubCmd = models.Command{
 		"If an error occurs and the logs are not printed, you can use the db logs command to print out historical backup job logs. Here is a sample command\n\n" +
 		"<pre>\ndatica -E \"<your_env_name>\" db export db01 ./dbexport.sql\n</pre>\n\n" +
 		"This assumes you are exporting a MySQL or PostgreSQL database which takes the <code>.sql</code> file format. If you are exporting a mongo database, the command might look like this\n\n" +
-		"<pre>\ndatica -E \"<your_env_name>\" db export db01 ./dbexport.tar.gz\n</pre>",
+		"<pre>\ndatica -E \"<your_env_name>\" db export db01 ./dbexport.tar.gz\n</pre>\n\n" +
+		"Use <code>-</code> as the file path to write the export to stdout instead, such as to pipe it into another program without the decrypted data touching the disk. " +
+		"All other output, including prompts and the backup logs, is then written to stderr.\n\n" +
+		"<pre>\ndatica -E \"<your_env_name>\" db export db01 - | psql localdb\n</pre>\n\n" +
+		"Use <code>--redact</code> to scrub sensitive data from the export, such as to load it into a development environment. " +
+		"The rules file names the tables and columns, or mongo collections and fields, to redact and how to replace each value. " +
+		"<code>null</code> replaces the value with NULL, <code>hash</code> with a salted SHA-256 hash so equal values stay equal, " +
+		"<code>fake</code> with random letters and digits in the same shape, and <code>constant</code> with the given value. " +
+		"A <code>salt</code> is required when any rule uses <code>hash</code> or <code>fake</code>. Keep it secret, since anyone who knows it can hash or fake guesses of the original values and compare them against the export. " +
+		"The backup is redacted as it is decrypted, so the unredacted data is never written to disk. " +
+		"If any rule does not match a table or collection in the backup, such as because of a typo, the export fails and is removed unless <code>--allow-unmatched</code> is given. Here is a sample rules file\n\n" +
+		"<pre>\nsalt: a-random-secret\nrules:\n" +
+		"  - table: patients\n    column: ssn\n    strategy: \"null\"\n" +
+		"  - table: public.patients\n    column: email\n    strategy: hash\n" +
+		"  - collection: visits\n    field: patient.phone\n    strategy: fake\n" +
+		"  - collection: visits\n    field: notes\n    strategy: constant\n    value: redacted\n</pre>\n\n" +
+		"<pre>\ndatica -E \"<your_env_name>\" db export db01 ./dbexport.sql --redact rules.yml\n</pre>\n\n" +
+		"Use <code>--encrypt-to</code> to encrypt the export to your own public key so it is never saved in plaintext, as with the <code>db download</code> command. " +
+		"Open it later with the <code>crypto decrypt</code> command, which is the only way to open it.\n\n" +
+		"<pre>\ndatica -E \"<your_env_name>\" db export db01 ./dbexport.sql.enc --encrypt-to age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p\n</pre>",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database to export data from (e.g. 'db01')")
-			filePath := subCmd.StringArg("FILEPATH", "", "The location to save the exported data. This location must NOT already exist unless -f is specified")
+			filePath := subCmd.StringArg("FILEPATH", "", "The location to save the exported data, or - for stdout. This location must NOT already exist unless -f is specified")
 			force := subCmd.BoolOpt("f force", false, "If a file previously exists at <code>filepath</code>, overwrite it and export data")
+			redactPath := subCmd.StringOpt("redact", "", "The path to a YAML file of rules for redacting columns or fields from the export")
+			allowUnmatched := subCmd.BoolOpt("allow-unmatched", false, "Keep the export even when a redaction rule does not match anything in the backup")
+			encryptTo := subCmd.StringOpt("encrypt-to", "", "An X25519 public key (age1...), or the path to an X25519 or RSA PEM public key file, to encrypt the export to")
 			subCmd.Action = func() {
+				if *filePath == StdoutFilePath {
+					RedirectStdout()
+				}
 				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
 					logrus.Fatal(err.Error())
 				}
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdExport(*databaseName, *filePath, *force, New(settings, crypto.New(), compress.New(), jobs.New(settings)), prompts.New(), services.New(settings), jobs.New(settings))
+				err := CmdExport(settings.Context, *databaseName, *filePath, *force, *redactPath, *allowUnmatched, *encryptTo, New(settings, crypto.New(), compress.New(), jobs.New(settings)), prompts.New(), services.New(settings), jobs.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 			}
-			subCmd.Spec = "DATABASE_NAME FILEPATH [-f]"
+			subCmd.Spec = "DATABASE_NAME FILEPATH [-f] [--redact] [--allow-unmatched] [--encrypt-to]"
 		}
 	},
 }
@@ -182,15 +224,24 @@ var ImportSubCmd = models.Command{
 		"Regardless of a successful import or not, the logs for the import will be printed to the console when the import is finished. " +
 		"Before an import takes place, your database is backed up automatically in case any issues arise. Here is a sample command\n\n" +
 		"<pre>\ndatica -E \"<your_env_name>\" db import db01 ./db.sql\n</pre>\n\n" +
+		"Large files are uploaded in parts. If an import is interrupted while uploading, run the same command with <code>--resume</code> to continue from the last uploaded part without backing up the database again. " +
+		"Use <code>--concurrency</code> to upload several parts at once, which can make importing very large files much faster.\n\n" +
+		"Use <code>-</code> as the file path to import from stdin, such as the output of <code>pg_dump</code>. " +
+		"Files ending in <code>.gz</code> or <code>.zst</code> are decompressed as they are imported, except for mongo imports which are uploaded as is. " +
+		"Decompressing <code>.zst</code> files requires the <code>zstd</code> command. " +
+		"Imports from stdin cannot be resumed, and the database is always backed up first.\n\n" +
+		"<pre>\npg_dump localdb | datica -E \"<your_env_name>\" db import db01 -\n</pre>\n\n" +
 		"When importing data into postgres, import cannot DROP DATABASE \"catalyzeDB\". " +
 		"Ensure your import individually removes any neccessary \"catalyzeDB\" objects, or import only into newly created postgres services where the \"catalyzeDB\" database is already empty.\n",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database to import data to (e.g. 'db01')")
-			filePath := subCmd.StringArg("FILEPATH", "", "The location of the file to import to the database")
+			filePath := subCmd.StringArg("FILEPATH", "", "The location of the file to import to the database, or - for stdin. Files ending in .gz or .zst are decompressed as they are imported")
 			mongoCollection := subCmd.StringOpt("c mongo-collection", "", "If importing into a mongo service, the name of the collection to import into")
 			mongoDatabase := subCmd.StringOpt("d mongo-database", "", "If importing into a mongo service, the name of the database to import into")
 			skipBackup := subCmd.BoolOpt("s skip-backup", false, "Skip backing up database. Useful for large databases, which can have long backup times.")
+			resume := subCmd.BoolOpt("r resume", false, "Continue an interrupted import of the same file from the last uploaded part")
+			concurrency := subCmd.IntOpt("concurrency", 1, "The number of parts to upload at once. Each part being uploaded is held in memory, up to 500MB for files over 1TB")
 			subCmd.Action = func() {
 				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
 					logrus.Fatal(err.Error())
@@ -198,12 +249,68 @@ var ImportSubCmd = models.Command{
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdImport(*databaseName, *filePath, *mongoCollection, *mongoDatabase, *skipBackup, New(settings, crypto.New(), compress.New(), jobs.New(settings)), prompts.New(), services.New(settings), jobs.New(settings))
+				err := CmdImport(settings.Context, *databaseName, *filePath, *mongoCollection, *mongoDatabase, *skipBackup, *resume, *concurrency, New(settings, crypto.New(), compress.New(), jobs.New(settings)), prompts.New(), services.New(settings), jobs.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 			}
-			subCmd.Spec = "DATABASE_NAME FILEPATH [-s][-d][-c]"
+			subCmd.Spec = "DATABASE_NAME FILEPATH [-s][-d][-c][-r][--concurrency]"
+		}
+	},
+}
+
+var CopySubCmd = models.Command{
+	Name:      "copy",
+	ShortHelp: "Copy a database into another database, even in another environment",
+	LongHelp: "<code>db copy</code> imports a backup of one database into another database of the same type. " +
+		"The databases can be in different environments, so the global <code>-E</code> option is not used. " +
+		"A new backup of the source database is created unless <code>--backup-id</code> is given. " +
+		"The backup is downloaded, decrypted, encrypted again, and uploaded into an import of the target database in a single pass, so it is never written to disk. " +
+		"The import is only started once every part of the backup has been verified. " +
+		"Like <code>db import</code>, the target database is always backed up before anything is imported into it, and the logs for the import will be printed to the console when the import is finished. Here is a sample command\n\n" +
+		"<pre>\ndatica db copy --from-env \"<your_prod_env_name>\" --from db01 --to-env \"<your_staging_env_name>\" --to db01\n</pre>\n\n" +
+		"When copying into postgres, import cannot DROP DATABASE \"catalyzeDB\". " +
+		"Ensure the target only has \"catalyzeDB\" objects that the backup replaces, or copy only into newly created postgres services where the \"catalyzeDB\" database is already empty.\n",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			fromEnv := subCmd.StringOpt("from-env", "", "The name or ID of the environment to copy from")
+			fromDatabase := subCmd.StringOpt("from", "", "The name of the database to copy from (e.g. 'db01')")
+			toEnv := subCmd.StringOpt("to-env", "", "The name or ID of the environment to copy into")
+			toDatabase := subCmd.StringOpt("to", "", "The name of the database to copy into (e.g. 'db01')")
+			backupID := subCmd.StringOpt("b backup-id", "", "The ID of an existing backup of the source database to copy instead of creating a new one (found from \"datica db list\")")
+			concurrency := subCmd.IntOpt("concurrency", 1, "The number of parts to upload at once. Each part being uploaded is held in memory")
+			subCmd.Action = func() {
+				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				fromSettings, err := EnvironmentSettings(settings, *fromEnv)
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+				toSettings, err := EnvironmentSettings(settings, *toEnv)
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+				from := CopyDatabase{
+					EnvName:  fromSettings.EnvironmentName,
+					Label:    *fromDatabase,
+					Db:       New(fromSettings, crypto.New(), compress.New(), jobs.New(fromSettings)),
+					Services: services.New(fromSettings),
+					Jobs:     jobs.New(fromSettings),
+				}
+				to := CopyDatabase{
+					EnvName:  toSettings.EnvironmentName,
+					Label:    *toDatabase,
+					Db:       New(toSettings, crypto.New(), compress.New(), jobs.New(toSettings)),
+					Services: services.New(toSettings),
+					Jobs:     jobs.New(toSettings),
+				}
+				err = CmdCopy(settings.Context, from, to, *backupID, *concurrency, prompts.New())
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "--from-env --from --to-env --to [-b] [--concurrency]"
 		}
 	},
 }
@@ -212,13 +319,25 @@ var ListSubCmd = models.Command{
 	Name:      "list",
 	ShortHelp: "List created backups",
 	LongHelp: "<code>db list</code> lists all previously created backups. " +
-		"After listing backups you can copy the backup ID and use it to download that backup or view the logs from that backup. Here is a sample command\n\n" +
-		"<pre>\ndatica -E \"<your_env_name>\" db list db01\n</pre>",
+		"After listing backups you can copy the backup ID and use it to download that backup or view the logs from that backup. " +
+		"Use <code>--type</code> to list restore or import jobs instead. " +
+		"Jobs are shown one page at a time unless <code>--all</code> is given. " +
+		"<code>--since</code> and <code>--until</code> only show jobs created at or after, and before, a date such as 2006-01-02 or a local time such as 2006-01-02T15:04. " +
+		"Filters are applied to the page that was retrieved, so use <code>--all</code> to search every job. " +
+		"The method column shows whether a backup is a snapshot or a logical dump. " +
+		"Use the global <code>--output json</code> option to script against the list. Here are some sample commands\n\n" +
+		"<pre>\ndatica -E \"<your_env_name>\" db list db01\n" +
+		"datica -E \"<your_env_name>\" db list db01 --all --status finished --until 2006-01-02T02:00\n</pre>",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(subCmd *cli.Cmd) {
 			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database service to list backups for (e.g. 'db01')")
 			page := subCmd.IntOpt("p page", 1, "The page to view")
 			pageSize := subCmd.IntOpt("n page-size", 10, "The number of items to show per page")
+			all := subCmd.BoolOpt("a all", false, "List every job instead of a single page")
+			since := subCmd.StringOpt("since", "", "Only list jobs created at or after this date or time")
+			until := subCmd.StringOpt("until", "", "Only list jobs created before this date or time")
+			status := subCmd.StringOpt("status", "", "Only list jobs with this status, such as 'finished' or 'failed'")
+			jobType := subCmd.StringOpt("type", "backup", "The type of jobs to list, one of 'backup', 'restore', or 'import'")
 			subCmd.Action = func() {
 				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
 					logrus.Fatal(err.Error())
@@ -226,12 +345,13 @@ var ListSubCmd = models.Command{
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdList(*databaseName, *page, *pageSize, New(settings, crypto.New(), compress.New(), jobs.New(settings)), services.New(settings))
+				filter := ListFilter{Type: *jobType, Status: *status, Since: *since, Until: *until}
+				err := CmdList(*databaseName, *page, *pageSize, *all, filter, New(settings, crypto.New(), compress.New(), jobs.New(settings)), services.New(settings), output.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 			}
-			subCmd.Spec = "DATABASE_NAME [-p] [-n]"
+			subCmd.Spec = "DATABASE_NAME [-p] [-n] [-a] [--since] [--until] [--status] [--type]"
 		}
 	},
 }
@@ -263,19 +383,98 @@ var LogsSubCmd = models.Command{
 	},
 }
 
+var PruneSubCmd = models.Command{
+	Name:      "prune",
+	ShortHelp: "Delete old backups according to a retention policy",
+	LongHelp: "<code>db prune</code> deletes the backups of a database service that are not kept by the given retention policy. " +
+		"<code>--keep-last</code> keeps the most recent backups, <code>--keep-daily</code> keeps the newest backup of each of the most recent days that have a backup, and <code>--keep-weekly</code> does the same for weeks. " +
+		"A backup kept by any one of these is not deleted. When <code>--older-than</code> is given, only backups older than that age, such as 90d, 2w, or 36h, are deleted. " +
+		"At least one of these options must be given. Backups that have not finished are never deleted. " +
+		"The newest finished backup is always kept, even when it is older than <code>--older-than</code>, unless <code>--allow-delete-all</code> is given. " +
+		"The backups to delete are printed and you will be asked to confirm before they are deleted. " +
+		"Use <code>--dry-run</code> to only print the backups that would be deleted. Here is a sample command\n\n" +
+		"<pre>\ndatica -E \"<your_env_name>\" db prune db01 --keep-last 7 --keep-weekly 8 --older-than 30d\n</pre>",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database service to prune backups for (e.g. 'db01')")
+			keepLast := subCmd.IntOpt("keep-last", 0, "Keep this many of the most recent backups")
+			keepDaily := subCmd.IntOpt("keep-daily", 0, "Keep the newest backup of this many days")
+			keepWeekly := subCmd.IntOpt("keep-weekly", 0, "Keep the newest backup of this many weeks")
+			olderThan := subCmd.StringOpt("older-than", "", "Only delete backups older than this age, such as 90d")
+			allowDeleteAll := subCmd.BoolOpt("allow-delete-all", false, "Allow the newest finished backup to be deleted when no other option keeps it")
+			dryRun := subCmd.BoolOpt("dry-run", false, "Print the backups that would be deleted without deleting them")
+			skipConfirm := subCmd.BoolOpt("y yes", false, "Skip the confirmation prompt")
+			subCmd.Action = func() {
+				policy := RetentionPolicy{KeepLast: *keepLast, KeepDaily: *keepDaily, KeepWeekly: *keepWeekly, AllowDeleteAll: *allowDeleteAll}
+				if *olderThan != "" {
+					age, err := ParseAge(*olderThan)
+					if err != nil {
+						logrus.Fatal(err.Error())
+					}
+					policy.OlderThan = age
+				}
+				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				if err := config.CheckRequiredAssociation(settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				err := CmdPrune(*databaseName, policy, *dryRun, *skipConfirm, New(settings, crypto.New(), compress.New(), jobs.New(settings)), prompts.New(), services.New(settings), jobs.New(settings))
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "DATABASE_NAME [--keep-last] [--keep-daily] [--keep-weekly] [--older-than] [--allow-delete-all] [--dry-run] [-y]"
+		}
+	},
+}
+
+var VerifySubCmd = models.Command{
+	Name:      "verify",
+	ShortHelp: "Check that a backup can be restored without saving it",
+	LongHelp: "<code>db verify</code> downloads a previously created backup and checks that it can be restored, without writing any of it to disk. " +
+		"Every chunk of the backup is decrypted and authenticated, compressed backups are decompressed, and the result is checked to be a PostgreSQL or MySQL dump, or a tar archive for mongo. " +
+		"The decrypted data is discarded as it is checked. " +
+		"The size and SHA-256 checksum of both the encrypted backup and the backup as <code>db download</code> would save it are printed. " +
+		"The ID of the backup is found by first running the db list command. Here is a sample command\n\n" +
+		"<pre>\ndatica -E \"<your_env_name>\" db verify db01 cd2b4bce-2727-42d1-89e0-027bf3f1a203\n</pre>",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			databaseName := subCmd.StringArg("DATABASE_NAME", "", "The name of the database service which was backed up (e.g. 'db01')")
+			backupID := subCmd.StringArg("BACKUP_ID", "", "The ID of the backup to verify (found from \"datica db list\")")
+			subCmd.Action = func() {
+				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				if err := config.CheckRequiredAssociation(settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				err := CmdVerify(*databaseName, *backupID, New(settings, crypto.New(), compress.New(), jobs.New(settings)), services.New(settings), output.New(settings))
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "DATABASE_NAME BACKUP_ID"
+		}
+	},
+}
+
 // IDb
 type IDb interface {
 	Backup(service *models.Service) (*models.Job, error)
 	Restore(backupID string, service *models.Service, mongoDatabase string) error
-	Download(backupID, filePath string, service *models.Service) error
-	Export(filePath string, job *models.Job, service *models.Service) error
-	Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollection, mongoDatabase string, service *models.Service, singleUploadMode bool) (*models.Job, error)
-	List(page, pageSize int, service *models.Service) (*[]models.Job, error)
+	Download(backupID, filePath string, service *models.Service, recipient crypto.Recipient) error
+	Export(filePath string, job *models.Job, service *models.Service, rules *redact.Rules, recipient crypto.Recipient) error
+	StreamBackup(w io.Writer, job *models.Job, service *models.Service, showProgress bool) error
+	Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollection, mongoDatabase string, service *models.Service, singleUploadMode bool, state *ImportState, concurrency int) (*models.Job, error)
+	List(jobType string, page, pageSize int, service *models.Service) (*[]models.Job, error)
 	TempDownloadURL(jobID string, service *models.Service) (*models.TempURL, error)
 	TempLogsURL(jobID string, serviceID string) (*models.TempURL, error)
 	DumpLogs(taskType string, job *models.Job, service *models.Service) error
+	TailLogs(ctx context.Context, taskType string, job *models.Job, service *models.Service) *LogTail
 	NewEncryptReader(reader io.Reader, key, iv []byte) (*gcm.EncryptReader, error)
 	RetrievePodApiVersion() (*models.VersionInfo, error)
+	Verify(backupID string, service *models.Service) (*BackupVerification, error)
 }
 
 // SDb is a concrete implementation of IDb
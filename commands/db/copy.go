@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/commands/environments"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/config"
+	"github.com/daticahealth/cli/lib/crypto"
+	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/lib/prompts"
+	"github.com/daticahealth/cli/lib/transfer"
+	"github.com/daticahealth/cli/models"
+)
+
+// CopyDatabase is one side of a database copy. Each side can be in a
+// different environment, so it has its own clients for that environment.
+type CopyDatabase struct {
+	EnvName  string
+	Label    string
+	Db       IDb
+	Services services.IServices
+	Jobs     jobs.IJobs
+}
+
+func (c *CopyDatabase) service() (*models.Service, error) {
+	service, err := c.Services.RetrieveByLabel(c.Label)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return nil, fmt.Errorf("Could not find a service with the label \"%s\" in %s. You can list services with the \"datica -E \\\"%s\\\" services list\" command.", c.Label, c.EnvName, c.EnvName)
+	}
+	return service, nil
+}
+
+func (c *CopyDatabase) String() string {
+	return fmt.Sprintf("%s (%s)", c.Label, c.EnvName)
+}
+
+// EnvironmentSettings returns a copy of the settings for running commands in
+// the given environment. The list of environments is refreshed if the
+// environment is not already known.
+func EnvironmentSettings(settings *models.Settings, envName string) (*models.Settings, error) {
+	envSettings := *settings
+	envSettings.EnvironmentID = ""
+	config.SetGivenEnv(envName, &envSettings)
+	if envSettings.EnvironmentID == "" {
+		envs, errs := environments.New(settings).List()
+		for pod, err := range errs {
+			logrus.Debugf("Failed to list environments for pod \"%s\": %s", pod, err)
+		}
+		if envs != nil && len(*envs) > 0 {
+			config.StoreEnvironments(envs, settings)
+			envSettings.Environments = settings.Environments
+			config.SetGivenEnv(envName, &envSettings)
+		}
+	}
+	if envSettings.EnvironmentID == "" {
+		return nil, fmt.Errorf("Could not find an environment named \"%s\". You can list environments with the \"datica environments list\" command.", envName)
+	}
+	return &envSettings, nil
+}
+
+func CmdCopy(ctx context.Context, from, to CopyDatabase, backupID string, concurrency int, ip prompts.IPrompts) error {
+	if concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	err := ip.PHI()
+	if err != nil {
+		return err
+	}
+	fromService, err := from.service()
+	if err != nil {
+		return err
+	}
+	toService, err := to.service()
+	if err != nil {
+		return err
+	}
+	if fromService.ID == toService.ID {
+		return fmt.Errorf("A database cannot be copied onto itself")
+	}
+	if fromService.Name != toService.Name {
+		return fmt.Errorf("%s is a %s database but %s is a %s database. Backups can only be copied between databases of the same type", from.String(), fromService.Name, to.String(), toService.Name)
+	}
+	versionInfo, err := to.Db.RetrievePodApiVersion()
+	if err != nil {
+		return err
+	}
+	if versionInfo.Version < "4.1.0" {
+		return fmt.Errorf("Copying into %s is not supported by its environment. Download the backup and import it instead", to.String())
+	}
+
+	var job *models.Job
+	source := fmt.Sprintf("A new backup of %s", from.String())
+	if backupID != "" {
+		job, err = from.Jobs.Retrieve(backupID, fromService.ID, false)
+		if err != nil {
+			return err
+		}
+		if job.Type != "backup" || (job.Status != "finished" && job.Status != "disappeared") {
+			return errors.New("Only 'finished' 'backup' jobs may be copied")
+		}
+		source = fmt.Sprintf("Backup %s of %s", job.ID, from.String())
+	}
+	if toService.Name == "postgresql" {
+		fmt.Println("WARNING: Import cannot DROP DATABASE \"catalyzeDB\". Ensure your import individually removes any necessary \"catalyzeDB\" objects, or import only into newly created postgres services where the \"catalyzeDB\" database is already empty.")
+	}
+	err = ip.YesNo(fmt.Sprintf("%s will be imported into %s. %s will be backed up first.", source, to.String(), to.Label), "Do you wish to proceed? (y/n) ")
+	if err != nil {
+		return err
+	}
+
+	if job == nil {
+		logrus.Printf("Backing up %s to copy it", from.String())
+		job, err = backupAndWait(ctx, from.Label, fromService, from.Db, from.Jobs)
+		if err != nil {
+			return err
+		}
+		// the backup's encryption key is only returned once it has finished
+		job, err = from.Jobs.Retrieve(job.ID, fromService.ID, false)
+		if err != nil {
+			return err
+		}
+	}
+	logrus.Printf("Backing up \"%s\" before performing the import", to.Label)
+	if _, err = backupAndWait(ctx, to.Label, toService, to.Db, to.Jobs); err != nil {
+		return err
+	}
+
+	logrus.Printf("Copying backup %s of %s into %s", job.ID, from.String(), to.String())
+	importJob, err := copyBackup(job, fromService, toService, from.Db, to.Db, concurrency)
+	if err != nil {
+		return err
+	}
+	return waitForImport(ctx, importJob, toService, to.Db, to.Jobs)
+}
+
+// copyBackup streams a backup into an import of another database. The backup
+// is decrypted and encrypted again with a new key in memory as it is
+// uploaded, so none of it is ever written to disk. The import is only started
+// once every chunk of the backup has passed authentication.
+func copyBackup(job *models.Job, fromService, toService *models.Service, from, to IDb, concurrency int) (*models.Job, error) {
+	key := make([]byte, crypto.KeySize)
+	iv := make([]byte, crypto.IVSize)
+	rand.Read(key)
+	rand.Read(iv)
+
+	pr, pw := io.Pipe()
+	streamed := make(chan error, 1)
+	go func() {
+		err := from.StreamBackup(pw, job, fromService, false)
+		pw.CloseWithError(err)
+		streamed <- err
+	}()
+	// stop the download if the upload stops early
+	defer func() {
+		pr.CloseWithError(errors.New("The copy was stopped"))
+		<-streamed
+	}()
+	encryptReader, err := to.NewEncryptReader(pr, key, iv)
+	if err != nil {
+		return nil, err
+	}
+	rt := transfer.NewReaderTransfer(encryptReader, -1)
+	return to.Import(rt, key, iv, "", "", toService, false, &ImportState{Parts: []UploadedPart{}}, concurrency)
+}
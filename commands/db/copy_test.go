@@ -0,0 +1,127 @@
+package db
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/compress"
+	"github.com/daticahealth/cli/lib/crypto"
+	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/models"
+	"github.com/daticahealth/cli/test"
+)
+
+const (
+	copySourceEnvName = "copy-source"
+	copySourceEnvID   = "copy-source-env"
+	copySourceJobID   = "copy-source-job"
+)
+
+var dbCopyTests = []struct {
+	fromEnv   string
+	from      string
+	toEnv     string
+	backupID  string
+	corrupt   bool
+	expectErr bool
+}{
+	{copySourceEnvName, dbName, test.EnvName, copySourceJobID, false, false},
+	{copySourceEnvID, dbName, test.EnvID, copySourceJobID, false, false},
+	{copySourceEnvName, dbName, test.EnvName, copySourceJobID, true, true}, // fails authentication
+	{copySourceEnvName, dbName, test.EnvName, "running-job", false, true},
+	{copySourceEnvName, "mysql-db", test.EnvName, copySourceJobID, false, true},
+	{test.EnvName, dbName, test.EnvName, copySourceJobID, false, true},
+	{"invalid-env", dbName, test.EnvName, copySourceJobID, false, true},
+}
+
+func TestDbCopy(t *testing.T) {
+	oldChunkSize := importChunkSize
+	importChunkSize = 16
+	defer func() { importChunkSize = oldChunkSize }()
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	settings.Environments[copySourceEnvName] = models.AssociatedEnvV2{
+		Name:          copySourceEnvName,
+		EnvironmentID: copySourceEnvID,
+		Pod:           test.Pod,
+		OrgID:         test.OrgID,
+	}
+	m := setupMultipartImport(mux, baseURL.String())
+
+	contents := strings.Repeat("select 1;\n", 5)
+	var backup []byte
+	mux.HandleFunc("/environments/"+copySourceEnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"id":"source-db","label":"`+dbName+`"},{"id":"source-mysql","label":"mysql-db","name":"mysql"}]`)
+		},
+	)
+	mux.HandleFunc("/environments/"+copySourceEnvID+"/services/source-db/jobs/"+copySourceJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","type":"backup","status":"finished","backup":{"key":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, copySourceJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+copySourceEnvID+"/services/source-db/jobs/running-job",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"id":"running-job","type":"backup","status":"running"}`)
+		},
+	)
+	mux.HandleFunc("/environments/"+copySourceEnvID+"/services/source-db/backup-url/"+copySourceJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/source-backup"}`, baseURL.String()))
+		},
+	)
+	mux.HandleFunc("/source-backup",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("x-amz-meta-datica-backup-compression", "gzip")
+			w.Write(backup)
+		},
+	)
+
+	for _, data := range dbCopyTests {
+		t.Logf("Data: %+v", data)
+		backup = encryptTestBackup(t, gzipTestData([]byte(contents)))
+		if data.corrupt {
+			backup[len(backup)-1]++
+		}
+		m.completeBody = ""
+
+		// test
+		err := copyBetween(settings, data.fromEnv, data.from, data.toEnv, dbName, data.backupID)
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if data.expectErr {
+			if m.completeBody != "" {
+				t.Errorf("Expected the upload to not be completed")
+			}
+			continue
+		}
+		if decrypted := m.decrypt(t); decrypted != contents {
+			t.Errorf("Expected %q to be imported but got %q", contents, decrypted)
+		}
+	}
+}
+
+// copyBetween runs a copy the same way the command does, resolving each side
+// from its environment
+func copyBetween(settings *models.Settings, fromEnv, from, toEnv, to, backupID string) error {
+	fromSettings, err := EnvironmentSettings(settings, fromEnv)
+	if err != nil {
+		return err
+	}
+	toSettings, err := EnvironmentSettings(settings, toEnv)
+	if err != nil {
+		return err
+	}
+	return CmdCopy(settings.Context,
+		CopyDatabase{fromSettings.EnvironmentName, from, New(fromSettings, crypto.New(), compress.New(), jobs.New(fromSettings)), services.New(fromSettings), jobs.New(fromSettings)},
+		CopyDatabase{toSettings.EnvironmentName, to, New(toSettings, crypto.New(), compress.New(), jobs.New(toSettings)), services.New(toSettings), jobs.New(toSettings)},
+		backupID, 1, &test.FakePrompts{})
+}
@@ -3,25 +3,25 @@ package db
 import (
 	"errors"
 	"fmt"
-	"os"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/crypto"
 	"github.com/daticahealth/cli/lib/prompts"
 	"github.com/daticahealth/cli/models"
 )
 
-func CmdDownload(databaseName, backupID, filePath string, force bool, id IDb, ip prompts.IPrompts, is services.IServices) error {
+func CmdDownload(databaseName, backupID, filePath string, force bool, encryptTo string, id IDb, ip prompts.IPrompts, is services.IServices) error {
 	err := ip.PHI()
 	if err != nil {
 		return err
 	}
-	if !force {
-		if _, err := os.Stat(filePath); err == nil {
-			return fmt.Errorf("File already exists at path '%s'. Specify `--force` to overwrite", filePath)
-		}
-	} else {
-		os.Remove(filePath)
+	if err = checkOutputFile(filePath, force); err != nil {
+		return err
+	}
+	recipient, err := parseRecipient(encryptTo)
+	if err != nil {
+		return err
 	}
 	service, err := is.RetrieveByLabel(databaseName)
 	if err != nil {
@@ -30,18 +30,20 @@ func CmdDownload(databaseName, backupID, filePath string, force bool, id IDb, ip
 	if service == nil {
 		return fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"datica services list\" command.", databaseName)
 	}
-	err = id.Download(backupID, filePath, service)
+	err = id.Download(backupID, filePath, service, recipient)
 	if err != nil {
 		return err
 	}
-	logrus.Printf("%s backup downloaded successfully to %s", databaseName, filePath)
+	logrus.Printf("%s backup downloaded successfully to %s", databaseName, outputName(filePath))
 	logrus.Printf("You can also view logs for this backup with the \"datica db logs %s %s\" command", databaseName, backupID)
 	return nil
 }
 
 // Download an existing backup to the local machine. The backup is encrypted
 // throughout the entire journey and then decrypted once it is stored locally.
-func (d *SDb) Download(backupID, filePath string, service *models.Service) error {
+// When a recipient is given, it is encrypted to the recipient instead as it is
+// decrypted, so it is never stored in plaintext.
+func (d *SDb) Download(backupID, filePath string, service *models.Service, recipient crypto.Recipient) error {
 	job, err := d.Jobs.Retrieve(backupID, service.ID, false)
 	if err != nil {
 		return err
@@ -49,12 +51,12 @@ func (d *SDb) Download(backupID, filePath string, service *models.Service) error
 	if job.Type != "backup" || (job.Status != "finished" && job.Status != "disappeared") {
 		return errors.New("Only 'finished' 'backup' jobs may be downloaded")
 	}
-	return d.Export(filePath, job, service)
+	return d.Export(filePath, job, service, nil, recipient)
 }
 
 func (d *SDb) TempDownloadURL(jobID string, service *models.Service) (*models.TempURL, error) {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/backup-url/%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, jobID), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Get(d.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/backup-url/%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, jobID), headers)
 	if err != nil {
 		return nil, err
 	}
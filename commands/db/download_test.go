@@ -1,13 +1,21 @@
 package db
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
 	"github.com/daticahealth/cli/lib/compress"
 	"github.com/daticahealth/cli/lib/crypto"
@@ -65,7 +73,7 @@ func TestDbDownload(t *testing.T) {
 		t.Logf("Data: %+v", data)
 
 		// test
-		err := CmdDownload(data.databaseName, data.backupID, data.filePath, data.force, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings))
+		err := CmdDownload(data.databaseName, data.backupID, data.filePath, data.force, "", New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings))
 
 		// assert
 		if err != nil {
@@ -82,3 +90,344 @@ func TestDbDownload(t *testing.T) {
 	}
 	os.Remove(downloadFilePath)
 }
+
+var encryptedBackup = []byte{186, 194, 51, 73, 71, 71, 38, 3, 182, 216, 210, 144, 156, 237, 120, 227, 95, 91, 197, 59, 19} // gcm encrypted "test"
+
+var dbDownloadResumeTests = []struct {
+	partial   []byte
+	backup    []byte
+	expectErr bool
+}{
+	{nil, encryptedBackup, false},
+	{encryptedBackup[:10], encryptedBackup, false},                                // resumed from the partial file
+	{append(append([]byte{}, encryptedBackup[:9]...), 0), encryptedBackup, false}, // mismatched partial file starts over
+	{encryptedBackup, encryptedBackup, false},                                     // already fully downloaded
+	{append(encryptedBackup, 0), encryptedBackup, false},                          // partial file larger than the backup starts over
+	{nil, append(append([]byte{}, encryptedBackup[:20]...), 0), true},             // fails authentication
+	{nil, encryptedBackup[:16], true},                                             // truncated backup fails authentication
+}
+
+func TestDbDownloadResume(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+
+	var backup []byte
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s"}]`, dbID, dbName))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"finished","backup":{"key":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-url/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/backup"}`, baseURL.String()))
+		},
+	)
+	mux.HandleFunc("/backup",
+		func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "backup", time.Time{}, bytes.NewReader(backup))
+		},
+	)
+
+	partialPath := fmt.Sprintf("%s.%s.partial", downloadFilePath, dbJobID)
+	for _, data := range dbDownloadResumeTests {
+		t.Logf("Data: %+v", data)
+		backup = data.backup
+		os.Remove(downloadFilePath)
+		os.Remove(partialPath)
+		if data.partial != nil {
+			if err := ioutil.WriteFile(partialPath, data.partial, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// test
+		err := CmdDownload(dbName, dbJobID, downloadFilePath, false, "", New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings))
+
+		// assert
+		if _, statErr := os.Stat(partialPath); !os.IsNotExist(statErr) {
+			t.Errorf("Expected the partial file to be removed")
+		}
+		if err != nil {
+			if !data.expectErr {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if _, statErr := os.Stat(downloadFilePath); !os.IsNotExist(statErr) {
+				t.Errorf("Expected no output file when the download fails verification")
+			}
+			continue
+		} else if data.expectErr {
+			t.Errorf("Expected error but got nil")
+			continue
+		}
+
+		b, _ := ioutil.ReadFile(downloadFilePath)
+		if strings.TrimSpace(string(b)) != "test" {
+			t.Errorf("Unexpected file contents. Expected: test, actual: %s", string(b))
+		}
+	}
+	os.Remove(downloadFilePath)
+	os.Remove(partialPath)
+}
+
+// TestDbExportResume interrupts an export and resumes it with db download,
+// since running the export again would create a new backup
+func TestDbExportResume(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	settings.Context = ctx
+
+	interrupted := false
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s"}]`, dbID, dbName))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "POST")
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"running","backup":{"key":"0000000000000000000000000000000000000000000000000000000000000000","keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"finished","backup":{"key":"0000000000000000000000000000000000000000000000000000000000000000","keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-url/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/backup"}`, baseURL.String()))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-restore-logs-url/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/logs"}`, baseURL.String()))
+		},
+	)
+	mux.HandleFunc("/logs",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write(encryptedBackup)
+		},
+	)
+	mux.HandleFunc("/backup",
+		func(w http.ResponseWriter, r *http.Request) {
+			if !interrupted {
+				// send part of the backup, then drop the connection
+				interrupted = true
+				w.Header().Set("Content-Length", fmt.Sprintf("%d", len(encryptedBackup)))
+				w.Write(encryptedBackup[:10])
+				w.(http.Flusher).Flush()
+				cancel()
+				return
+			}
+			http.ServeContent(w, r, "backup", time.Time{}, bytes.NewReader(encryptedBackup))
+		},
+	)
+	var logs bytes.Buffer
+	logger := logrus.StandardLogger()
+	oldOut := logger.Out
+	logrus.SetOutput(&logs)
+	defer logrus.SetOutput(oldOut)
+	partialPath := fmt.Sprintf("%s.%s.partial", exportFilePath, dbJobID)
+	os.Remove(exportFilePath)
+	defer os.Remove(exportFilePath)
+	defer os.Remove(partialPath)
+
+	// test
+	err := CmdExport(settings.Context, dbName, exportFilePath, false, "", false, "", New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+
+	// assert
+	if err == nil {
+		t.Fatal("Expected the interrupted export to fail")
+	}
+	if _, statErr := os.Stat(partialPath); statErr != nil {
+		t.Fatalf("Expected the partial file to be kept: %s", statErr)
+	}
+	resume := fmt.Sprintf("datica db download %s %s %s", dbName, dbJobID, exportFilePath)
+	if !strings.Contains(logs.String(), resume) {
+		t.Errorf("Expected to be told to resume with %q, got %s", resume, logs.String())
+	}
+
+	// test
+	settings.Context = context.Background()
+	err = CmdDownload(dbName, dbJobID, exportFilePath, false, "", New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings))
+
+	// assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, statErr := os.Stat(partialPath); !os.IsNotExist(statErr) {
+		t.Errorf("Expected the partial file to be removed")
+	}
+	b, _ := ioutil.ReadFile(exportFilePath)
+	if strings.TrimSpace(string(b)) != "test" {
+		t.Errorf("Unexpected file contents. Expected: test, actual: %s", string(b))
+	}
+}
+
+var dbDownloadStdoutTests = []struct {
+	backup    []byte
+	expectErr bool
+}{
+	{encryptedBackup, false},
+	{append(append([]byte{}, encryptedBackup[:20]...), 0), true}, // fails authentication
+}
+
+func TestDbDownloadStdout(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	var backup []byte
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s"}]`, dbID, dbName))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"finished","backup":{"key":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-url/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/backup"}`, baseURL.String()))
+		},
+	)
+	mux.HandleFunc("/backup",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write(backup)
+		},
+	)
+
+	for _, data := range dbDownloadStdoutTests {
+		t.Logf("Data: %+v", data)
+		backup = data.backup
+		buf.Reset()
+
+		// test
+		err := CmdDownload(dbName, dbJobID, StdoutFilePath, false, "", New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings))
+
+		// assert
+		if _, statErr := os.Stat(StdoutFilePath); !os.IsNotExist(statErr) {
+			t.Errorf("Expected no file to be created")
+		}
+		if err != nil {
+			if !data.expectErr {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("Expected nothing unverified to be written, got %s", buf.String())
+			}
+			continue
+		} else if data.expectErr {
+			t.Errorf("Expected error but got nil")
+			continue
+		}
+		test.AssertEquals(t, "test", strings.TrimSpace(buf.String()))
+	}
+}
+
+var dbDownloadEncryptToTests = []struct {
+	filePath  string
+	encryptTo string
+	expectErr bool
+}{
+	{downloadFilePath, "db-download.pub.pem", false},
+	{StdoutFilePath, "db-download.pub.pem", false},
+	{downloadFilePath, "does-not-exist.pem", true},
+	{downloadFilePath, "age1notakey", true},
+}
+
+func TestDbDownloadEncryptTo(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	ioutil.WriteFile("db-download.pub.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0600)
+	ioutil.WriteFile("db-download.pem", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600)
+	defer os.Remove("db-download.pub.pem")
+	defer os.Remove("db-download.pem")
+	identity, err := crypto.LoadIdentity("db-download.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s"}]`, dbID, dbName))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"finished","backup":{"key":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-url/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/backup"}`, baseURL.String()))
+		},
+	)
+	mux.HandleFunc("/backup",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write(encryptedBackup)
+		},
+	)
+
+	for _, data := range dbDownloadEncryptToTests {
+		t.Logf("Data: %+v", data)
+		buf.Reset()
+		os.Remove(downloadFilePath)
+
+		// test
+		err := CmdDownload(dbName, dbJobID, data.filePath, false, data.encryptTo, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings))
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if data.expectErr {
+			continue
+		}
+		encrypted := buf.Bytes()
+		if data.filePath != StdoutFilePath {
+			encrypted, _ = ioutil.ReadFile(data.filePath)
+		}
+		if bytes.Contains(encrypted, []byte("test")) {
+			t.Errorf("Expected the backup to be encrypted, got %q", encrypted)
+		}
+		r, err := crypto.New().NewDecryptFromReader(bytes.NewReader(encrypted), identity)
+		if err != nil {
+			t.Errorf("Unexpected error decrypting the backup: %s", err)
+			continue
+		}
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("Unexpected error decrypting the backup: %s", err)
+		}
+		test.AssertEquals(t, "test", strings.TrimSpace(string(b)))
+	}
+	os.Remove(downloadFilePath)
+}
@@ -1,27 +1,51 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/crypto"
 	"github.com/daticahealth/cli/lib/httpclient"
 	"github.com/daticahealth/cli/lib/jobs"
 	"github.com/daticahealth/cli/lib/prompts"
+	"github.com/daticahealth/cli/lib/redact"
 	"github.com/daticahealth/cli/lib/transfer"
 	"github.com/daticahealth/cli/models"
 )
 
-func CmdExport(databaseName, filePath string, force bool, id IDb, ip prompts.IPrompts, is services.IServices, ij jobs.IJobs) error {
-	err := ip.PHI()
-	if err != nil {
-		return err
+// maxDownloadAttempts is how many times a dropped backup download is resumed
+// before giving up
+const maxDownloadAttempts = 5
+
+// StdoutFilePath is the FILEPATH that writes a backup to stdout instead of a
+// file
+const StdoutFilePath = "-"
+
+// stdout is where backups are written when the file path is StdoutFilePath. It
+// keeps the real stdout after RedirectStdout points os.Stdout at stderr.
+var stdout io.Writer = os.Stdout
+
+// RedirectStdout sends everything a command prints, including prompts,
+// progress, and job logs, to stderr so that only the backup itself is written
+// to stdout
+func RedirectStdout() {
+	os.Stdout = os.Stderr
+	logrus.SetOutput(os.Stderr)
+}
+
+// checkOutputFile makes sure a backup can be saved to filePath, removing any
+// existing file if force is set
+func checkOutputFile(filePath string, force bool) error {
+	if filePath == StdoutFilePath {
+		return nil
 	}
 	if !force {
 		if _, err := os.Stat(filePath); err == nil {
@@ -30,6 +54,36 @@ func CmdExport(databaseName, filePath string, force bool, id IDb, ip prompts.IPr
 	} else {
 		os.Remove(filePath)
 	}
+	return nil
+}
+
+// outputName describes where a backup was saved for messages
+func outputName(filePath string) string {
+	if filePath == StdoutFilePath {
+		return "stdout"
+	}
+	return filePath
+}
+
+func CmdExport(ctx context.Context, databaseName, filePath string, force bool, redactPath string, allowUnmatched bool, encryptTo string, id IDb, ip prompts.IPrompts, is services.IServices, ij jobs.IJobs) error {
+	err := ip.PHI()
+	if err != nil {
+		return err
+	}
+	if err = checkOutputFile(filePath, force); err != nil {
+		return err
+	}
+	recipient, err := parseRecipient(encryptTo)
+	if err != nil {
+		return err
+	}
+	var rules *redact.Rules
+	if redactPath != "" {
+		rules, err = redact.Load(redactPath)
+		if err != nil {
+			return err
+		}
+	}
 	service, err := is.RetrieveByLabel(databaseName)
 	if err != nil {
 		return err
@@ -37,6 +91,11 @@ func CmdExport(databaseName, filePath string, force bool, id IDb, ip prompts.IPr
 	if service == nil {
 		return fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"datica services list\" command.", databaseName)
 	}
+	if rules != nil {
+		if err = rules.Check(service.Name); err != nil {
+			return err
+		}
+	}
 	job, err := id.Backup(service)
 	if err != nil {
 		return err
@@ -46,90 +105,416 @@ func CmdExport(databaseName, filePath string, force bool, id IDb, ip prompts.IPr
 	logrus.StandardLogger().Out.Write([]byte("Polling until backup finishes."))
 	if job.IsSnapshotBackup != nil && *job.IsSnapshotBackup {
 		logrus.Printf("This is a snapshot backup, it may be a while before this backup shows up in the \"datica db list %s\" command.", databaseName)
-		err = ij.WaitToAppear(job.ID, service.ID)
+		err = ij.WaitToAppear(ctx, job.ID, service.ID)
 		if err != nil {
 			return err
 		}
 	}
-	status, err := ij.PollTillFinished(job.ID, service.ID)
+	tail := id.TailLogs(ctx, "backup", job, service)
+	status, err := ij.PollTillFinished(ctx, job.ID, service.ID)
+	// stop checking for new lines while the backup downloads
+	tail.Stop()
 	if err != nil {
 		return err
 	}
 	job.Status = status
 	logrus.Printf("Ended in status '%s'", job.Status)
 	if job.Status != "finished" {
-		id.DumpLogs("backup", job, service)
+		tail.Finish()
 		return fmt.Errorf("Job finished with invalid status %s", job.Status)
 	}
 
-	err = id.Export(filePath, job, service)
+	err = id.Export(filePath, job, service, rules, recipient)
 	if err != nil {
 		return err
 	}
-	err = id.DumpLogs("backup", job, service)
+	err = tail.Finish()
 	if err != nil {
 		return err
 	}
-	logrus.Printf("%s exported successfully to %s", service.Name, filePath)
+	if rules != nil {
+		if err = checkUnmatched(rules.Unmatched(service.Name), filePath, allowUnmatched); err != nil {
+			return err
+		}
+	}
+	logrus.Printf("%s exported successfully to %s", service.Name, outputName(filePath))
 	return nil
 }
 
+// checkUnmatched fails an export when any redaction rule did not match
+// anything in the backup, since a misspelled table or column would otherwise
+// leave its data unredacted. The export is removed unless allowUnmatched is
+// set, in which case each unmatched rule is only a warning.
+func checkUnmatched(unmatched []*redact.Rule, filePath string, allowUnmatched bool) error {
+	if len(unmatched) == 0 {
+		return nil
+	}
+	names := []string{}
+	for _, r := range unmatched {
+		if allowUnmatched {
+			logrus.Warnf("The redaction rule for %s did not match anything in the backup", r)
+		}
+		names = append(names, r.String())
+	}
+	if allowUnmatched {
+		return nil
+	}
+	if filePath == StdoutFilePath {
+		return fmt.Errorf("The redaction rules for %s did not match anything in the backup, so the export written to stdout may not be redacted as intended. Check the rules or specify --allow-unmatched", strings.Join(names, ", "))
+	}
+	if err := os.Remove(filePath); err != nil {
+		return err
+	}
+	return fmt.Errorf("The redaction rules for %s did not match anything in the backup, so the export was removed. Check the rules or specify --allow-unmatched to keep the export", strings.Join(names, ", "))
+}
+
 // Export dumps all data from a database service and downloads the encrypted
 // data to the local machine. The export is accomplished by first creating a
 // backup. Once finished, the CLI asks where the file can be downloaded from.
-// The file is downloaded, decrypted, decompressed, and saved locally.
-func (d *SDb) Export(filePath string, job *models.Job, service *models.Service) error {
-	tempURL, err := d.TempDownloadURL(job.ID, service)
+// The encrypted file is downloaded next to the output file, resuming any
+// earlier download of the same backup. Only once the download is complete and
+// its size is verified is it decrypted, authenticated, decompressed, and saved
+// to the output file.
+//
+// When filePath is StdoutFilePath, the backup is streamed straight to stdout
+// instead so the decrypted data never touches the disk. Each chunk is still
+// authenticated before it is written, but the download cannot be resumed.
+//
+// When rules are given, the backup is redacted as it is decrypted so that the
+// unredacted backup is never written anywhere. When a recipient is given, the
+// backup is encrypted to it before it is written.
+func (d *SDb) Export(filePath string, job *models.Job, service *models.Service, rules *redact.Rules, recipient crypto.Recipient) error {
+	if filePath == StdoutFilePath {
+		if rules == nil && recipient == nil {
+			return d.StreamBackup(stdout, job, service, true)
+		}
+		w, _, err := d.wrapOutput(nopWriteCloser{stdout}, service, rules, recipient)
+		if err != nil {
+			return err
+		}
+		err = d.StreamBackup(w, job, service, true)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+	partialPath := fmt.Sprintf("%s.%s.partial", filePath, job.ID)
+	compression, err := d.downloadEncrypted(filePath, partialPath, job, service)
 	if err != nil {
 		return err
 	}
-	resp, err := http.Get(tempURL.URL)
+	// Decompress (leave MongoDB backups in compressed .tgz format)
+	err = d.decryptBackup(partialPath, filePath, compression == "gzip" && service.Name != "mongodb", job, service, rules, recipient)
 	if err != nil {
 		return err
 	}
+	return os.Remove(partialPath)
+}
+
+// downloadEncrypted downloads the encrypted backup for filePath to partialPath,
+// picking up where any earlier attempt left off. A dropped connection is
+// resumed up to maxDownloadAttempts times. The backup's compression is
+// returned.
+func (d *SDb) downloadEncrypted(filePath, partialPath string, job *models.Job, service *models.Service) (string, error) {
+	file, err := os.OpenFile(partialPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	for attempt := 1; ; attempt++ {
+		size, compression, resumable, err := d.downloadRange(file, job, service)
+		if err == nil {
+			info, err := file.Stat()
+			if err != nil {
+				return "", err
+			}
+			if info.Size() != size {
+				file.Truncate(0)
+				return "", fmt.Errorf("Downloaded %d bytes but the backup is %d bytes. Please download it again", info.Size(), size)
+			}
+			return compression, nil
+		}
+		if !resumable || attempt == maxDownloadAttempts || d.Settings.Context.Err() != nil {
+			if resumable {
+				// an export creates a new backup each time it is run, so the
+				// download is always resumed with the job ID of this backup
+				logrus.Printf("The partially downloaded backup was kept at %s. Run \"datica db download %s %s %s\" to resume the download.", partialPath, service.Label, job.ID, filePath)
+			}
+			return "", err
+		}
+		logrus.Printf("Download interrupted: %s. Resuming (attempt %d of %d)...", err, attempt+1, maxDownloadAttempts)
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// downloadRange appends the rest of the encrypted backup to file and returns
+// the total size of the backup. Whether the download can be resumed is
+// returned along with any error.
+func (d *SDb) downloadRange(file *os.File, job *models.Job, service *models.Service) (int64, string, bool, error) {
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, "", false, err
+	}
+	tempURL, err := d.TempDownloadURL(job.ID, service)
+	if err != nil {
+		return 0, "", false, err
+	}
+	req, err := http.NewRequest("GET", tempURL.URL, nil)
+	if err != nil {
+		return 0, "", false, err
+	}
+	// ask for the last byte already downloaded as well, so the range is
+	// never empty and the partial file can be checked against the backup
+	var overlap int64
+	if offset > 0 {
+		overlap = 1
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset-overlap))
+		logrus.Printf("Resuming download at %s", transfer.ByteSize(offset))
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(d.Settings.Context))
+	if err != nil {
+		return 0, "", true, err
+	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0 {
+		file.Truncate(0)
+		return 0, "", true, errors.New("the partially downloaded backup is larger than the backup, starting over")
+	}
 	if httpclient.IsError(resp.StatusCode) {
-		return httpclient.ConvertError(resp)
+		return 0, "", false, httpclient.ConvertError(resp)
 	}
-	contentLength := resp.Header.Get("Content-Length")
-	if contentLength == "" {
-		return fmt.Errorf("Export succeeded, but Content-Length was not present in the response.")
+	compression := resp.Header.Get("x-amz-meta-datica-backup-compression")
+	var size int64
+	if resp.StatusCode == http.StatusPartialContent {
+		start, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return 0, "", false, err
+		}
+		if start != offset-overlap {
+			return 0, "", false, fmt.Errorf("Requested the backup from byte %d but received it from byte %d", offset-overlap, start)
+		}
+		size = total
+		if overlap > 0 {
+			expected := make([]byte, 1)
+			actual := make([]byte, 1)
+			if _, err = file.ReadAt(expected, offset-overlap); err != nil {
+				return 0, "", false, err
+			}
+			if _, err = io.ReadFull(resp.Body, actual); err != nil {
+				return 0, "", true, err
+			}
+			if expected[0] != actual[0] {
+				file.Truncate(0)
+				return 0, "", true, errors.New("the partially downloaded backup does not match, starting over")
+			}
+		}
+	} else {
+		// the whole backup was sent, so start from the beginning
+		if resp.ContentLength < 0 {
+			return 0, "", false, fmt.Errorf("Export succeeded, but Content-Length was not present in the response.")
+		}
+		size = resp.ContentLength
+		if offset > 0 {
+			if err = file.Truncate(0); err != nil {
+				return 0, "", false, err
+			}
+			offset = 0
+		}
 	}
-	size, err := strconv.Atoi(contentLength)
+	if offset > size {
+		file.Truncate(0)
+		return 0, "", true, errors.New("the partially downloaded backup is larger than the backup, starting over")
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		return 0, "", false, err
+	}
+
+	tr := &resumedTransfer{
+		offset:              transfer.ByteSize(offset),
+		WriteCloserTransfer: transfer.NewWriteCloserTransfer(file, int(size)),
+	}
+	done := make(chan bool)
+	go printTransferStatus(true, tr, 1, 1, done)
+	_, err = io.Copy(tr, resp.Body)
+	done <- err == nil
+	if err != nil {
+		return 0, "", true, err
+	}
+	return size, compression, false, nil
+}
+
+// decryptBackup decrypts and optionally decompresses a fully downloaded
+// backup. Every chunk is authenticated, so a corrupt download fails here and
+// the output file is only created once the entire backup has been verified. A
+// backup that fails to be redacted is removed as well since an export always
+// creates a new backup.
+func (d *SDb) decryptBackup(partialPath, filePath string, decompress bool, job *models.Job, service *models.Service, rules *redact.Rules, recipient crypto.Recipient) error {
+	logrus.Println("Decrypting and verifying...")
+	in, err := os.Open(partialPath)
 	if err != nil {
 		return err
 	}
-	var file io.WriteCloser
-	file, err = os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	defer in.Close()
+	tmpPath := filePath + ".tmp"
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
 	}
-	// Decompress (leave MongoDB backups in compressed .tgz format)
-	backupCompression := resp.Header.Get("x-amz-meta-datica-backup-compression")
-	if backupCompression == "gzip" && service.Name != "mongodb" {
+	defer out.Close()
+	file, rw, err := d.wrapOutput(out, service, rules, recipient)
+	if err != nil {
+		return err
+	}
+	if decompress {
 		file, err = d.Compress.NewDecompressWriteCloser(file)
 		if err != nil {
 			return err
 		}
 	}
-
 	dfw, err := d.Crypto.NewDecryptWriteCloser(file, job.Backup.Key, job.Backup.IV)
 	if err != nil {
 		return err
 	}
+	_, err = io.Copy(dfw, in)
+	if err == nil {
+		err = dfw.Close()
+	}
+	if err != nil && rw != nil {
+		redactErr := rw.Err()
+		rw.Close()
+		if redactErr != nil {
+			out.Close()
+			in.Close()
+			os.Remove(tmpPath)
+			os.Remove(partialPath)
+			return redactErr
+		}
+	}
+	if err != nil {
+		out.Close()
+		in.Close()
+		os.Remove(tmpPath)
+		os.Remove(partialPath)
+		return fmt.Errorf("The downloaded backup failed verification and was removed, please download it again: %s", err)
+	}
+	out.Close()
+	return os.Rename(tmpPath, filePath)
+}
 
-	wct := transfer.NewWriteCloserTransfer(dfw, size)
-	done := make(chan bool)
-	go printTransferStatus(true, wct, 1, 1, done)
+// wrapOutput wraps the writer a backup is saved to so that the backup is
+// redacted and then encrypted to the recipient, if either is given. The
+// redacting writer is returned as well so its errors can be told apart.
+func (d *SDb) wrapOutput(w io.WriteCloser, service *models.Service, rules *redact.Rules, recipient crypto.Recipient) (io.WriteCloser, *redact.WriteCloser, error) {
+	var err error
+	if recipient != nil {
+		w, err = d.Crypto.NewEncryptToWriteCloser(w, recipient)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	var rw *redact.WriteCloser
+	if rules != nil {
+		rw, err = redact.NewWriteCloser(w, rules, service.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		w = rw
+	}
+	return w, rw, nil
+}
+
+// parseRecipient reads the public key given to --encrypt-to, if any
+func parseRecipient(encryptTo string) (crypto.Recipient, error) {
+	if encryptTo == "" {
+		return nil, nil
+	}
+	recipient, err := crypto.ParseRecipient(encryptTo)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid --encrypt-to key: %s", err)
+	}
+	return recipient, nil
+}
 
-	_, err = io.Copy(wct, resp.Body)
+// StreamBackup downloads, decrypts, and decompresses a backup in one pass,
+// writing it to w. Only chunks that pass authentication are written.
+func (d *SDb) StreamBackup(w io.Writer, job *models.Job, service *models.Service, showProgress bool) error {
+	tempURL, err := d.TempDownloadURL(job.ID, service)
 	if err != nil {
-		done <- false
-		dfw.Close()
 		return err
 	}
-	done <- true
-	return dfw.Close()
+	req, err := http.NewRequest("GET", tempURL.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(d.Settings.Context))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if httpclient.IsError(resp.StatusCode) {
+		return httpclient.ConvertError(resp)
+	}
+	if resp.ContentLength < 0 {
+		return fmt.Errorf("Export succeeded, but Content-Length was not present in the response.")
+	}
+	var file io.WriteCloser = nopWriteCloser{w}
+	// Decompress (leave MongoDB backups in compressed .tgz format)
+	if resp.Header.Get("x-amz-meta-datica-backup-compression") == "gzip" && service.Name != "mongodb" {
+		file, err = d.Compress.NewDecompressWriteCloser(file)
+		if err != nil {
+			return err
+		}
+	}
+	dfw, err := d.Crypto.NewDecryptWriteCloser(file, job.Backup.Key, job.Backup.IV)
+	if err != nil {
+		return err
+	}
+	wct := transfer.NewWriteCloserTransfer(dfw, int(resp.ContentLength))
+	done := make(chan bool, 1)
+	if showProgress {
+		go printTransferStatus(true, wct, 1, 1, done)
+	}
+	n, err := io.Copy(wct, resp.Body)
+	if err == nil && n != resp.ContentLength {
+		err = fmt.Errorf("Downloaded %d bytes but the backup is %d bytes", n, resp.ContentLength)
+	}
+	if err == nil {
+		err = wct.Close()
+	}
+	done <- err == nil
+	if err != nil {
+		return fmt.Errorf("The backup could not be fully downloaded and verified, so the output is incomplete: %s", err)
+	}
+	return nil
+}
+
+// nopWriteCloser lets a writer that should stay open, such as stdout, be used
+// where an io.WriteCloser is needed
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// parseContentRange reads the start and total size from a Content-Range
+// header such as "bytes 100-199/200"
+func parseContentRange(contentRange string) (int64, int64, error) {
+	var start, end, total int64
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total); err != nil {
+		return 0, 0, fmt.Errorf("Invalid Content-Range \"%s\" in the response", contentRange)
+	}
+	return start, total, nil
+}
+
+// resumedTransfer reports the progress of a resumed download including the
+// part downloaded before it was resumed
+type resumedTransfer struct {
+	offset transfer.ByteSize
+	*transfer.WriteCloserTransfer
+}
+
+func (r *resumedTransfer) Transferred() transfer.ByteSize {
+	return r.offset + r.WriteCloserTransfer.Transferred()
 }
 
 func printTransferStatus(isDownload bool, tr transfer.Transfer, partNumber, totalParts int, done <-chan bool) {
@@ -137,7 +522,7 @@ func printTransferStatus(isDownload bool, tr transfer.Transfer, partNumber, tota
 	final := "Download"
 	status := "Finished"
 	if isDownload {
-		logrus.Println("Decrypting and Downloading...")
+		logrus.Println("Downloading...")
 	} else {
 		if totalParts == 0 {
 			logrus.Printf("\nEncrypting and uploading...\n")
@@ -151,14 +536,13 @@ func printTransferStatus(isDownload bool, tr transfer.Transfer, partNumber, tota
 	success := true
 	isDone := false
 loop:
-	for i, l := tr.Transferred(), tr.Length(); i < l; i = tr.Transferred() {
+	for i, l := tr.Transferred(), tr.Length(); l < 0 || i < l; i = tr.Transferred() {
 		select {
 		case success = <-done:
 			isDone = true
 			break loop
 		case <-time.After(time.Millisecond * 100):
-			percent := uint64(i / l * 100)
-			s := fmt.Sprintf("\r\033[m\t%s of %s (%d%%) %s", i, l, percent, action)
+			s := transferStatus(i, l, action)
 			fmt.Print(s)
 			sLen := len(s)
 			// this clears any dangling characters at the end with empty space
@@ -173,9 +557,7 @@ loop:
 		success = <-done
 	}
 
-	total := tr.Transferred()
-	l := tr.Length()
-	s := fmt.Sprintf("\r\033[m\t%s of %s (%d%%) %s", total, l, uint64(total/l*100), action)
+	s := transferStatus(tr.Transferred(), tr.Length(), action)
 	fmt.Print(s)
 	sLen := len(s)
 	// this clears any dangling characters at the end with empty space
@@ -188,3 +570,12 @@ loop:
 	}
 	logrus.Printf("\n%s %s!\n", final, status)
 }
+
+// transferStatus is the progress line for a transfer. A negative length means
+// the total size is not known.
+func transferStatus(transferred, length transfer.ByteSize, action string) string {
+	if length < 0 {
+		return fmt.Sprintf("\r\033[m\t%s %s", transferred, action)
+	}
+	return fmt.Sprintf("\r\033[m\t%s of %s (%d%%) %s", transferred, length, uint64(transferred/length*100), action)
+}
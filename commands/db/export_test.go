@@ -1,6 +1,8 @@
 package db
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -13,6 +15,7 @@ import (
 	"github.com/daticahealth/cli/lib/crypto"
 	"github.com/daticahealth/cli/lib/jobs"
 	"github.com/daticahealth/cli/test"
+	cli "github.com/jault3/mow.cli"
 )
 
 var exportFilePath = "db-export.sql"
@@ -81,7 +84,7 @@ func TestDbExport(t *testing.T) {
 		t.Logf("Data: %+v", data)
 
 		// test
-		err := CmdExport(data.databaseName, data.filePath, data.force, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+		err := CmdExport(settings.Context, data.databaseName, data.filePath, data.force, "", false, "", New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
 
 		// assert
 		if err != nil {
@@ -151,7 +154,7 @@ func TestDbExportUncompressed(t *testing.T) {
 		t.Logf("Data: %+v", data)
 
 		// test
-		err := CmdExport(data.databaseName, data.filePath, data.force, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+		err := CmdExport(settings.Context, data.databaseName, data.filePath, data.force, "", false, "", New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
 
 		// assert
 		if err != nil {
@@ -168,3 +171,152 @@ func TestDbExportUncompressed(t *testing.T) {
 	}
 	os.Remove(exportFilePath)
 }
+
+var dbExportRedactTests = []struct {
+	description    string
+	rules          string
+	backup         string
+	filePath       string
+	allowUnmatched bool
+	expected       string
+	expectErr      bool
+}{
+	{"redacted to a file", "rules:\n  - table: patients\n    column: ssn\n    strategy: \"null\"\n", "COPY public.patients (id, ssn) FROM stdin;\n1\t123-45-6789\n\\.\n", exportFilePath, false, "COPY public.patients (id, ssn) FROM stdin;\n1\t\\N\n\\.\n", false},
+	{"redacted to stdout", "rules:\n  - table: patients\n    column: ssn\n    strategy: constant\n    value: x\n", "COPY public.patients (id, ssn) FROM stdin;\n1\t123-45-6789\n\\.\n", StdoutFilePath, false, "COPY public.patients (id, ssn) FROM stdin;\n1\tx\n\\.\n", false},
+	{"only mongo rules", "rules:\n  - collection: patients\n    field: ssn\n    strategy: \"null\"\n", "", exportFilePath, false, "", true},
+	{"invalid rules", "rules:\n  - table: patients\n    strategy: \"null\"\n", "", exportFilePath, false, "", true},
+	{"custom format dump", "rules:\n  - table: patients\n    column: ssn\n    strategy: \"null\"\n", "PGDMP", exportFilePath, false, "", true},
+	{"unmatched rule", "rules:\n  - table: patients\n    column: ssn\n    strategy: \"null\"\n  - table: patient\n    column: email\n    strategy: \"null\"\n", "COPY public.patients (id, ssn) FROM stdin;\n1\t123-45-6789\n\\.\n", exportFilePath, false, "", true},
+	{"unmatched rule to stdout", "rules:\n  - table: patients\n    column: ssn\n    strategy: \"null\"\n  - table: patient\n    column: email\n    strategy: \"null\"\n", "COPY public.patients (id, ssn) FROM stdin;\n1\t123-45-6789\n\\.\n", StdoutFilePath, false, "", true},
+	{"unmatched rule allowed", "rules:\n  - table: patients\n    column: ssn\n    strategy: \"null\"\n  - table: patient\n    column: email\n    strategy: \"null\"\n", "COPY public.patients (id, ssn) FROM stdin;\n1\t123-45-6789\n\\.\n", exportFilePath, true, "COPY public.patients (id, ssn) FROM stdin;\n1\t\\N\n\\.\n", false},
+}
+
+func TestDbExportRedact(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+
+	var backup []byte
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s","name":"postgresql"}]`, dbID, dbName))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "POST")
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"running","backup":{"key":"0000000000000000000000000000000000000000000000000000000000000000","keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"finished","backup":{"key":"0000000000000000000000000000000000000000000000000000000000000000","keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-url/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/backup"}`, baseURL.String()))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-restore-logs-url/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/logs"}`, baseURL.String()))
+		},
+	)
+	mux.HandleFunc("/logs",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			w.Write([]byte{186, 194, 51, 73, 71, 71, 38, 3, 182, 216, 210, 144, 156, 237, 120, 227, 95, 91, 197, 59, 19}) // gcm encrypted "test"
+		},
+	)
+	mux.HandleFunc("/backup",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			w.Header().Set("x-amz-meta-datica-backup-compression", "gzip")
+			w.Write(backup)
+		},
+	)
+
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+	rulesPath := "db-export-rules.yml"
+	defer os.Remove(rulesPath)
+	partialPath := fmt.Sprintf("%s.%s.partial", exportFilePath, dbJobID)
+	for _, data := range dbExportRedactTests {
+		t.Logf("Data: %+v", data)
+		buf.Reset()
+		backup = encryptTestBackup(t, gzipTestData([]byte(data.backup)))
+		ioutil.WriteFile(rulesPath, []byte(data.rules), 0600)
+
+		// test
+		err := CmdExport(settings.Context, dbName, data.filePath, true, rulesPath, data.allowUnmatched, "", New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if data.expectErr {
+			if _, statErr := os.Stat(exportFilePath); !os.IsNotExist(statErr) {
+				t.Errorf("Expected no export file to be written")
+			}
+			if _, statErr := os.Stat(partialPath); !os.IsNotExist(statErr) {
+				t.Errorf("Expected the partial file to be removed")
+			}
+			continue
+		}
+		actual := buf.String()
+		if data.filePath != StdoutFilePath {
+			b, _ := ioutil.ReadFile(data.filePath)
+			actual = string(b)
+		}
+		if actual != data.expected {
+			t.Errorf("Unexpected redacted export. Expected: %q, actual: %q", data.expected, actual)
+		}
+	}
+	os.Remove(exportFilePath)
+	os.Remove(partialPath)
+}
+
+var exportSubCmdTests = []struct {
+	args      []string
+	expectErr bool
+}{
+	{[]string{dbName, exportFilePath}, false},
+	{[]string{dbName, exportFilePath, "-f", "--redact", "rules.yml", "--allow-unmatched", "--encrypt-to", "key.pem"}, false},
+	{[]string{dbName, exportFilePath, "--allow-unmatched", "--redact", "rules.yml"}, false},
+	{[]string{dbName, exportFilePath, "--unknown"}, true},
+	{[]string{dbName}, true},
+}
+
+func TestExportSubCmdOptions(t *testing.T) {
+	settings := test.GetSettings("")
+	for _, data := range exportSubCmdTests {
+		t.Logf("Data: %+v", data)
+		ran := false
+		app := cli.App("datica", "")
+		app.ErrorHandling = flag.ContinueOnError
+		app.Command(ExportSubCmd.Name, ExportSubCmd.ShortHelp, func(cmd *cli.Cmd) {
+			ExportSubCmd.CmdFunc(settings)(cmd)
+			// only the option parsing is tested, not the export itself
+			cmd.Action = func() { ran = true }
+		})
+
+		// test
+		err := app.Run(append([]string{"datica", ExportSubCmd.Name}, data.args...))
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if ran == data.expectErr {
+			t.Errorf("Expected the command to run: %t, actual: %t", !data.expectErr, ran)
+		}
+	}
+}
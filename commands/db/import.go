@@ -2,6 +2,7 @@ package db
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
@@ -11,8 +12,10 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -24,15 +27,31 @@ import (
 	"github.com/daticahealth/cli/models"
 )
 
-func CmdImport(databaseName, filePath, mongoCollection, mongoDatabase string, skipBackup bool, id IDb, ip prompts.IPrompts, is services.IServices, ij jobs.IJobs) error {
+// importChunkSize is the size of each part of a multipart import upload for
+// files up to 1TB
+var importChunkSize = transfer.MB * 100
+
+// importRetryWait is how long to wait before retrying a part that failed to
+// upload
+var importRetryWait = time.Second * 15
+
+func CmdImport(ctx context.Context, databaseName, filePath, mongoCollection, mongoDatabase string, skipBackup, resume bool, concurrency int, id IDb, ip prompts.IPrompts, is services.IServices, ij jobs.IJobs) error {
 	singleUploadMode := false
 	versionInfo, err := id.RetrievePodApiVersion()
 	if versionInfo.Version < "4.1.0" {
 		singleUploadMode = true
 	}
 
-	if _, err = os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("A file does not exist at path '%s'", filePath)
+	if concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if filePath == StdinFilePath && skipBackup && !resume {
+		return fmt.Errorf("Skipping the backup cannot be confirmed while importing from stdin. Remove --skip-backup and try again")
+	}
+	if filePath != StdinFilePath {
+		if _, err = os.Stat(filePath); os.IsNotExist(err) {
+			return fmt.Errorf("A file does not exist at path '%s'", filePath)
+		}
 	}
 	service, err := is.RetrieveByLabel(databaseName)
 	if err != nil {
@@ -44,62 +63,80 @@ func CmdImport(databaseName, filePath, mongoCollection, mongoDatabase string, sk
 	if service.Name == "postgresql" {
 		fmt.Println("WARNING: Import cannot DROP DATABASE \"catalyzeDB\". Ensure your import individually removes any necessary \"catalyzeDB\" objects, or import only into newly created postgres services where the \"catalyzeDB\" database is already empty.")
 	}
-	key := make([]byte, crypto.KeySize)
-	iv := make([]byte, crypto.IVSize)
-	rand.Read(key)
-	rand.Read(iv)
-	file, err := os.Open(filePath)
+	input, err := openImportInput(filePath, service.Name)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	fi, err := file.Stat()
-	if err != nil {
-		return err
+	defer input.Close()
+	if input.Size < 0 && singleUploadMode {
+		return fmt.Errorf("Importing from stdin or a compressed file is not supported by this environment. Decompress the file and import it instead")
 	}
-	encryptFileReader, err := id.NewEncryptReader(file, key, iv)
-	if err != nil {
-		return err
-	}
-	uploadSize := encryptFileReader.CalculateTotalSize(int(fi.Size()))
-	fiveGB := transfer.GB * 5
-	if singleUploadMode && transfer.ByteSize(uploadSize) > fiveGB {
-		return fmt.Errorf("The encrypted size of %s exceeds the maximum upload size of %s", filePath, fiveGB)
-	}
-	fiveTB := transfer.TB * 5
-	if transfer.ByteSize(uploadSize) > fiveTB {
-		return fmt.Errorf("The encrypted size of %s exceeds the maximum upload size of %s", filePath, fiveTB)
-	}
-	rt := transfer.NewReaderTransfer(encryptFileReader, uploadSize)
-	if !skipBackup {
-		logrus.Printf("Backing up \"%s\" before performing the import", databaseName)
-		job, err := id.Backup(service)
+	var state *ImportState
+	key := make([]byte, crypto.KeySize)
+	iv := make([]byte, crypto.IVSize)
+	if resume {
+		if singleUploadMode {
+			return fmt.Errorf("Imports into %s cannot be resumed. Run the import again without --resume", databaseName)
+		}
+		if filePath == StdinFilePath {
+			return fmt.Errorf("Imports from stdin cannot be resumed. Run the import again without --resume")
+		}
+		state, err = LoadImportState(service.ID, filePath)
 		if err != nil {
 			return err
 		}
-		logrus.Printf("Backup started (job ID = %s)", job.ID)
-
-		// all because logrus treats print, println, and printf the same
-		logrus.Println("Polling until backup finishes.")
-		if job.IsSnapshotBackup != nil && *job.IsSnapshotBackup {
-			logrus.Printf("This is a snapshot backup, it may be a while before this backup shows up in the \"datica db list %s\" command.", databaseName)
-			err = ij.WaitToAppear(job.ID, service.ID)
+		if state == nil {
+			return fmt.Errorf("No interrupted import of '%s' into %s was found. Run the import again without --resume", filePath, databaseName)
+		}
+		if !state.Matches(input.Info) {
+			return fmt.Errorf("'%s' has changed since the interrupted import was started. Run the import again without --resume", filePath)
+		}
+		key, iv, err = state.EncryptionKey()
+		if err != nil {
+			return err
+		}
+		mongoCollection = state.MongoCollection
+		mongoDatabase = state.MongoDatabase
+		// the database was already backed up when the import was started
+		skipBackup = true
+	} else {
+		rand.Read(key)
+		rand.Read(iv)
+		if filePath == StdinFilePath {
+			// stdin cannot be read again, so its progress is never saved
+			state = &ImportState{Parts: []UploadedPart{}}
+		} else if !singleUploadMode {
+			state, err = NewImportState(service.ID, filePath, input.Info, key, iv, mongoCollection, mongoDatabase)
 			if err != nil {
 				return err
 			}
 		}
-		status, err := ij.PollTillFinished(job.ID, service.ID)
-		if err != nil {
-			return err
+	}
+	encryptFileReader, err := id.NewEncryptReader(input, key, iv)
+	if err != nil {
+		return err
+	}
+	// streams of unknown length are encrypted and uploaded a part at a time
+	// until they run out
+	uploadSize := -1
+	if input.Size >= 0 {
+		uploadSize = encryptFileReader.CalculateTotalSize(int(input.Size))
+		fiveGB := transfer.GB * 5
+		if singleUploadMode && transfer.ByteSize(uploadSize) > fiveGB {
+			return fmt.Errorf("The encrypted size of %s exceeds the maximum upload size of %s", filePath, fiveGB)
 		}
-		job.Status = status
-		logrus.Printf("Ended in status '%s'", job.Status)
-		err = id.DumpLogs("backup", job, service)
-		if err != nil {
-			return err
+		fiveTB := transfer.TB * 5
+		if transfer.ByteSize(uploadSize) > fiveTB {
+			return fmt.Errorf("The encrypted size of %s exceeds the maximum upload size of %s", filePath, fiveTB)
 		}
-		if job.Status != "finished" {
-			return fmt.Errorf("Job finished with invalid status %s", job.Status)
+	}
+	rt := transfer.NewReaderTransfer(encryptFileReader, uploadSize)
+	if resume {
+		logrus.Printf("Resuming the import of '%s' into %s after %d uploaded parts", filePath, databaseName, len(state.Parts))
+	} else if !skipBackup {
+		logrus.Printf("Backing up \"%s\" before performing the import", databaseName)
+		if _, err = backupAndWait(ctx, databaseName, service, id, ij); err != nil {
+			return err
 		}
 	} else {
 		err := ip.YesNo("", "Are you sure you want to import data into your database without backing it up first? (y/n) ")
@@ -108,20 +145,71 @@ func CmdImport(databaseName, filePath, mongoCollection, mongoDatabase string, sk
 		}
 	}
 	logrus.Printf("Importing '%s' into %s (ID = %s)", filePath, databaseName, service.ID)
-	job, err := id.Import(rt, key, iv, mongoCollection, mongoDatabase, service, singleUploadMode)
+	job, err := id.Import(rt, key, iv, mongoCollection, mongoDatabase, service, singleUploadMode, state, concurrency)
 	if err != nil {
+		if state != nil && state.path != "" && state.UploadInfo.UploadID != "" {
+			logrus.Printf("The upload can be continued by running the same command with --resume")
+		}
 		return err
 	}
+	if state != nil {
+		if err = state.Remove(); err != nil {
+			logrus.Warnf("Failed to remove the saved state of the import: %s", err)
+		}
+	}
+	return waitForImport(ctx, job, service, id, ij)
+}
+
+// backupAndWait backs up a database, waits for the backup to finish, and
+// prints its logs
+func backupAndWait(ctx context.Context, databaseName string, service *models.Service, id IDb, ij jobs.IJobs) (*models.Job, error) {
+	job, err := id.Backup(service)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Printf("Backup started (job ID = %s)", job.ID)
+
+	// all because logrus treats print, println, and printf the same
+	logrus.Println("Polling until backup finishes.")
+	if job.IsSnapshotBackup != nil && *job.IsSnapshotBackup {
+		logrus.Printf("This is a snapshot backup, it may be a while before this backup shows up in the \"datica db list %s\" command.", databaseName)
+		err = ij.WaitToAppear(ctx, job.ID, service.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	tail := id.TailLogs(ctx, "backup", job, service)
+	defer tail.Stop()
+	status, err := ij.PollTillFinished(ctx, job.ID, service.ID)
+	if err != nil {
+		return nil, err
+	}
+	job.Status = status
+	logrus.Printf("Ended in status '%s'", job.Status)
+	err = tail.Finish()
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != "finished" {
+		return nil, fmt.Errorf("Job finished with invalid status %s", job.Status)
+	}
+	return job, nil
+}
+
+// waitForImport polls an import job until it finishes and prints its logs
+func waitForImport(ctx context.Context, job *models.Job, service *models.Service, id IDb, ij jobs.IJobs) error {
 	// all because logrus treats print, println, and printf the same
 	logrus.StandardLogger().Out.Write([]byte(fmt.Sprintf("Processing import (job ID = %s).", job.ID)))
 
-	status, err := ij.PollTillFinished(job.ID, service.ID)
+	tail := id.TailLogs(ctx, "restore", job, service)
+	defer tail.Stop()
+	status, err := ij.PollTillFinished(ctx, job.ID, service.ID)
 	if err != nil {
 		return err
 	}
 	job.Status = status
 	logrus.Printf("\nImport complete (end status = '%s')", job.Status)
-	err = id.DumpLogs("restore", job, service)
+	err = tail.Finish()
 	if err != nil {
 		return err
 	}
@@ -140,7 +228,11 @@ func CmdImport(databaseName, filePath, mongoCollection, mongoDatabase string, sk
 // PostgreSQL and MySQL, this should be a single `.sql` file. For Mongo, this
 // should be a single tar'ed, gzipped archive (`.tar.gz`) of the database dump
 // that you want to import.
-func (d *SDb) Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollection, mongoDatabase string, service *models.Service, singleUploadMode bool) (*models.Job, error) {
+//
+// When uploading in parts, up to concurrency parts are uploaded at once and
+// the progress is saved to state after every part. A state with parts already
+// uploaded continues the upload with the parts that are missing.
+func (d *SDb) Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollection, mongoDatabase string, service *models.Service, singleUploadMode bool, state *ImportState, concurrency int) (*models.Job, error) {
 	options := map[string]string{}
 	if mongoCollection != "" {
 		options["databaseCollection"] = mongoCollection
@@ -165,7 +257,7 @@ func (d *SDb) Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollectio
 		req.ContentLength = int64(rt.Length())
 		done := make(chan bool)
 		go printTransferStatus(false, rt, 0, 0, done)
-		uploadResp, err := http.DefaultClient.Do(req)
+		uploadResp, err := http.DefaultClient.Do(req.WithContext(d.Settings.Context))
 		if err != nil {
 			done <- false
 			return nil, err
@@ -179,89 +271,48 @@ func (d *SDb) Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollectio
 		}
 		uploadFilename = strings.TrimLeft(u.Path, "/")
 		done <- true
+	} else if state.Completed {
+		uploadFilename = state.UploadInfo.FileName
 	} else {
-		var uploadInfo *models.MultipartUploadInfo
-		var err error
-		for attempt := 0; attempt < 5; attempt++ {
-			uploadInfo, err = d.InitiateMultiPartUpload(service)
-			if err == nil {
-				break
-			}
-		}
-		if err != nil {
-			return nil, fmt.Errorf("Failed to initiate upload - %s", err)
-		}
-
-		chunkSize := transfer.MB * 100
-		if rt.Length() > transfer.TB {
-			chunkSize = transfer.MB * 500
-		}
-
-		numChunks := int(math.Ceil(float64(rt.Length() / chunkSize)))
-		parts := []map[string]interface{}{}
-		for i := 1; i <= numChunks; i++ {
-			tmpURL, err := d.TempUploadURL(service, uploadInfo.FileName, strconv.Itoa(i), uploadInfo.UploadID)
-			if err != nil {
-				return nil, err
-			}
-
-			if i == numChunks {
-				chunkSize = (transfer.ByteSize)(int(rt.Length()) - int(rt.Transferred()))
-			}
-
-			readBuffer := make([]byte, int(chunkSize))
-			bytesRead, err := rt.Read(readBuffer)
-			if err != nil {
-				return nil, err
-			}
-			if bytesRead < int(chunkSize) {
-				return nil, fmt.Errorf("Failed to read from file - attempted to read %v but read %v. Import failed.", int(chunkSize), bytesRead)
-			}
-
-			var uploadResp *http.Response
+		if state.UploadInfo.UploadID == "" {
+			var uploadInfo *models.MultipartUploadInfo
+			var err error
 			for attempt := 0; attempt < 5; attempt++ {
-				tempReader := bytes.NewReader(readBuffer)
-				chunkRT := transfer.NewReaderTransfer(io.LimitReader(tempReader, int64(chunkSize)), int(chunkSize))
-
-				done := make(chan bool)
-				go printTransferStatus(false, chunkRT, i, numChunks, done)
-
-				req, err := http.NewRequest("PUT", tmpURL.URL, chunkRT)
-				req.ContentLength = int64(chunkRT.Length())
-
-				uploadResp, err = http.DefaultClient.Do(req)
-				if err == nil && uploadResp.StatusCode == 200 {
-					done <- true
+				uploadInfo, err = d.InitiateMultiPartUpload(service)
+				if err == nil {
 					break
 				}
-				if uploadResp == nil {
-					logrus.Printf("\nChunk upload %s failed.\nErr: %s\nRetrying...", strconv.Itoa(i), fmt.Errorf("No response from server"))
-				} else {
-					logrus.Printf("\nChunk upload %s failed.\nResponse code: %s\nErr: %s\nRetrying...", strconv.Itoa(i), strconv.Itoa(uploadResp.StatusCode), err)
-				}
-				done <- false
-				time.Sleep(time.Second * 15)
 			}
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("Failed to initiate upload - %s", err)
 			}
-			if uploadResp == nil {
-				return nil, fmt.Errorf("Failed to upload import file - %s", fmt.Errorf("No response from server"))
-			} else {
-				defer uploadResp.Body.Close()
-				if uploadResp.StatusCode != 200 {
-					b, err := ioutil.ReadAll(uploadResp.Body)
-					return nil, fmt.Errorf("Failed to upload import file - received status code %d %s %s", uploadResp.StatusCode, string(b), err)
-				}
-				etag := uploadResp.Header.Get("ETag")
-				parts = append(parts, map[string]interface{}{
-					"ETag":       etag,
-					"PartNumber": i,
-				})
+			state.UploadInfo = *uploadInfo
+			if err = state.Save(); err != nil {
+				return nil, err
 			}
-			uploadFilename = uploadInfo.FileName
 		}
+		uploadInfo := &state.UploadInfo
 
+		chunkSize := importChunkSize
+		if rt.Length() > transfer.TB {
+			chunkSize = transfer.MB * 500
+		}
+		if err := d.uploadParts(rt, service, state, int(chunkSize), concurrency); err != nil {
+			return nil, err
+		}
+		uploadFilename = uploadInfo.FileName
+
+		sort.Slice(state.Parts, func(i, j int) bool {
+			return state.Parts[i].PartNumber < state.Parts[j].PartNumber
+		})
+		parts := []map[string]interface{}{}
+		for _, part := range state.Parts {
+			parts = append(parts, map[string]interface{}{
+				"ETag":       part.ETag,
+				"PartNumber": part.PartNumber,
+			})
+		}
+		var err error
 		for attempt := 0; attempt < 5; attempt++ {
 			_, err = d.CompleteMultiPartUpload(service, uploadInfo.FileName, uploadInfo.UploadID, parts)
 			if err == nil {
@@ -271,6 +322,10 @@ func (d *SDb) Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollectio
 		if err != nil {
 			return nil, fmt.Errorf("Failed to complete upload - %s", err)
 		}
+		state.Completed = true
+		if err = state.Save(); err != nil {
+			return nil, err
+		}
 	}
 
 	importParams := map[string]interface{}{}
@@ -293,7 +348,7 @@ func (d *SDb) Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollectio
 		return nil, err
 	}
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Post(b, fmt.Sprintf("%s%s/environments/%s/services/%s/import", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Post(d.Settings.Context, b, fmt.Sprintf("%s%s/environments/%s/services/%s/import", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -305,9 +360,223 @@ func (d *SDb) Import(rt *transfer.ReaderTransfer, key, iv []byte, mongoCollectio
 	return &job, nil
 }
 
+// importPart is a single encrypted part of the file waiting to be uploaded
+type importPart struct {
+	number int
+	data   []byte
+}
+
+// maxImportParts is the most parts a multipart upload can have
+const maxImportParts = 10000
+
+// uploadParts uploads every part of the encrypted file that is not yet in
+// state using a pool of concurrency workers. The encrypting reader can only be
+// read in order, so parts are read one after another and handed to the next
+// free worker. At most concurrency+1 parts are held in memory at once. When
+// the length of rt is not known, parts are read until it runs out.
+func (d *SDb) uploadParts(rt *transfer.ReaderTransfer, service *models.Service, state *ImportState, chunkSize, concurrency int) error {
+	length := int(rt.Length())
+	known := length >= 0
+	numChunks := maxImportParts
+	if known {
+		numChunks = int(math.Ceil(float64(length) / float64(chunkSize)))
+	}
+	partSize := func(number int) int {
+		if known && number == numChunks {
+			return length - (numChunks-1)*chunkSize
+		}
+		return chunkSize
+	}
+	uploaded := map[int]bool{}
+	for _, part := range state.Parts {
+		uploaded[part.PartNumber] = true
+	}
+	remaining := -1
+	if known {
+		remaining = 0
+		for i := 1; i <= numChunks; i++ {
+			if !uploaded[i] {
+				remaining += partSize(i)
+			}
+		}
+		if remaining == 0 {
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(d.Settings.Context)
+	defer cancel()
+	progress := transfer.NewReaderTransfer(nil, remaining)
+	buffers := make(chan []byte, concurrency+1)
+	for i := 0; i < concurrency+1; i++ {
+		buffers <- nil
+	}
+	parts := make(chan importPart)
+	errs := make(chan error, concurrency)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for part := range parts {
+				etag, err := d.uploadPart(ctx, service, &state.UploadInfo, part.number, part.data, progress)
+				buffers <- part.data
+				if err == nil {
+					lock.Lock()
+					state.Parts = append(state.Parts, UploadedPart{
+						PartNumber: part.number,
+						ETag:       etag,
+					})
+					err = state.Save()
+					lock.Unlock()
+				}
+				if err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+	if concurrency > 1 {
+		if known {
+			logrus.Printf("\nEncrypting and uploading %d parts, %d at a time...", numChunks-len(uploaded), concurrency)
+		} else {
+			logrus.Printf("\nEncrypting and uploading %s parts, %d at a time...", transfer.ByteSize(chunkSize), concurrency)
+		}
+	}
+	done := make(chan bool)
+	go printTransferStatus(false, progress, 0, 0, done)
+	var err error
+read:
+	for i := 1; ; i++ {
+		if i > numChunks {
+			if !known {
+				err = fmt.Errorf("The import exceeds the maximum upload size of %s", transfer.ByteSize(maxImportParts*chunkSize))
+			}
+			break
+		}
+		size := partSize(i)
+		// the file is encrypted again with the same key and IV, so skipping the
+		// parts already uploaded keeps the reader at the start of the next part
+		if uploaded[i] {
+			n, copyErr := io.CopyN(ioutil.Discard, rt, int64(size))
+			if copyErr == io.EOF && !known && n > 0 {
+				// the last part was already uploaded
+				break
+			} else if copyErr != nil {
+				err = fmt.Errorf("Failed to read from file - %s. Import failed.", copyErr)
+				break
+			}
+			continue
+		}
+		var buf []byte
+		select {
+		case buf = <-buffers:
+		case <-ctx.Done():
+			break read
+		}
+		if buf == nil {
+			buf = make([]byte, chunkSize)
+		}
+		bytesRead, readErr := io.ReadFull(rt, buf[:size])
+		last := false
+		if !known && (readErr == io.EOF || readErr == io.ErrUnexpectedEOF) {
+			if bytesRead == 0 && i > 1 {
+				// the stream ended exactly at the end of the previous part
+				buffers <- buf
+				break
+			}
+			last = true
+		} else if readErr != nil {
+			if known {
+				err = fmt.Errorf("Failed to read from file - attempted to read %v but read %v. Import failed.", size, bytesRead)
+			} else {
+				err = fmt.Errorf("Failed to read the import - %s. Import failed.", readErr)
+			}
+			break
+		}
+		select {
+		case parts <- importPart{number: i, data: buf[:bytesRead]}:
+		case <-ctx.Done():
+			break read
+		}
+		if last {
+			break
+		}
+	}
+	close(parts)
+	wg.Wait()
+	if err == nil {
+		select {
+		case err = <-errs:
+		default:
+			err = d.Settings.Context.Err()
+		}
+	}
+	done <- err == nil
+	return err
+}
+
+// uploadPart uploads a single part, retrying up to 5 times, and returns its
+// ETag. Every attempt counts towards progress, less whatever a failed attempt
+// had sent.
+func (d *SDb) uploadPart(ctx context.Context, service *models.Service, uploadInfo *models.MultipartUploadInfo, number int, data []byte, progress *transfer.ReaderTransfer) (string, error) {
+	tmpURL, err := d.TempUploadURL(service, uploadInfo.FileName, strconv.Itoa(number), uploadInfo.UploadID)
+	if err != nil {
+		return "", err
+	}
+	var uploadResp *http.Response
+	for attempt := 0; attempt < 5; attempt++ {
+		partRT := progress.Part(bytes.NewReader(data), len(data))
+		req, err := http.NewRequest("PUT", tmpURL.URL, partRT)
+		if err != nil {
+			return "", err
+		}
+		req.ContentLength = int64(len(data))
+
+		uploadResp, err = http.DefaultClient.Do(req.WithContext(ctx))
+		if err == nil && uploadResp.StatusCode == 200 {
+			uploadResp.Body.Close()
+			return uploadResp.Header.Get("ETag"), nil
+		}
+		partRT.Reset()
+		if ctx.Err() != nil {
+			break
+		}
+		if uploadResp == nil {
+			logrus.Printf("\nChunk upload %d failed.\nErr: %s\nRetrying...", number, err)
+		} else {
+			logrus.Printf("\nChunk upload %d failed.\nResponse code: %d\nRetrying...", number, uploadResp.StatusCode)
+			if attempt < 4 {
+				uploadResp.Body.Close()
+			}
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(importRetryWait):
+		}
+	}
+	if ctx.Err() != nil {
+		// interrupted, so the upload can be resumed
+		if uploadResp != nil {
+			uploadResp.Body.Close()
+		}
+		return "", fmt.Errorf("Failed to upload part %d of the import file - %s", number, ctx.Err())
+	}
+	if uploadResp == nil {
+		return "", fmt.Errorf("Failed to upload import file - %s", fmt.Errorf("No response from server"))
+	}
+	defer uploadResp.Body.Close()
+	b, err := ioutil.ReadAll(uploadResp.Body)
+	return "", fmt.Errorf("Failed to upload import file - received status code %d %s %s", uploadResp.StatusCode, string(b), err)
+}
+
 func (d *SDb) InitiateMultiPartUpload(service *models.Service) (*models.MultipartUploadInfo, error) {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Post(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/initiate-multipart-upload", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Post(d.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/initiate-multipart-upload", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -325,7 +594,7 @@ func (d *SDb) CompleteMultiPartUpload(service *models.Service, fileName string,
 	if err != nil {
 		return nil, err
 	}
-	resp, statusCode, err := d.Settings.HTTPManager.Post(body, fmt.Sprintf("%s%s/environments/%s/services/%s/complete-multipart-upload?fileName=%s&uploadId=%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, fileName, uploadID), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Post(d.Settings.Context, body, fmt.Sprintf("%s%s/environments/%s/services/%s/complete-multipart-upload?fileName=%s&uploadId=%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, fileName, uploadID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -339,7 +608,7 @@ func (d *SDb) CompleteMultiPartUpload(service *models.Service, fileName string,
 
 func (d *SDb) TempUploadURL(service *models.Service, fileName string, partNumber string, uploadID string) (*models.TempURL, error) {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/multipart-upload-url?fileName=%s&partNumber=%s&uploadId=%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, fileName, partNumber, uploadID), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Get(d.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/multipart-upload-url?fileName=%s&partNumber=%s&uploadId=%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, fileName, partNumber, uploadID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -353,7 +622,7 @@ func (d *SDb) TempUploadURL(service *models.Service, fileName string, partNumber
 
 func (d *SDb) TempUploadURLSingleUploadMode(service *models.Service) (*models.TempURL, error) {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/restore-url", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Get(d.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/restore-url", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -367,7 +636,7 @@ func (d *SDb) TempUploadURLSingleUploadMode(service *models.Service) (*models.Te
 
 func (d *SDb) RetrievePodApiVersion() (*models.VersionInfo, error) {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/healthcheck", d.Settings.PaasHost, d.Settings.PaasHostVersion), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Get(d.Settings.Context, nil, fmt.Sprintf("%s%s/healthcheck", d.Settings.PaasHost, d.Settings.PaasHostVersion), headers)
 	if err != nil {
 		return nil, err
 	}
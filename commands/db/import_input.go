@@ -0,0 +1,135 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// StdinFilePath is the FILEPATH that imports data read from stdin
+const StdinFilePath = "-"
+
+// importInput is the data to import. Size is -1 when the length of the data
+// is not known ahead of time, such as for stdin and compressed files.
+type importInput struct {
+	io.Reader
+	Size int64
+	Info os.FileInfo
+
+	closers []io.Closer
+}
+
+// Close closes the input along with any file or decompressor behind it
+func (i *importInput) Close() error {
+	var err error
+	for j := len(i.closers) - 1; j >= 0; j-- {
+		if cerr := i.closers[j].Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// openImportInput opens the data to import from filePath. Files ending in .gz
+// or .zst are decompressed on the fly, except for mongo imports which are
+// uploaded as the compressed archive.
+func openImportInput(filePath string, service string) (*importInput, error) {
+	if filePath == StdinFilePath {
+		return &importInput{Reader: os.Stdin, Size: -1}, nil
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	input := &importInput{Reader: file, Size: fi.Size(), Info: fi, closers: []io.Closer{file}}
+	switch {
+	case service == "mongodb":
+		if strings.HasSuffix(filePath, ".zst") {
+			input.Close()
+			return nil, fmt.Errorf("Mongo imports must be a tar'ed, gzipped archive (.tar.gz)")
+		}
+	case strings.HasSuffix(filePath, ".gz"):
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			input.Close()
+			return nil, fmt.Errorf("Failed to decompress %s: %s", filePath, err)
+		}
+		input.Reader = gz
+		input.Size = -1
+		input.closers = append(input.closers, gz)
+	case strings.HasSuffix(filePath, ".zst"):
+		zst, err := newZstdReader(file)
+		if err != nil {
+			input.Close()
+			return nil, err
+		}
+		input.Reader = zst
+		input.Size = -1
+		input.closers = append(input.closers, zst)
+	}
+	return input, nil
+}
+
+// zstdReader decompresses zstd data with the zstd command
+type zstdReader struct {
+	io.ReadCloser
+	cmd     *exec.Cmd
+	stderr  bytes.Buffer
+	waited  bool
+	waitErr error
+}
+
+func newZstdReader(src io.Reader) (*zstdReader, error) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		return nil, fmt.Errorf("The zstd command must be installed to import .zst files")
+	}
+	z := &zstdReader{cmd: exec.Command("zstd", "-dc")}
+	z.cmd.Stdin = src
+	z.cmd.Stderr = &z.stderr
+	out, err := z.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	z.ReadCloser = out
+	if err = z.cmd.Start(); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// Read reports a failure to decompress once all of the output has been read,
+// so corrupt input fails the import instead of being silently cut short
+func (z *zstdReader) Read(p []byte) (int, error) {
+	n, err := z.ReadCloser.Read(p)
+	if err == io.EOF {
+		if !z.waited {
+			z.waited = true
+			if werr := z.cmd.Wait(); werr != nil {
+				z.waitErr = fmt.Errorf("Failed to decompress: %s %s", werr, strings.TrimSpace(z.stderr.String()))
+			}
+		}
+		if z.waitErr != nil {
+			return n, z.waitErr
+		}
+	}
+	return n, err
+}
+
+func (z *zstdReader) Close() error {
+	z.ReadCloser.Close()
+	if !z.waited {
+		z.waited = true
+		z.cmd.Process.Kill()
+		z.cmd.Wait()
+	}
+	return nil
+}
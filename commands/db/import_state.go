@@ -0,0 +1,151 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/daticahealth/cli/config"
+	"github.com/daticahealth/cli/models"
+)
+
+// ImportState is the progress of a multipart import upload. It is saved after
+// every uploaded part so an interrupted import can be continued with
+// "datica db import --resume". The state holds the encryption key, so it is
+// only readable by the current user.
+type ImportState struct {
+	ServiceID       string                     `json:"serviceId"`
+	FilePath        string                     `json:"filePath"`
+	FileSize        int64                      `json:"fileSize"`
+	FileModTime     time.Time                  `json:"fileModTime"`
+	Key             string                     `json:"key"`
+	IV              string                     `json:"iv"`
+	MongoCollection string                     `json:"mongoCollection,omitempty"`
+	MongoDatabase   string                     `json:"mongoDatabase,omitempty"`
+	UploadInfo      models.MultipartUploadInfo `json:"uploadInfo"`
+	Parts           []UploadedPart             `json:"parts"`
+	Completed       bool                       `json:"completed"`
+
+	path string
+}
+
+// UploadedPart is a single part of a multipart upload that has been uploaded
+type UploadedPart struct {
+	PartNumber int    `json:"PartNumber"`
+	ETag       string `json:"ETag"`
+}
+
+// importStateDir is where the state of interrupted imports is kept, next to the
+// settings file
+func importStateDir() string {
+	return config.SettingsFile + ".imports"
+}
+
+// importStatePath is the state file for importing the given file into a
+// service. There is at most one interrupted import per file and service.
+func importStatePath(serviceID, filePath string) (string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(serviceID + "\n" + absPath))
+	return filepath.Join(importStateDir(), hex.EncodeToString(sum[:16])+".json"), nil
+}
+
+// NewImportState starts tracking a new import of the given file, replacing the
+// state of any earlier interrupted import of the same file into the service.
+func NewImportState(serviceID, filePath string, fi os.FileInfo, key, iv []byte, mongoCollection, mongoDatabase string) (*ImportState, error) {
+	path, err := importStatePath(serviceID, filePath)
+	if err != nil {
+		return nil, err
+	}
+	absPath, _ := filepath.Abs(filePath)
+	return &ImportState{
+		ServiceID:       serviceID,
+		FilePath:        absPath,
+		FileSize:        fi.Size(),
+		FileModTime:     fi.ModTime(),
+		Key:             hex.EncodeToString(key),
+		IV:              hex.EncodeToString(iv),
+		MongoCollection: mongoCollection,
+		MongoDatabase:   mongoDatabase,
+		Parts:           []UploadedPart{},
+		path:            path,
+	}, nil
+}
+
+// LoadImportState reads the state of an interrupted import of the given file
+// into a service. If there is no interrupted import, nil is returned.
+func LoadImportState(serviceID, filePath string) (*ImportState, error) {
+	path, err := importStatePath(serviceID, filePath)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var state ImportState
+	if err = json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("Invalid or corrupt import state. Please remove %s and start the import again", path)
+	}
+	state.path = path
+	return &state, nil
+}
+
+// Matches reports whether the file is unchanged since the import was started
+func (s *ImportState) Matches(fi os.FileInfo) bool {
+	return s.FileSize == fi.Size() && s.FileModTime.Equal(fi.ModTime())
+}
+
+// EncryptionKey decodes the key and IV the file is encrypted with
+func (s *ImportState) EncryptionKey() ([]byte, []byte, error) {
+	key, err := hex.DecodeString(s.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid or corrupt import state. Please remove %s and start the import again", s.path)
+	}
+	iv, err := hex.DecodeString(s.IV)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid or corrupt import state. Please remove %s and start the import again", s.path)
+	}
+	return key, iv, nil
+}
+
+// Save writes the state so only the current user can read it. A state without
+// a path, such as for an import from stdin, is never saved.
+func (s *ImportState) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err = ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Remove deletes the saved state once the import no longer needs resuming
+func (s *ImportState) Remove() error {
+	if s.path == "" {
+		return nil
+	}
+	err := os.Remove(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
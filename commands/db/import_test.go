@@ -1,13 +1,23 @@
 package db
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"os/exec"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/config"
 	"github.com/daticahealth/cli/lib/compress"
 	"github.com/daticahealth/cli/lib/crypto"
 	"github.com/daticahealth/cli/lib/jobs"
@@ -33,7 +43,25 @@ var dbImportTests = []struct {
 	{"invalid-svc", importFilePath, "", "", false, true},
 }
 
+// useTempSettingsFile points the settings file at a temporary file so the
+// saved state of interrupted imports stays out of the home directory
+func useTempSettingsFile(t *testing.T) func() {
+	f, err := ioutil.TempFile("", "datica-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	oldSettingsFile := config.SettingsFile
+	config.SettingsFile = f.Name()
+	return func() {
+		config.SettingsFile = oldSettingsFile
+		os.Remove(f.Name())
+		os.RemoveAll(f.Name() + ".imports")
+	}
+}
+
 func TestDbImport(t *testing.T) {
+	defer useTempSettingsFile(t)()
 	ioutil.WriteFile(importFilePath, []byte("select 1;"), 0644)
 	mux, server, baseURL := test.Setup()
 	defer test.Teardown(server)
@@ -127,7 +155,7 @@ func TestDbImport(t *testing.T) {
 		backedUp = false
 
 		// test
-		err := CmdImport(data.databaseName, data.filePath, data.collection, data.database, data.skipBackup, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+		err := CmdImport(settings.Context, data.databaseName, data.filePath, data.collection, data.database, data.skipBackup, false, 1, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
 
 		// assert
 		if err != nil {
@@ -146,6 +174,7 @@ func TestDbImport(t *testing.T) {
 }
 
 func TestDbImportOverFiveGB(t *testing.T) {
+	defer useTempSettingsFile(t)()
 	requestData := make([]byte, 5368709130)
 	ioutil.WriteFile(importFilePath, requestData, 0644)
 	mux, server, baseURL := test.Setup()
@@ -240,7 +269,7 @@ func TestDbImportOverFiveGB(t *testing.T) {
 		backedUp = false
 
 		// test
-		err := CmdImport(data.databaseName, data.filePath, data.collection, data.database, data.skipBackup, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+		err := CmdImport(settings.Context, data.databaseName, data.filePath, data.collection, data.database, data.skipBackup, false, 1, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
 
 		// assert
 		if err != nil {
@@ -259,6 +288,7 @@ func TestDbImportOverFiveGB(t *testing.T) {
 }
 
 func TestDbImportFailedUpload(t *testing.T) {
+	defer useTempSettingsFile(t)()
 	ioutil.WriteFile(importFilePath, []byte("select 1;"), 0644)
 	mux, server, baseURL := test.Setup()
 	defer test.Teardown(server)
@@ -310,7 +340,7 @@ func TestDbImportFailedUpload(t *testing.T) {
 	)
 
 	// test
-	err := CmdImport(dbName, importFilePath, "", "", true, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+	err := CmdImport(settings.Context, dbName, importFilePath, "", "", true, false, 1, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
 
 	// assert
 	if err == nil {
@@ -322,6 +352,7 @@ func TestDbImportFailedUpload(t *testing.T) {
 }
 
 func TestDbImportSingleUploadMode(t *testing.T) {
+	defer useTempSettingsFile(t)()
 	ioutil.WriteFile(importFilePath, []byte("select 1;"), 0644)
 	mux, server, baseURL := test.Setup()
 	defer test.Teardown(server)
@@ -403,7 +434,7 @@ func TestDbImportSingleUploadMode(t *testing.T) {
 		backedUp = false
 
 		// test
-		err := CmdImport(data.databaseName, data.filePath, data.collection, data.database, data.skipBackup, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+		err := CmdImport(settings.Context, data.databaseName, data.filePath, data.collection, data.database, data.skipBackup, false, 1, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
 
 		// assert
 		if err != nil {
@@ -420,3 +451,399 @@ func TestDbImportSingleUploadMode(t *testing.T) {
 	}
 	os.Remove(importFilePath)
 }
+
+func TestDbImportResume(t *testing.T) {
+	defer useTempSettingsFile(t)()
+	oldChunkSize := importChunkSize
+	importChunkSize = 16 // "select 1;" encrypts to 25 bytes, so it is uploaded in 2 parts
+	defer func() { importChunkSize = oldChunkSize }()
+	ioutil.WriteFile(importFilePath, []byte("select 1;"), 0644)
+	defer os.Remove(importFilePath)
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	ctx, cancel := context.WithCancel(context.Background())
+	settings.Context = ctx
+
+	backups := 0
+	initiated := 0
+	uploaded := []string{}
+	var encrypted []byte
+	var completeBody, key, iv string
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s"}]`, dbID, dbName))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup",
+		func(w http.ResponseWriter, r *http.Request) {
+			backups++
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"running","backup":{"keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"finished","backup":{"keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs/"+dbImportID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","type":"restore","status":"finished","restore":{"keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbImportID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-restore-logs-url/",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/logs"}`, baseURL.String()))
+		},
+	)
+	mux.HandleFunc("/logs",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte{186, 194, 51, 73, 71, 71, 38, 3, 182, 216, 210, 144, 156, 237, 120, 227, 95, 91, 197, 59, 19}) // gcm encrypted "test"
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/initiate-multipart-upload",
+		func(w http.ResponseWriter, r *http.Request) {
+			initiated++
+			fmt.Fprint(w, fmt.Sprintf(`{"upload_id":"upload_id","file_name": "%s"}`, importFilePath))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/multipart-upload-url",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/restore?partNumber=%s"}`, baseURL.String(), r.URL.Query().Get("partNumber")))
+		},
+	)
+	mux.HandleFunc("/restore",
+		func(w http.ResponseWriter, r *http.Request) {
+			part := r.URL.Query().Get("partNumber")
+			b, _ := ioutil.ReadAll(r.Body)
+			if part == "2" && ctx.Err() == nil {
+				// the connection drops and the process is interrupted
+				cancel()
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			uploaded = append(uploaded, part)
+			encrypted = append(encrypted, b...)
+			w.Header().Set("ETag", "etag-"+part)
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/complete-multipart-upload",
+		func(w http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			completeBody = string(b)
+			fmt.Fprint(w, `{"location":"location"}`)
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/import",
+		func(w http.ResponseWriter, r *http.Request) {
+			var params map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&params)
+			key = params["encryptionKey"].(string)
+			iv = params["encryptionIV"].(string)
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","type":"restore","status":"running","restore":{"keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbImportID))
+		},
+	)
+	mux.HandleFunc("/healthcheck",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"status":"ok","version": "4.1.0"}`)
+		},
+	)
+
+	// test
+	err := CmdImport(settings.Context, dbName, importFilePath, "", "", false, false, 1, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+
+	// assert
+	if err == nil {
+		t.Fatalf("Expected the interrupted import to fail")
+	}
+	if !strings.Contains(err.Error(), "part 2") || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Errorf("Expected the import to fail because it was interrupted, got %s", err)
+	}
+	state, err := LoadImportState(dbID, importFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state == nil || len(state.Parts) != 1 {
+		t.Fatalf("Expected the state to be saved with 1 uploaded part, got %+v", state)
+	}
+	fi, err := os.Stat(state.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertEquals(t, "-rw-------", fi.Mode().String())
+
+	// test
+	settings.Context = context.Background()
+	err = CmdImport(settings.Context, dbName, importFilePath, "", "", false, true, 1, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+
+	// assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	test.AssertEquals(t, "1", fmt.Sprintf("%d", backups))
+	test.AssertEquals(t, "1", fmt.Sprintf("%d", initiated))
+	test.AssertEquals(t, "1,2", strings.Join(uploaded, ","))
+	test.AssertEquals(t, `[{"ETag":"etag-1","PartNumber":1},{"ETag":"etag-2","PartNumber":2}]`, completeBody)
+	var decrypted bytes.Buffer
+	dwc, err := crypto.New().NewDecryptWriteCloser(nopWriteCloser{&decrypted}, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dwc.Write(encrypted)
+	if err = dwc.Close(); err != nil {
+		t.Fatalf("The resumed upload could not be decrypted: %s", err)
+	}
+	test.AssertEquals(t, "select 1;", decrypted.String())
+	if state, _ = LoadImportState(dbID, importFilePath); state != nil {
+		t.Errorf("Expected the state to be removed after the import")
+	}
+
+	// test
+	err = CmdImport(settings.Context, dbName, importFilePath, "", "", false, true, 1, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+
+	// assert
+	if err == nil {
+		t.Errorf("Expected an error resuming when there is no interrupted import")
+	}
+}
+
+// multipartImport records the parts of a multipart import sent to a test
+// server
+type multipartImport struct {
+	lock         sync.Mutex
+	inFlight     int
+	maxInFlight  int
+	parts        map[int][]byte
+	completeBody string
+	key          string
+	iv           string
+}
+
+// decrypt joins the uploaded parts in order and decrypts them
+func (m *multipartImport) decrypt(t *testing.T) string {
+	var decrypted bytes.Buffer
+	dwc, err := crypto.New().NewDecryptWriteCloser(nopWriteCloser{&decrypted}, m.key, m.iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= len(m.parts); i++ {
+		dwc.Write(m.parts[i])
+	}
+	if err = dwc.Close(); err != nil {
+		t.Fatalf("The uploaded parts could not be decrypted: %s", err)
+	}
+	return decrypted.String()
+}
+
+// setupMultipartImport handles every request of a multipart import
+func setupMultipartImport(mux *http.ServeMux, baseURL string) *multipartImport {
+	m := &multipartImport{parts: map[int][]byte{}}
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s"}]`, dbID, dbName))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"running","backup":{"keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"finished","backup":{"keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-restore-logs-url/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/logs"}`, baseURL))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs/"+dbImportID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","type":"restore","status":"finished","restore":{"keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbImportID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-restore-logs-url/"+dbImportID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/logs"}`, baseURL))
+		},
+	)
+	mux.HandleFunc("/logs",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte{186, 194, 51, 73, 71, 71, 38, 3, 182, 216, 210, 144, 156, 237, 120, 227, 95, 91, 197, 59, 19}) // gcm encrypted "test"
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/initiate-multipart-upload",
+		func(w http.ResponseWriter, r *http.Request) {
+			m.lock.Lock()
+			m.parts = map[int][]byte{}
+			m.lock.Unlock()
+			fmt.Fprint(w, fmt.Sprintf(`{"upload_id":"upload_id","file_name": "%s"}`, importFilePath))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/multipart-upload-url",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/restore?partNumber=%s"}`, baseURL, r.URL.Query().Get("partNumber")))
+		},
+	)
+	mux.HandleFunc("/restore",
+		func(w http.ResponseWriter, r *http.Request) {
+			m.lock.Lock()
+			m.inFlight++
+			if m.inFlight > m.maxInFlight {
+				m.maxInFlight = m.inFlight
+			}
+			m.lock.Unlock()
+			b, _ := ioutil.ReadAll(r.Body)
+			time.Sleep(10 * time.Millisecond)
+			part := r.URL.Query().Get("partNumber")
+			var number int
+			fmt.Sscanf(part, "%d", &number)
+			m.lock.Lock()
+			m.inFlight--
+			m.parts[number] = b
+			m.lock.Unlock()
+			w.Header().Set("ETag", "etag-"+part)
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/complete-multipart-upload",
+		func(w http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			m.completeBody = string(b)
+			fmt.Fprint(w, `{"location":"location"}`)
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/import",
+		func(w http.ResponseWriter, r *http.Request) {
+			var params map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&params)
+			m.key = params["encryptionKey"].(string)
+			m.iv = params["encryptionIV"].(string)
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","type":"restore","status":"running","restore":{"keyLogs":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbImportID))
+		},
+	)
+	mux.HandleFunc("/healthcheck",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"status":"ok","version": "4.1.0"}`)
+		},
+	)
+	return m
+}
+
+func TestDbImportConcurrency(t *testing.T) {
+	defer useTempSettingsFile(t)()
+	oldChunkSize := importChunkSize
+	importChunkSize = 16
+	defer func() { importChunkSize = oldChunkSize }()
+	contents := strings.Repeat("select 1;\n", 20)
+	ioutil.WriteFile(importFilePath, []byte(contents), 0644)
+	defer os.Remove(importFilePath)
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	m := setupMultipartImport(mux, baseURL.String())
+
+	// test
+	err := CmdImport(settings.Context, dbName, importFilePath, "", "", true, false, 4, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+
+	// assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	// 200 bytes encrypt to 216 bytes, which is 14 parts of 16 bytes
+	test.AssertEquals(t, "14", fmt.Sprintf("%d", len(m.parts)))
+	if m.maxInFlight > 4 {
+		t.Errorf("Expected at most 4 parts to be uploaded at once, got %d", m.maxInFlight)
+	}
+	expectedParts := []string{}
+	for i := 1; i <= 14; i++ {
+		expectedParts = append(expectedParts, fmt.Sprintf(`{"ETag":"etag-%d","PartNumber":%d}`, i, i))
+	}
+	test.AssertEquals(t, "["+strings.Join(expectedParts, ",")+"]", m.completeBody)
+	test.AssertEquals(t, contents, m.decrypt(t))
+
+	// test
+	err = CmdImport(settings.Context, dbName, importFilePath, "", "", true, false, 0, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+
+	// assert
+	if err == nil {
+		t.Errorf("Expected an error with a concurrency of 0")
+	}
+}
+
+var dbImportStreamTests = []struct {
+	filePath  string
+	contents  string
+	numParts  int
+	expectErr bool
+}{
+	{"db-import.sql.gz", strings.Repeat("select 1;\n", 20), 14, false},
+	{"db-import.sql.zst", strings.Repeat("select 1;\n", 20), 14, false},
+	{StdinFilePath, strings.Repeat("select 1;\n", 20), 14, false},
+	{StdinFilePath, "select 1;\n12345", 2, false}, // encrypts to exactly 2 parts
+	{StdinFilePath, "", 1, false},
+}
+
+func TestDbImportStreams(t *testing.T) {
+	defer useTempSettingsFile(t)()
+	oldChunkSize := importChunkSize
+	importChunkSize = 16
+	defer func() { importChunkSize = oldChunkSize }()
+	oldStdin := os.Stdin
+	defer func() { os.Stdin = oldStdin }()
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	m := setupMultipartImport(mux, baseURL.String())
+
+	for _, data := range dbImportStreamTests {
+		t.Logf("Data: %+v", data)
+		switch {
+		case data.filePath == StdinFilePath:
+			f, _ := ioutil.TempFile("", "datica-stdin")
+			f.WriteString(data.contents)
+			f.Seek(0, io.SeekStart)
+			defer os.Remove(f.Name())
+			os.Stdin = f
+		case strings.HasSuffix(data.filePath, ".gz"):
+			var b bytes.Buffer
+			gz := gzip.NewWriter(&b)
+			gz.Write([]byte(data.contents))
+			gz.Close()
+			ioutil.WriteFile(data.filePath, b.Bytes(), 0644)
+			defer os.Remove(data.filePath)
+		case strings.HasSuffix(data.filePath, ".zst"):
+			cmd := exec.Command("zstd", "-q", "-o", data.filePath)
+			cmd.Stdin = strings.NewReader(data.contents)
+			if err := cmd.Run(); err != nil {
+				t.Logf("Skipping, zstd is not available: %s", err)
+				continue
+			}
+			defer os.Remove(data.filePath)
+		}
+
+		// test
+		err := CmdImport(settings.Context, dbName, data.filePath, "", "", false, false, 2, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+
+		// assert
+		if err != nil {
+			if !data.expectErr {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			continue
+		} else if data.expectErr {
+			t.Errorf("Expected error but got nil")
+			continue
+		}
+		test.AssertEquals(t, fmt.Sprintf("%d", data.numParts), fmt.Sprintf("%d", len(m.parts)))
+		test.AssertEquals(t, data.contents, m.decrypt(t))
+	}
+
+	// test
+	err := CmdImport(settings.Context, dbName, StdinFilePath, "", "", true, false, 1, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+
+	// assert
+	if err == nil {
+		t.Errorf("Expected an error skipping the backup while importing from stdin")
+	}
+}
@@ -3,13 +3,114 @@ package db
 import (
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/models"
+	"github.com/olekukonko/tablewriter"
 )
 
-func CmdList(databaseName string, page, pageSize int, id IDb, is services.IServices) error {
+// listAllPageSize is the number of jobs retrieved per request while finding
+// every job of a database
+const listAllPageSize = 100
+
+// listJobTypes are the types of jobs that can be listed
+var listJobTypes = []string{"backup", "restore", "import"}
+
+// listTimeForms are the accepted formats for --since and --until. Times
+// without a time zone are in the local time zone.
+var listTimeForms = []string{
+	"2006-01-02",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+}
+
+// ListFilter narrows down the jobs shown by db list. Empty fields match every
+// job. Since is inclusive and Until is exclusive.
+type ListFilter struct {
+	Type   string
+	Status string
+	Since  string
+	Until  string
+
+	since time.Time
+	until time.Time
+}
+
+// parse validates the filter and parses its times
+func (f *ListFilter) parse() error {
+	if f.Type == "" {
+		f.Type = "backup"
+	}
+	valid := false
+	for _, t := range listJobTypes {
+		if f.Type == t {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("Invalid --type \"%s\". The type must be one of %s", f.Type, strings.Join(listJobTypes, ", "))
+	}
+	var err error
+	if f.since, err = parseListTime(f.Since); err != nil {
+		return fmt.Errorf("Invalid --since \"%s\". Please use a date such as 2006-01-02 or a time such as 2006-01-02T15:04", f.Since)
+	}
+	if f.until, err = parseListTime(f.Until); err != nil {
+		return fmt.Errorf("Invalid --until \"%s\". Please use a date such as 2006-01-02 or a time such as 2006-01-02T15:04", f.Until)
+	}
+	if !f.since.IsZero() && !f.until.IsZero() && !f.since.Before(f.until) {
+		return fmt.Errorf("--since must be before --until")
+	}
+	return nil
+}
+
+// Matches reports whether a job passes the status and date filters
+func (f *ListFilter) Matches(job models.Job) bool {
+	if f.Status != "" && job.Status != f.Status {
+		return false
+	}
+	if f.since.IsZero() && f.until.IsZero() {
+		return true
+	}
+	createdAt, err := parseCreatedAt(job.CreatedAt)
+	if err != nil {
+		return false
+	}
+	if !f.since.IsZero() && createdAt.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !createdAt.Before(f.until) {
+		return false
+	}
+	return true
+}
+
+func parseListTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	var err error
+	for _, form := range listTimeForms {
+		var t time.Time
+		if t, err = time.ParseInLocation(form, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func CmdList(databaseName string, page, pageSize int, all bool, filter ListFilter, id IDb, is services.IServices, out output.IOutput) error {
+	if err := filter.parse(); err != nil {
+		return err
+	}
 	service, err := is.RetrieveByLabel(databaseName)
 	if err != nil {
 		return err
@@ -17,25 +118,101 @@ func CmdList(databaseName string, page, pageSize int, id IDb, is services.IServi
 	if service == nil {
 		return fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"datica services list\" command.", databaseName)
 	}
-	jobs, err := id.List(page, pageSize, service)
+	var jobs *[]models.Job
+	if all {
+		jobs, err = listAll(filter.Type, id, service)
+	} else {
+		jobs, err = id.List(filter.Type, page, pageSize, service)
+	}
 	if err != nil {
 		return err
 	}
-	sort.Sort(SortedJobs(*jobs))
+	pageFull := len(*jobs) == pageSize
+	matched := []models.Job{}
 	for _, job := range *jobs {
-		logrus.Printf("%s %s (status = %s)", job.ID, job.CreatedAt, job.Status)
+		if filter.Matches(job) {
+			matched = append(matched, job)
+		}
+	}
+	sort.Sort(SortedJobs(matched))
+	return out.Render(matched, func() error {
+		return printBackups(matched, filter, page, all, pageFull)
+	})
+}
+
+// listAll retrieves every job of the given type for the service
+func listAll(jobType string, id IDb, service *models.Service) (*[]models.Job, error) {
+	all := []models.Job{}
+	for page := 1; ; page++ {
+		jobs, err := id.List(jobType, page, listAllPageSize, service)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, *jobs...)
+		if len(*jobs) < listAllPageSize {
+			break
+		}
+	}
+	return &all, nil
+}
+
+func printBackups(jobs []models.Job, filter ListFilter, page int, all, pageFull bool) error {
+	if len(jobs) > 0 {
+		data := [][]string{{"Job Id", "Type", "Status", "Created At", "Method", "Duration"}}
+		for _, job := range jobs {
+			createdAt := job.CreatedAt
+			if t, err := parseCreatedAt(job.CreatedAt); err == nil {
+				createdAt = t.Local().Format(time.ANSIC)
+			}
+			method := ""
+			if job.IsSnapshotBackup != nil {
+				method = "logical"
+				if *job.IsSnapshotBackup {
+					method = "snapshot"
+				}
+			}
+			data = append(data, []string{job.ID, job.Type, job.Status, createdAt, method, jobDuration(job)})
+		}
+
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.AppendBulk(data)
+		table.Render()
 	}
-	if len(*jobs) == pageSize && page == 1 {
-		logrus.Println("(for older backups, try with --page 2 or adjust --page-size)")
+	if !all && pageFull {
+		logrus.Printf("(for older jobs, try with --page %d, adjust --page-size, or use --all)", page+1)
 	}
-	if len(*jobs) == 0 && page == 1 {
-		logrus.Println("No backups created yet for this service.")
-	} else if len(*jobs) == 0 {
-		logrus.Println("No backups found with the given parameters.")
+	filtered := filter.Status != "" || filter.Since != "" || filter.Until != ""
+	if len(jobs) == 0 && page == 1 && !filtered {
+		logrus.Printf("No %s jobs created yet for this service.", filter.Type)
+	} else if len(jobs) == 0 {
+		logrus.Println("No jobs found with the given parameters.")
 	}
 	return nil
 }
 
+// jobDuration is how long a job ran for, or an empty string if it has not
+// finished or the API did not say when it finished
+func jobDuration(job models.Job) string {
+	switch job.Status {
+	case "scheduled", "queued", "started", "running", "waiting":
+		return ""
+	}
+	createdAt, err := parseCreatedAt(job.CreatedAt)
+	if err != nil {
+		return ""
+	}
+	updatedAt, err := parseCreatedAt(job.UpdatedAt)
+	if err != nil || updatedAt.Before(createdAt) {
+		return ""
+	}
+	return updatedAt.Sub(createdAt).Round(time.Second).String()
+}
+
 // SortedJobs is a wrapper for Jobs array in order to sort them by CreatedAt
 // for the ListBackups command
 type SortedJobs []models.Job
@@ -52,10 +229,10 @@ func (jobs SortedJobs) Less(i, j int) bool {
 	return jobs[i].CreatedAt < jobs[j].CreatedAt
 }
 
-// List lists the created backups for the service sorted from oldest to newest
-func (d *SDb) List(page, pageSize int, service *models.Service) (*[]models.Job, error) {
+// List lists the jobs of the given type for the service, such as its backups
+func (d *SDb) List(jobType string, page, pageSize int, service *models.Service) (*[]models.Job, error) {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/jobs?type=backup&pageNumber=%d&pageSize=%d", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, page, pageSize), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Get(d.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/jobs?type=%s&pageNumber=%d&pageSize=%d", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID, jobType, page, pageSize), headers)
 	if err != nil {
 		return nil, err
 	}
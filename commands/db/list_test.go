@@ -3,12 +3,17 @@ package db
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/daticahealth/cli/commands/services"
 	"github.com/daticahealth/cli/lib/compress"
 	"github.com/daticahealth/cli/lib/crypto"
 	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/daticahealth/cli/models"
 	"github.com/daticahealth/cli/test"
 )
 
@@ -45,7 +50,7 @@ func TestDbList(t *testing.T) {
 		t.Logf("Data: %+v", data)
 
 		// test
-		err := CmdList(data.databaseName, data.page, data.pageSize, New(settings, crypto.New(), compress.New(), jobs.New(settings)), services.New(settings))
+		err := CmdList(data.databaseName, data.page, data.pageSize, false, ListFilter{}, New(settings, crypto.New(), compress.New(), jobs.New(settings)), services.New(settings), output.New(settings))
 
 		// assert
 		if err != nil != data.expectErr {
@@ -54,3 +59,122 @@ func TestDbList(t *testing.T) {
 		}
 	}
 }
+
+var dbListFilterTests = []struct {
+	all       bool
+	filter    ListFilter
+	expected  []string
+	expectErr bool
+}{
+	{false, ListFilter{}, []string{"job-1", "job-2"}, false},
+	{true, ListFilter{}, []string{"job-1", "job-2", "job-3", "job-4"}, false},
+	{true, ListFilter{Status: "finished"}, []string{"job-1", "job-3"}, false},
+	{true, ListFilter{Since: "2017-03-02T00:00:00Z"}, []string{"job-2", "job-3", "job-4"}, false},
+	{true, ListFilter{Until: "2017-03-03T02:00:00Z"}, []string{"job-1", "job-2", "job-3"}, false},
+	{true, ListFilter{Status: "finished", Since: "2017-03-02T00:00:00Z", Until: "2017-03-03T02:00:00Z"}, []string{"job-3"}, false},
+	{true, ListFilter{Type: "restore"}, []string{"job-1", "job-2", "job-3", "job-4"}, false},
+	{true, ListFilter{Type: "deploy"}, nil, true},
+	{true, ListFilter{Since: "yesterday"}, nil, true},
+	{true, ListFilter{Since: "2017-03-03", Until: "2017-03-02"}, nil, true},
+}
+
+func TestDbListFilter(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s"}]`, dbID, dbName))
+		},
+	)
+	pages := [][]string{
+		{
+			`{"id":"job-1","type":"%s","status":"finished","created_at":"2017-03-01T01:00:00","updated_at":"2017-03-01T01:02:30","isSnapshotBackup":false}`,
+			`{"id":"job-2","type":"%s","status":"failed","created_at":"2017-03-02T01:00:00","updated_at":"2017-03-02T01:00:05","isSnapshotBackup":true}`,
+		},
+		{
+			`{"id":"job-3","type":"%s","status":"finished","created_at":"2017-03-03T01:59:59","updated_at":"2017-03-03T03:00:00"}`,
+			`{"id":"job-4","type":"%s","status":"running","created_at":"2017-03-03T02:00:00"}`,
+		},
+	}
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			jobType := r.URL.Query().Get("type")
+			page, _ := strconv.Atoi(r.URL.Query().Get("pageNumber"))
+			pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+			if pageSize != 2 && pageSize != listAllPageSize {
+				t.Errorf("Unexpected page size %d", pageSize)
+			}
+			// serve both pages at once when every job is requested
+			var jobs []string
+			for i, p := range pages {
+				if page == i+1 || (pageSize == listAllPageSize && page == 1) {
+					for _, j := range p {
+						jobs = append(jobs, fmt.Sprintf(j, jobType))
+					}
+				}
+			}
+			fmt.Fprint(w, "["+strings.Join(jobs, ",")+"]")
+		},
+	)
+
+	for _, data := range dbListFilterTests {
+		t.Logf("Data: %+v", data)
+
+		// test
+		out := &recordingOutput{}
+		err := CmdList(dbName, 1, 2, data.all, data.filter, New(settings, crypto.New(), compress.New(), jobs.New(settings)), services.New(settings), out)
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if data.expectErr {
+			continue
+		}
+		listed := out.data.([]models.Job)
+		ids := []string{}
+		for _, job := range listed {
+			ids = append(ids, job.ID)
+			if data.filter.Type != "" && job.Type != data.filter.Type {
+				t.Errorf("Expected %s jobs but got %s", data.filter.Type, job.Type)
+			}
+		}
+		if strings.Join(ids, ",") != strings.Join(data.expected, ",") {
+			t.Errorf("Expected jobs %v but got %v", data.expected, ids)
+		}
+	}
+}
+
+func TestJobDuration(t *testing.T) {
+	finished := models.Job{Status: "finished", CreatedAt: "2017-03-01T01:00:00", UpdatedAt: "2017-03-01T02:02:30"}
+	if d := jobDuration(finished); d != (time.Hour + 2*time.Minute + 30*time.Second).String() {
+		t.Errorf("Expected a duration of 1h2m30s but got %s", d)
+	}
+	running := models.Job{Status: "running", CreatedAt: "2017-03-01T01:00:00", UpdatedAt: "2017-03-01T02:02:30"}
+	if d := jobDuration(running); d != "" {
+		t.Errorf("Expected no duration for a running job but got %s", d)
+	}
+	unknown := models.Job{Status: "finished", CreatedAt: "2017-03-01T01:00:00"}
+	if d := jobDuration(unknown); d != "" {
+		t.Errorf("Expected no duration without an updated time but got %s", d)
+	}
+}
+
+// recordingOutput keeps the data rendered by a command and prints its table
+type recordingOutput struct {
+	data interface{}
+}
+
+func (o *recordingOutput) Render(data interface{}, table func() error) error {
+	o.data = data
+	return table()
+}
+
+func (o *recordingOutput) Format() string {
+	return output.Table
+}
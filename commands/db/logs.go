@@ -1,14 +1,19 @@
 package db
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/config"
+	"github.com/daticahealth/cli/lib/httpclient"
 	"github.com/daticahealth/cli/lib/jobs"
 	"github.com/daticahealth/cli/models"
 )
@@ -28,71 +33,164 @@ func CmdLogs(databaseName, backupID string, id IDb, is services.IServices, ij jo
 	return id.DumpLogs(job.Type, job, service)
 }
 
+// logTailInterval is how often the logs of a running job are checked for new
+// lines
+var logTailInterval = config.JobPollTime * time.Second
+
 // DumpLogs dumps logs from a Backup/Restore/Import/Export job to the console
 func (d *SDb) DumpLogs(taskType string, job *models.Job, service *models.Service) error {
 	logrus.Printf("Retrieving %s logs for job %s...", service.Label, job.ID)
-	tempURL, err := d.TempLogsURL(job.ID, service.ID)
-	if err != nil {
-		return err
+	t := &LogTail{d: d, taskType: taskType, job: job, service: service, out: os.Stdout}
+	return t.Finish()
+}
+
+// LogTail prints the logs of a job while it runs. Every logTailInterval the
+// logs are downloaded again if they changed, and any new complete lines are
+// printed. The logs are only ever written whole, so each download is
+// decrypted and authenticated on its own.
+type LogTail struct {
+	d        *SDb
+	taskType string
+	job      *models.Job
+	service  *models.Service
+	out      io.Writer
+
+	// logs are the decrypted logs last downloaded, of which printed bytes have
+	// been printed
+	logs    []byte
+	printed int
+	etag    string
+	begun   bool
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// TailLogs starts printing the logs of a running Backup/Restore/Import/Export
+// job. Call Finish once the job is done to print the rest of its logs.
+func (d *SDb) TailLogs(ctx context.Context, taskType string, job *models.Job, service *models.Service) *LogTail {
+	t := &LogTail{d: d, taskType: taskType, job: job, service: service, out: os.Stdout, stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(t.done)
+		for {
+			select {
+			case <-t.stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-time.After(logTailInterval):
+			}
+			// the logs may not have been written yet, so only the final
+			// download reports errors
+			t.fetch(false)
+		}
+	}()
+	return t
+}
+
+// Stop stops checking for new lines without printing the rest of the logs
+func (t *LogTail) Stop() {
+	if t.stop == nil {
+		return
 	}
-	dir, err := ioutil.TempDir("", "")
-	if err != nil {
+	t.stopOnce.Do(func() {
+		close(t.stop)
+		<-t.done
+	})
+}
+
+// Finish stops tailing the logs and prints whatever has not been printed yet
+func (t *LogTail) Finish() error {
+	t.Stop()
+	if err := t.fetch(true); err != nil {
 		return err
 	}
+	t.begin(false)
+	logrus.Printf("--------------------------- End %s logs ---------------------------", t.service.Label)
+	return nil
+}
 
-	encrFile, err := ioutil.TempFile(dir, "")
+func (t *LogTail) begin(polling bool) {
+	if t.begun {
+		return
+	}
+	t.begun = true
+	// polling prints dots without a newline
+	prefix := ""
+	if polling {
+		prefix = "\n"
+	}
+	logrus.Printf("%s-------------------------- Begin %s logs --------------------------", prefix, t.service.Label)
+}
+
+// fetch downloads the logs if they changed and prints any new lines. The
+// final fetch also prints a last line without a newline.
+func (t *LogTail) fetch(final bool) error {
+	var key, iv string
+	switch t.taskType {
+	case "backup":
+		key, iv = t.job.Backup.KeyLogs, t.job.Backup.IV
+		if key == "" {
+			key = t.job.Backup.Key
+		}
+	case "restore":
+		key, iv = t.job.Restore.KeyLogs, t.job.Restore.IV
+		if key == "" {
+			key = t.job.Restore.Key
+		}
+	default:
+		return nil
+	}
+	tempURL, err := t.d.TempLogsURL(t.job.ID, t.service.ID)
 	if err != nil {
 		return err
 	}
-	resp, err := http.Get(tempURL.URL)
+	req, err := http.NewRequest("GET", tempURL.URL, nil)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	io.Copy(encrFile, resp.Body)
-	encrFile.Close()
-
-	plainFile, err := ioutil.TempFile(dir, "")
+	if t.etag != "" {
+		req.Header.Set("If-None-Match", t.etag)
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(t.d.Settings.Context))
 	if err != nil {
 		return err
 	}
-	// do we have to close this before calling DecryptFile?
-	// or can two processes have a file open simultaneously?
-	plainFile.Close()
-
-	if taskType == "backup" {
-		logsKey := job.Backup.KeyLogs
-		if logsKey == "" {
-			logsKey = job.Backup.Key
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotModified {
+		if httpclient.IsError(resp.StatusCode) {
+			return httpclient.ConvertError(resp)
 		}
-		err := d.Crypto.DecryptFile(encrFile.Name(), logsKey, job.Backup.IV, plainFile.Name())
+		var logs bytes.Buffer
+		dwc, err := t.d.Crypto.NewDecryptWriteCloser(nopWriteCloser{&logs}, key, iv)
 		if err != nil {
 			return err
 		}
-	} else if taskType == "restore" {
-		logsKey := job.Restore.KeyLogs
-		if logsKey == "" {
-			logsKey = job.Restore.Key
+		if _, err = io.Copy(dwc, resp.Body); err != nil {
+			return err
 		}
-		err := d.Crypto.DecryptFile(encrFile.Name(), logsKey, job.Restore.IV, plainFile.Name())
-		if err != nil {
+		if err = dwc.Close(); err != nil {
 			return err
 		}
+		t.logs = logs.Bytes()
+		t.etag = resp.Header.Get("ETag")
+	}
+	end := len(t.logs)
+	if !final {
+		end = bytes.LastIndexByte(t.logs, '\n') + 1
+	}
+	if end > t.printed {
+		t.begin(!final)
+		t.out.Write(t.logs[t.printed:end])
+		t.printed = end
 	}
-	logrus.Printf("-------------------------- Begin %s logs --------------------------", service.Label)
-	plainFile, _ = os.Open(plainFile.Name())
-	io.Copy(os.Stdout, plainFile)
-	plainFile.Close()
-	logrus.Printf("--------------------------- End %s logs ---------------------------", service.Label)
-	os.Remove(encrFile.Name())
-	os.Remove(plainFile.Name())
-	os.Remove(dir)
 	return nil
 }
 
 func (d *SDb) TempLogsURL(jobID string, serviceID string) (*models.TempURL, error) {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/backup-restore-logs-url/%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, serviceID, jobID), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Get(d.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/backup-restore-logs-url/%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, serviceID, jobID), headers)
 	if err != nil {
 		return nil, err
 	}
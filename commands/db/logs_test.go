@@ -1,14 +1,18 @@
 package db
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/daticahealth/cli/commands/services"
 	"github.com/daticahealth/cli/lib/compress"
 	"github.com/daticahealth/cli/lib/crypto"
 	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/models"
 	"github.com/daticahealth/cli/test"
 )
 
@@ -74,3 +78,80 @@ func TestDbLogs(t *testing.T) {
 		}
 	}
 }
+
+func TestDbTailLogs(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	oldInterval := logTailInterval
+	logTailInterval = 10 * time.Millisecond
+	defer func() { logTailInterval = oldInterval }()
+
+	// the logs as they are rewritten while the job runs
+	versions := []string{"one\ntw", "one\ntwo\nthr", "one\ntwo\nthree"}
+	var mu sync.Mutex
+	requests := 0
+	notModified := 0
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-restore-logs-url/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/logs"}`, baseURL.String()))
+		},
+	)
+	mux.HandleFunc("/logs",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			mu.Lock()
+			defer mu.Unlock()
+			if requests == 0 {
+				// the job has not written any logs yet
+				requests++
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			version := requests - 1
+			if version >= len(versions) {
+				version = len(versions) - 1
+			}
+			requests++
+			etag := fmt.Sprintf(`"%d"`, version)
+			if r.Header.Get("If-None-Match") == etag {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			w.Write(encryptTestBackup(t, []byte(versions[version])))
+		},
+	)
+	job := &models.Job{ID: dbJobID, Backup: &models.EncryptionStore{KeyLogs: "0000000000000000000000000000000000000000000000000000000000000000", IV: "000000000000000000000000"}}
+	service := &models.Service{ID: dbID, Label: dbName}
+	var buf bytes.Buffer
+
+	// test
+	tail := New(settings, crypto.New(), compress.New(), jobs.New(settings)).TailLogs(settings.Context, "backup", job, service)
+	tail.out = &buf
+	for i := 0; i < 500; i++ {
+		mu.Lock()
+		done := notModified > 0
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	tail.Stop()
+	printed := buf.String()
+	err := tail.Finish()
+
+	// assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if printed != "one\ntwo\n" {
+		t.Errorf("Unexpected logs while tailing. Expected: %q, actual: %q", "one\ntwo\n", printed)
+	}
+	if buf.String() != versions[len(versions)-1] {
+		t.Errorf("Unexpected logs. Expected: %q, actual: %q", versions[len(versions)-1], buf.String())
+	}
+}
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/lib/prompts"
+	"github.com/daticahealth/cli/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+const pruneDateForm = "2006-01-02T15:04:05"
+
+// RetentionPolicy decides which backups are kept. A backup is kept when any
+// one of the rules keeps it. When OlderThan is set, only backups older than
+// that are ever removed. The newest finished backup is always kept unless
+// AllowDeleteAll is set.
+type RetentionPolicy struct {
+	KeepLast       int
+	KeepDaily      int
+	KeepWeekly     int
+	OlderThan      time.Duration
+	AllowDeleteAll bool
+}
+
+// Empty is true when no rule is set, which would remove every backup
+func (p RetentionPolicy) Empty() bool {
+	return p.KeepLast <= 0 && p.KeepDaily <= 0 && p.KeepWeekly <= 0 && p.OlderThan <= 0
+}
+
+// Prune splits the given backups into the ones to keep and the ones to remove.
+// Only finished backups are ever removed, and backups whose creation date
+// cannot be read are always kept. Both lists are sorted from newest to oldest.
+func (p RetentionPolicy) Prune(backups []models.Job, now time.Time) (keep, remove []models.Job) {
+	finished := []models.Job{}
+	for _, b := range backups {
+		if _, err := parseCreatedAt(b.CreatedAt); b.Status != "finished" || err != nil {
+			keep = append(keep, b)
+			continue
+		}
+		finished = append(finished, b)
+	}
+	sort.Sort(sort.Reverse(SortedJobs(finished)))
+
+	kept := map[string]bool{}
+	if !p.AllowDeleteAll && len(finished) > 0 {
+		kept[finished[0].ID] = true
+	}
+	for i := 0; i < p.KeepLast && i < len(finished); i++ {
+		kept[finished[i].ID] = true
+	}
+	p.keepPerPeriod(finished, p.KeepDaily, kept, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	p.keepPerPeriod(finished, p.KeepWeekly, kept, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+	for _, b := range finished {
+		createdAt, _ := parseCreatedAt(b.CreatedAt)
+		if kept[b.ID] || (p.OlderThan > 0 && now.Sub(createdAt) < p.OlderThan) {
+			keep = append(keep, b)
+			continue
+		}
+		remove = append(remove, b)
+	}
+	sort.Sort(sort.Reverse(SortedJobs(keep)))
+	return keep, remove
+}
+
+// keepPerPeriod keeps the newest backup of each of the most recent periods
+// that have a backup. The backups must be sorted from newest to oldest.
+func (p RetentionPolicy) keepPerPeriod(backups []models.Job, periods int, kept map[string]bool, period func(time.Time) string) {
+	seen := map[string]bool{}
+	for _, b := range backups {
+		if len(seen) == periods {
+			return
+		}
+		createdAt, _ := parseCreatedAt(b.CreatedAt)
+		key := period(createdAt)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		kept[b.ID] = true
+	}
+}
+
+// ParseAge parses an age such as 90d, 2w, or 36h
+func ParseAge(age string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if strings.HasSuffix(age, suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(age, suffix))
+			if err != nil || n <= 0 {
+				break
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(age)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("Invalid age \"%s\". Please specify a positive age such as 90d, 2w, or 36h", age)
+	}
+	return d, nil
+}
+
+func parseCreatedAt(createdAt string) (time.Time, error) {
+	t, err := time.Parse(pruneDateForm, createdAt)
+	if err != nil {
+		return time.Parse(time.RFC3339, createdAt)
+	}
+	return t, nil
+}
+
+func CmdPrune(databaseName string, policy RetentionPolicy, dryRun, skipConfirm bool, id IDb, ip prompts.IPrompts, is services.IServices, ij jobs.IJobs) error {
+	if policy.Empty() {
+		return fmt.Errorf("Please specify at least one of --keep-last, --keep-daily, --keep-weekly, or --older-than")
+	}
+	service, err := is.RetrieveByLabel(databaseName)
+	if err != nil {
+		return err
+	}
+	if service == nil {
+		return fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"datica services list\" command.", databaseName)
+	}
+	jobs, err := listAll("backup", id, service)
+	if err != nil {
+		return err
+	}
+	backups := *jobs
+	keep, remove := policy.Prune(backups, time.Now().UTC())
+	if len(remove) == 0 {
+		logrus.Printf("Nothing to prune. All %d backups of %s are kept.", len(backups), databaseName)
+		return nil
+	}
+	printPrune(remove)
+	logrus.Printf("\n%d of %d backups of %s will be deleted, %d will be kept.", len(remove), len(backups), databaseName, len(keep))
+	if dryRun {
+		logrus.Println("This was a dry run, no backups were deleted.")
+		return nil
+	}
+	if !skipConfirm {
+		if err = ip.YesNo("Deleted backups cannot be recovered.", "Do you wish to proceed (y/n)? "); err != nil {
+			return err
+		}
+	}
+	for i, b := range remove {
+		if err = ij.Delete(b.ID, service.ID); err != nil {
+			return fmt.Errorf("Failed to delete backup %s after deleting %d of %d backups: %s", b.ID, i, len(remove), err)
+		}
+		logrus.Debugf("Deleted backup %s", b.ID)
+	}
+	newest, _ := parseCreatedAt(remove[0].CreatedAt)
+	oldest, _ := parseCreatedAt(remove[len(remove)-1].CreatedAt)
+	logrus.Printf("Deleted %d backups of %s created between %s and %s. %d backups remain.", len(remove), databaseName, oldest.Local().Format(time.ANSIC), newest.Local().Format(time.ANSIC), len(keep))
+	return nil
+}
+
+func printPrune(remove []models.Job) {
+	data := [][]string{{"Backup Id", "Created At"}}
+	for _, b := range remove {
+		t, _ := parseCreatedAt(b.CreatedAt)
+		data = append(data, []string{b.ID, t.Local().Format(time.ANSIC)})
+	}
+	table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.AppendBulk(data)
+	table.Render()
+}
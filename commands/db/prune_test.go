@@ -0,0 +1,164 @@
+package db
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/compress"
+	"github.com/daticahealth/cli/lib/crypto"
+	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/models"
+	"github.com/daticahealth/cli/test"
+)
+
+// pruneBackups are two backups a day for 20 days ending on Sunday 2017-01-29,
+// newest first, plus a running backup
+func pruneBackups() []models.Job {
+	backups := []models.Job{{ID: "running", Status: "running", CreatedAt: "2017-01-29T23:00:00"}}
+	end := time.Date(2017, 1, 29, 0, 0, 0, 0, time.UTC)
+	for day := 0; day < 20; day++ {
+		for _, hour := range []int{18, 6} {
+			t := end.AddDate(0, 0, -day).Add(time.Duration(hour) * time.Hour)
+			backups = append(backups, models.Job{ID: t.Format("01-02T15"), Status: "finished", CreatedAt: t.Format(pruneDateForm)})
+		}
+	}
+	return backups
+}
+
+var pruneTests = []struct {
+	policy   RetentionPolicy
+	expected []string
+	removed  int
+}{
+	{RetentionPolicy{KeepLast: 3}, []string{"running", "01-29T18", "01-29T06", "01-28T18"}, 37},
+	{RetentionPolicy{KeepDaily: 2}, []string{"running", "01-29T18", "01-28T18"}, 38},
+	{RetentionPolicy{KeepLast: 1, KeepDaily: 2}, []string{"running", "01-29T18", "01-28T18"}, 38},
+	{RetentionPolicy{KeepWeekly: 3}, []string{"running", "01-29T18", "01-22T18", "01-15T18"}, 37},
+	{RetentionPolicy{KeepLast: 1, KeepWeekly: 2}, []string{"running", "01-29T18", "01-22T18"}, 38},
+	{RetentionPolicy{OlderThan: 48 * time.Hour}, []string{"running", "01-29T18", "01-29T06", "01-28T18"}, 37},
+	{RetentionPolicy{KeepLast: 1, OlderThan: 19 * 24 * time.Hour}, nil, 3},
+	// the newest backup is kept even though it is older than --older-than
+	{RetentionPolicy{OlderThan: 12 * time.Hour}, []string{"running", "01-29T18"}, 39},
+	{RetentionPolicy{OlderThan: 12 * time.Hour, AllowDeleteAll: true}, []string{"running"}, 40},
+	{RetentionPolicy{KeepWeekly: 1, OlderThan: 12 * time.Hour, AllowDeleteAll: true}, []string{"running", "01-29T18"}, 39},
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	now := time.Date(2017, 1, 30, 12, 0, 0, 0, time.UTC)
+	for _, data := range pruneTests {
+		t.Logf("Data: %+v", data)
+
+		// test
+		keep, remove := data.policy.Prune(pruneBackups(), now)
+
+		// assert
+		if len(keep)+len(remove) != len(pruneBackups()) {
+			t.Errorf("Expected every backup to be kept or removed, got %d and %d", len(keep), len(remove))
+		}
+		if len(remove) != data.removed {
+			t.Errorf("Expected %d backups to be removed, got %d", data.removed, len(remove))
+		}
+		if data.expected == nil {
+			continue
+		}
+		ids := []string{}
+		for _, b := range keep {
+			ids = append(ids, b.ID)
+		}
+		if !reflect.DeepEqual(ids, data.expected) {
+			t.Errorf("Expected to keep %v, got %v", data.expected, ids)
+		}
+	}
+}
+
+var parseAgeTests = []struct {
+	age       string
+	expected  time.Duration
+	expectErr bool
+}{
+	{"90d", 90 * 24 * time.Hour, false},
+	{"2w", 14 * 24 * time.Hour, false},
+	{"36h", 36 * time.Hour, false},
+	{"0d", 0, true},
+	{"-1d", 0, true},
+	{"soon", 0, true},
+}
+
+func TestParseAge(t *testing.T) {
+	for _, data := range parseAgeTests {
+		t.Logf("Data: %+v", data)
+		age, err := ParseAge(data.age)
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		test.AssertEquals(t, data.expected.String(), age.String())
+	}
+}
+
+var dbPruneTests = []struct {
+	databaseName string
+	policy       RetentionPolicy
+	dryRun       bool
+	deleted      int
+	expectErr    bool
+}{
+	{dbName, RetentionPolicy{KeepLast: 2}, false, 1, false},
+	{dbName, RetentionPolicy{KeepLast: 2}, true, 0, false},
+	{dbName, RetentionPolicy{KeepLast: 5}, false, 0, false},
+	{dbName, RetentionPolicy{}, false, 0, true},
+	{"invalid-svc", RetentionPolicy{KeepLast: 2}, false, 0, true},
+}
+
+func TestDbPrune(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+
+	deleted := []string{}
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s"}]`, dbID, dbName))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			test.AssertEquals(t, r.URL.Query().Get("type"), "backup")
+			fmt.Fprint(w, `[{"id":"b1","type":"backup","status":"finished","created_at":"2017-01-01T00:00:00"},{"id":"b2","type":"backup","status":"finished","created_at":"2017-01-02T00:00:00"},{"id":"b3","type":"backup","status":"finished","created_at":"2017-01-03T00:00:00"}]`)
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs/",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "DELETE")
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/environments/"+test.EnvID+"/services/"+dbID+"/jobs/"))
+			w.WriteHeader(204)
+		},
+	)
+
+	for _, data := range dbPruneTests {
+		t.Logf("Data: %+v", data)
+		deleted = []string{}
+
+		// test
+		err := CmdPrune(data.databaseName, data.policy, data.dryRun, false, New(settings, crypto.New(), compress.New(), jobs.New(settings)), &test.FakePrompts{}, services.New(settings), jobs.New(settings))
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if len(deleted) != data.deleted {
+			t.Errorf("Expected %d backups to be deleted, got %v", data.deleted, deleted)
+		}
+		if data.deleted == 1 && deleted[0] != "b1" {
+			t.Errorf("Expected the oldest backup to be deleted, got %s", deleted[0])
+		}
+	}
+}
@@ -65,7 +65,7 @@ func (d *SDb) Restore(backupID string, service *models.Service, mongoDatabase st
 	if err != nil {
 		return err
 	}
-	resp, statusCode, err := d.Settings.HTTPManager.Post(body, fmt.Sprintf("%s%s/environments/%s/services/%s/restore", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Post(d.Settings.Context, body, fmt.Sprintf("%s%s/environments/%s/services/%s/restore", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, service.ID), headers)
 	if err != nil {
 		return err
 	}
@@ -74,13 +74,15 @@ func (d *SDb) Restore(backupID string, service *models.Service, mongoDatabase st
 	if err != nil {
 		return err
 	}
-	status, err := d.Jobs.PollTillFinished(job.ID, service.ID)
+	tail := d.TailLogs(d.Settings.Context, "restore", &job, service)
+	defer tail.Stop()
+	status, err := d.Jobs.PollTillFinished(d.Settings.Context, job.ID, service.ID)
 	if err != nil {
 		return err
 	}
 	job.Status = status
 	logrus.Printf("\nEnded in status '%s'", job.Status)
-	err = d.DumpLogs("restore", &job, service)
+	err = tail.Finish()
 	if err != nil {
 		return err
 	}
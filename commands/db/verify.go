@@ -0,0 +1,274 @@
+package db
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/httpclient"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/daticahealth/cli/lib/transfer"
+	"github.com/daticahealth/cli/models"
+)
+
+// headSize is how much of the start of a backup is kept to check its format
+const headSize = 4096
+
+// BackupVerification is the result of verifying a backup. Size and SHA256
+// describe the backup as "datica db download" would save it.
+type BackupVerification struct {
+	BackupID        string `json:"backupId" yaml:"backupId"`
+	Database        string `json:"database" yaml:"database"`
+	EncryptedSize   int64  `json:"encryptedSize" yaml:"encryptedSize"`
+	EncryptedSHA256 string `json:"encryptedSha256" yaml:"encryptedSha256"`
+	Compression     string `json:"compression,omitempty" yaml:"compression,omitempty"`
+	Size            int64  `json:"size" yaml:"size"`
+	SHA256          string `json:"sha256" yaml:"sha256"`
+	Format          string `json:"format" yaml:"format"`
+}
+
+func CmdVerify(databaseName, backupID string, id IDb, is services.IServices, out output.IOutput) error {
+	service, err := is.RetrieveByLabel(databaseName)
+	if err != nil {
+		return err
+	}
+	if service == nil {
+		return fmt.Errorf("Could not find a service with the label \"%s\". You can list services with the \"datica services list\" command.", databaseName)
+	}
+	result, err := id.Verify(backupID, service)
+	if err != nil {
+		return err
+	}
+	return out.Render(result, func() error {
+		logrus.Printf("%s backup %s verified successfully", databaseName, backupID)
+		logrus.Printf("Encrypted size:    %s (%d bytes)", transfer.ByteSize(result.EncryptedSize), result.EncryptedSize)
+		logrus.Printf("Encrypted SHA-256: %s", result.EncryptedSHA256)
+		if result.Compression != "" {
+			logrus.Printf("Compression:       %s", result.Compression)
+		}
+		logrus.Printf("Size:              %s (%d bytes)", transfer.ByteSize(result.Size), result.Size)
+		logrus.Printf("SHA-256:           %s", result.SHA256)
+		logrus.Printf("Format:            %s", result.Format)
+		return nil
+	})
+}
+
+// Verify downloads a backup and checks that it can be restored without saving
+// any of it. Every chunk must pass GCM authentication, compressed backups must
+// be a valid gzip stream, and the result must look like a dump for the type of
+// database. Nothing decrypted is kept except for the start of the backup.
+func (d *SDb) Verify(backupID string, service *models.Service) (*BackupVerification, error) {
+	job, err := d.Jobs.Retrieve(backupID, service.ID, false)
+	if err != nil {
+		return nil, err
+	}
+	if job.Type != "backup" || (job.Status != "finished" && job.Status != "disappeared") {
+		return nil, errors.New("Only 'finished' 'backup' jobs may be verified")
+	}
+	tempURL, err := d.TempDownloadURL(job.ID, service)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", tempURL.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(d.Settings.Context))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if httpclient.IsError(resp.StatusCode) {
+		return nil, httpclient.ConvertError(resp)
+	}
+	if resp.ContentLength < 0 {
+		return nil, fmt.Errorf("Content-Length was not present in the response.")
+	}
+
+	result := &BackupVerification{
+		BackupID:    job.ID,
+		Database:    service.Label,
+		Compression: resp.Header.Get("x-amz-meta-datica-backup-compression"),
+	}
+	// the backup as it would be saved by db download
+	saved := newBackupInspector()
+	var dst io.WriteCloser = saved
+	var archive *tarInspector
+	if service.Name == "mongodb" {
+		// mongo backups are saved as a .tar.gz, so unpack a copy to check it
+		archive = newTarInspector()
+		unzip, err := d.Compress.NewDecompressWriteCloser(archive)
+		if err != nil {
+			return nil, err
+		}
+		dst = &teeWriteCloser{saved, unzip}
+	} else if result.Compression == "gzip" {
+		dst, err = d.Compress.NewDecompressWriteCloser(saved)
+		if err != nil {
+			return nil, err
+		}
+	}
+	dfw, err := d.Crypto.NewDecryptWriteCloser(dst, job.Backup.Key, job.Backup.IV)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := sha256.New()
+	wct := transfer.NewWriteCloserTransfer(dfw, int(resp.ContentLength))
+	done := make(chan bool)
+	go printTransferStatus(true, wct, 1, 1, done)
+	n, err := io.Copy(io.MultiWriter(encrypted, wct), resp.Body)
+	if err == nil && n != resp.ContentLength {
+		err = fmt.Errorf("Downloaded %d bytes but the backup is %d bytes", n, resp.ContentLength)
+	}
+	if err == nil {
+		err = wct.Close()
+	}
+	done <- err == nil
+	if err != nil {
+		return nil, fmt.Errorf("Backup %s failed verification: %s", job.ID, err)
+	}
+
+	result.EncryptedSize = n
+	result.EncryptedSHA256 = hex.EncodeToString(encrypted.Sum(nil))
+	result.Size = saved.size
+	result.SHA256 = hex.EncodeToString(saved.hash.Sum(nil))
+	if result.Size == 0 {
+		return nil, fmt.Errorf("Backup %s failed verification: the backup is empty", job.ID)
+	}
+	if archive != nil {
+		result.Format = fmt.Sprintf("tar archive (%d files)", archive.files)
+	} else {
+		result.Format, err = dumpFormat(service.Name, saved.head)
+		if err != nil {
+			return nil, fmt.Errorf("Backup %s failed verification: %s", job.ID, err)
+		}
+	}
+	return result, nil
+}
+
+// dumpFormat checks that the start of a backup looks like a dump made by the
+// given type of database
+func dumpFormat(serviceName string, head []byte) (string, error) {
+	switch serviceName {
+	case "postgresql":
+		if bytes.HasPrefix(head, []byte("PGDMP")) {
+			return "PostgreSQL custom format dump", nil
+		}
+		if bytes.Contains(head, []byte("PostgreSQL database dump")) || bytes.Contains(head, []byte("PostgreSQL database cluster dump")) {
+			return "PostgreSQL SQL dump", nil
+		}
+		return "", errors.New("the backup does not start with a PostgreSQL dump header")
+	case "mysql":
+		if bytes.Contains(head, []byte("MySQL dump")) || bytes.Contains(head, []byte("MariaDB dump")) {
+			return "MySQL SQL dump", nil
+		}
+		return "", errors.New("the backup does not start with a MySQL dump header")
+	}
+	return fmt.Sprintf("not checked for %s", serviceName), nil
+}
+
+// backupInspector hashes and counts everything written to it and keeps only
+// the start of the data
+type backupInspector struct {
+	hash hash.Hash
+	size int64
+	head []byte
+}
+
+func newBackupInspector() *backupInspector {
+	return &backupInspector{hash: sha256.New()}
+}
+
+func (b *backupInspector) Write(p []byte) (int, error) {
+	b.hash.Write(p)
+	b.size += int64(len(p))
+	if len(b.head) < headSize {
+		end := headSize - len(b.head)
+		if end > len(p) {
+			end = len(p)
+		}
+		b.head = append(b.head, p[:end]...)
+	}
+	return len(p), nil
+}
+
+func (b *backupInspector) Close() error {
+	return nil
+}
+
+// tarInspector reads a tar archive written to it, discarding the contents of
+// every file. Close reports whether the archive was valid.
+type tarInspector struct {
+	pw    *io.PipeWriter
+	done  chan error
+	files int
+}
+
+func newTarInspector() *tarInspector {
+	pr, pw := io.Pipe()
+	t := &tarInspector{pw: pw, done: make(chan error, 1)}
+	go func() {
+		tr := tar.NewReader(pr)
+		var err error
+		for {
+			if _, err = tr.Next(); err != nil {
+				break
+			}
+			t.files++
+			if _, err = io.Copy(ioutil.Discard, tr); err != nil {
+				break
+			}
+		}
+		if err == io.EOF {
+			err = nil
+			if t.files == 0 {
+				err = errors.New("the tar archive is empty")
+			}
+		} else {
+			err = fmt.Errorf("invalid tar archive: %s", err)
+		}
+		// drain anything after the end of the archive
+		io.Copy(ioutil.Discard, pr)
+		pr.CloseWithError(err)
+		t.done <- err
+	}()
+	return t
+}
+
+func (t *tarInspector) Write(p []byte) (int, error) {
+	return t.pw.Write(p)
+}
+
+func (t *tarInspector) Close() error {
+	t.pw.Close()
+	return <-t.done
+}
+
+// teeWriteCloser writes to and closes two io.WriteClosers
+type teeWriteCloser struct {
+	a, b io.WriteCloser
+}
+
+func (t *teeWriteCloser) Write(p []byte) (int, error) {
+	if n, err := t.a.Write(p); err != nil {
+		return n, err
+	}
+	return t.b.Write(p)
+}
+
+func (t *teeWriteCloser) Close() error {
+	err := t.a.Close()
+	if berr := t.b.Close(); err == nil {
+		err = berr
+	}
+	return err
+}
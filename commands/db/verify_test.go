@@ -0,0 +1,150 @@
+package db
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/compress"
+	"github.com/daticahealth/cli/lib/crypto"
+	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/daticahealth/cli/models"
+	"github.com/daticahealth/cli/test"
+)
+
+// encryptTestBackup encrypts data with the all zero key and IV the test jobs use
+func encryptTestBackup(t *testing.T, data []byte) []byte {
+	r, err := crypto.New().NewEncryptReader(bytes.NewReader(data), make([]byte, crypto.KeySize), make([]byte, crypto.IVSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func gzipTestData(data []byte) []byte {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	gz.Write(data)
+	gz.Close()
+	return b.Bytes()
+}
+
+func tarTestData(files map[string]string) []byte {
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	for name, contents := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents))})
+		tw.Write([]byte(contents))
+	}
+	tw.Close()
+	return b.Bytes()
+}
+
+var (
+	postgresDump = []byte("--\n-- PostgreSQL database dump\n--\n\nSET statement_timeout = 0;\n")
+	mysqlDump    = []byte("-- MySQL dump 10.13  Distrib 5.7.22, for Linux (x86_64)\n--\n-- Host: localhost    Database: catalyzeDB\n")
+	mongoArchive = gzipTestData(tarTestData(map[string]string{"dump/catalyzeDB/users.bson": "bson"}))
+)
+
+func TestDbVerify(t *testing.T) {
+	corrupt := encryptTestBackup(t, postgresDump)
+	corrupt[10]++
+
+	var dbVerifyTests = []struct {
+		serviceName string
+		compression string
+		backup      []byte
+		format      string
+		expectErr   bool
+	}{
+		{"postgresql", "", encryptTestBackup(t, postgresDump), "PostgreSQL SQL dump", false},
+		{"postgresql", "gzip", encryptTestBackup(t, gzipTestData(postgresDump)), "PostgreSQL SQL dump", false},
+		{"postgresql", "gzip", encryptTestBackup(t, gzipTestData([]byte("PGDMP\x01\x0d"))), "PostgreSQL custom format dump", false},
+		{"mysql", "gzip", encryptTestBackup(t, gzipTestData(mysqlDump)), "MySQL SQL dump", false},
+		{"mongodb", "gzip", encryptTestBackup(t, mongoArchive), "tar archive (1 files)", false},
+		{"postgresql", "", corrupt, "", true},                                                   // fails authentication
+		{"postgresql", "", encryptTestBackup(t, postgresDump)[:20], "", true},                   // truncated
+		{"postgresql", "gzip", encryptTestBackup(t, postgresDump), "", true},                    // not gzipped
+		{"postgresql", "gzip", encryptTestBackup(t, gzipTestData(postgresDump)[:30]), "", true}, // truncated gzip stream
+		{"postgresql", "", encryptTestBackup(t, mysqlDump), "", true},                           // wrong dump format
+		{"mongodb", "gzip", encryptTestBackup(t, gzipTestData(postgresDump)), "", true},         // not a tar archive
+	}
+
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+
+	var serviceName, compression string
+	var backup []byte
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s","name":"%s"}]`, dbID, dbName, serviceName))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/jobs/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","isSnapshotBackup":false,"type":"backup","status":"finished","backup":{"key":"0000000000000000000000000000000000000000000000000000000000000000","iv":"000000000000000000000000"}}`, dbJobID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+dbID+"/backup-url/"+dbJobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`{"url":"%s/backup"}`, baseURL.String()))
+		},
+	)
+	mux.HandleFunc("/backup",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			if compression != "" {
+				w.Header().Set("x-amz-meta-datica-backup-compression", compression)
+			}
+			w.Write(backup)
+		},
+	)
+
+	for _, data := range dbVerifyTests {
+		t.Logf("Data: %s %s %s", data.serviceName, data.compression, data.format)
+		serviceName = data.serviceName
+		compression = data.compression
+		backup = data.backup
+
+		// test
+		result, err := New(settings, crypto.New(), compress.New(), jobs.New(settings)).Verify(dbJobID, &models.Service{ID: dbID, Label: dbName, Name: data.serviceName})
+
+		// assert
+		if err != nil {
+			if !data.expectErr {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			continue
+		} else if data.expectErr {
+			t.Errorf("Expected error but got nil")
+			continue
+		}
+		test.AssertEquals(t, data.format, result.Format)
+		test.AssertEquals(t, fmt.Sprintf("%d", len(data.backup)), fmt.Sprintf("%d", result.EncryptedSize))
+	}
+
+	// test
+	serviceName = "postgresql"
+	compression = ""
+	backup = encryptTestBackup(t, postgresDump)
+	err := CmdVerify(dbName, dbJobID, New(settings, crypto.New(), compress.New(), jobs.New(settings)), services.New(settings), output.New(settings))
+
+	// assert
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
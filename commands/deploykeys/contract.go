@@ -9,6 +9,7 @@ import (
 	"github.com/daticahealth/cli/commands/services"
 	"github.com/daticahealth/cli/config"
 	"github.com/daticahealth/cli/lib/auth"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/lib/prompts"
 	"github.com/daticahealth/cli/models"
 	"github.com/jault3/mow.cli"
@@ -72,7 +73,7 @@ var ListSubCmd = models.Command{
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdList(*serviceName, New(settings), services.New(settings))
+				err := CmdList(*serviceName, New(settings), services.New(settings), output.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
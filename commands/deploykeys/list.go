@@ -8,11 +8,12 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
 
-func CmdList(svcName string, id IDeployKeys, is services.IServices) error {
+func CmdList(svcName string, id IDeployKeys, is services.IServices, out output.IOutput) error {
 	service, err := is.RetrieveByLabel(svcName)
 	if err != nil {
 		return err
@@ -28,6 +29,12 @@ func CmdList(svcName string, id IDeployKeys, is services.IServices) error {
 	if err != nil {
 		return err
 	}
+	return out.Render(keys, func() error {
+		return printDeployKeys(keys, id)
+	})
+}
+
+func printDeployKeys(keys *[]models.DeployKey, id IDeployKeys) error {
 	if keys == nil || len(*keys) == 0 {
 		logrus.Println("No deploy-keys found")
 		return nil
@@ -71,7 +78,7 @@ func CmdList(svcName string, id IDeployKeys, is services.IServices) error {
 
 func (d *SDeployKeys) List(svcID string) (*[]models.DeployKey, error) {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/ssh_keys", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Get(d.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/ssh_keys", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/test"
 )
 
@@ -38,7 +39,7 @@ func TestList(t *testing.T) {
 		t.Logf("Data: %+v", data)
 
 		// test
-		err := CmdList(data.svcName, New(settings), services.New(settings))
+		err := CmdList(data.svcName, New(settings), services.New(settings), output.New(settings))
 
 		// assert
 		if err != nil != data.expectErr {
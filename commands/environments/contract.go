@@ -2,8 +2,15 @@ package environments
 
 import (
 	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/commands/certs"
+	"github.com/daticahealth/cli/commands/files"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/commands/sites"
+	"github.com/daticahealth/cli/commands/vars"
+	"github.com/daticahealth/cli/commands/worker"
 	"github.com/daticahealth/cli/config"
 	"github.com/daticahealth/cli/lib/auth"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/lib/prompts"
 	"github.com/daticahealth/cli/models"
 	"github.com/jault3/mow.cli"
@@ -17,12 +24,44 @@ var Cmd = models.Command{
 	LongHelp:  "The <code>environments</code> command allows you to manage your environments. The environments command can not be run directly but has subcommands.",
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(cmd *cli.Cmd) {
+			cmd.CommandLong(ExportSubCmd.Name, ExportSubCmd.ShortHelp, ExportSubCmd.LongHelp, ExportSubCmd.CmdFunc(settings))
 			cmd.CommandLong(ListSubCmd.Name, ListSubCmd.ShortHelp, ListSubCmd.LongHelp, ListSubCmd.CmdFunc(settings))
 			cmd.CommandLong(RenameSubCmd.Name, RenameSubCmd.ShortHelp, RenameSubCmd.LongHelp, RenameSubCmd.CmdFunc(settings))
 		}
 	},
 }
 
+var ExportSubCmd = models.Command{
+	Name:      "export",
+	ShortHelp: "Export the configuration of every service in an environment",
+	LongHelp: "<code>environments export</code> writes a snapshot of your environment's configuration for change review, disaster recovery documentation, or audit evidence. " +
+		"The snapshot includes every service's size, scale, release version, environment variables, workers, and service file metadata along with the certs and sites on the service proxy. " +
+		"The contents of service files and the private keys of certs are never exported. " +
+		"Use <code>--mask-secrets</code> to replace the values of all environment variables. " +
+		"The snapshot is written as YAML unless the file name ends in <code>.json</code> or the global <code>--output json</code> option is given. " +
+		"When no file is given, the snapshot is printed. Here is a sample command\n\n" +
+		"<pre>\ndatica -E \"<your_env_name>\" environments export -f snapshot.yml --mask-secrets\n</pre>",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			filePath := subCmd.StringOpt("f file", "", "The file to write the snapshot to")
+			maskSecrets := subCmd.BoolOpt("m mask-secrets", false, "Replace the values of all environment variables")
+			subCmd.Action = func() {
+				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				if err := config.CheckRequiredAssociation(settings); err != nil {
+					logrus.Fatal(err.Error())
+				}
+				err := CmdExport(settings.EnvironmentID, settings.Pod, *filePath, *maskSecrets, output.New(settings), New(settings), services.New(settings), vars.New(settings), sites.New(settings), certs.New(settings), worker.New(settings), files.New(settings))
+				if err != nil {
+					logrus.Fatalln(err.Error())
+				}
+			}
+			subCmd.Spec = "[-f] [-m]"
+		}
+	},
+}
+
 var ListSubCmd = models.Command{
 	Name:      "list",
 	ShortHelp: "List all environments you have access to",
@@ -36,7 +75,7 @@ var ListSubCmd = models.Command{
 				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
 					logrus.Fatalln(err.Error())
 				}
-				err := CmdList(settings, New(settings))
+				err := CmdList(settings, New(settings), output.New(settings))
 				if err != nil {
 					logrus.Fatalln(err.Error())
 				}
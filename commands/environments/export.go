@@ -0,0 +1,210 @@
+package environments
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/commands/certs"
+	"github.com/daticahealth/cli/commands/files"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/commands/sites"
+	"github.com/daticahealth/cli/commands/vars"
+	"github.com/daticahealth/cli/commands/worker"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/daticahealth/cli/models"
+	"gopkg.in/yaml.v2"
+)
+
+const maskedValue = "********"
+
+// Snapshot is the configuration of an entire environment at a point in time
+type Snapshot struct {
+	Environment string            `json:"environment"`
+	EnvID       string            `json:"environment_id"`
+	Pod         string            `json:"pod"`
+	ExportedAt  string            `json:"exported_at"`
+	Services    []ServiceSnapshot `json:"services"`
+	Certs       []CertSnapshot    `json:"certs"`
+	Sites       []SiteSnapshot    `json:"sites"`
+}
+
+// ServiceSnapshot is the configuration of a single service
+type ServiceSnapshot struct {
+	Label          string             `json:"label"`
+	Name           string             `json:"name"`
+	Type           string             `json:"type"`
+	Size           models.ServiceSize `json:"size"`
+	Scale          int                `json:"scale"`
+	ReleaseVersion string             `json:"release_version,omitempty"`
+	Vars           map[string]string  `json:"vars"`
+	Workers        map[string]int     `json:"workers,omitempty"`
+	Files          []FileSnapshot     `json:"files,omitempty"`
+}
+
+// FileSnapshot is the metadata of a service file. The contents are never
+// exported.
+type FileSnapshot struct {
+	Name      string `json:"name"`
+	Mode      string `json:"mode"`
+	UID       int    `json:"uid"`
+	GID       int    `json:"gid"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// CertSnapshot is a cert on the service proxy
+type CertSnapshot struct {
+	Name              string `json:"name"`
+	LetsEncrypt       bool   `json:"lets_encrypt"`
+	LetsEncryptStatus string `json:"lets_encrypt_status,omitempty"`
+}
+
+// SiteSnapshot is a site on the service proxy
+type SiteSnapshot struct {
+	Name    string                 `json:"name"`
+	Service string                 `json:"service"`
+	Cert    string                 `json:"cert"`
+	Values  map[string]interface{} `json:"values"`
+}
+
+// CmdExport writes a snapshot of the configuration of every service in an
+// environment to filePath, or to stdout when filePath is empty. The snapshot is
+// JSON when the file ends in .json or the global output format is json and
+// YAML otherwise.
+func CmdExport(envID, pod, filePath string, maskSecrets bool, out output.IOutput, ie IEnvironments, is services.IServices, iv vars.IVars, isites sites.ISites, ic certs.ICerts, iw worker.IWorker, ifiles files.IFiles) error {
+	snapshot, err := TakeSnapshot(envID, pod, maskSecrets, ie, is, iv, isites, ic, iw, ifiles)
+	if err != nil {
+		return err
+	}
+	format := output.YAML
+	if out.Format() == output.JSON || strings.ToLower(filepath.Ext(filePath)) == ".json" {
+		format = output.JSON
+	}
+	b, err := marshalSnapshot(snapshot, format)
+	if err != nil {
+		return err
+	}
+	if filePath == "" {
+		logrus.Println(strings.TrimSuffix(string(b), "\n"))
+		return nil
+	}
+	if err = ioutil.WriteFile(filePath, b, 0600); err != nil {
+		return err
+	}
+	logrus.Printf("Exported the configuration of %d services in %s to %s", len(snapshot.Services), snapshot.Environment, filePath)
+	return nil
+}
+
+// TakeSnapshot gathers the configuration of every service in an environment.
+// When maskSecrets is true, the values of all environment variables are
+// replaced so the snapshot can be shared without exposing credentials.
+func TakeSnapshot(envID, pod string, maskSecrets bool, ie IEnvironments, is services.IServices, iv vars.IVars, isites sites.ISites, ic certs.ICerts, iw worker.IWorker, ifiles files.IFiles) (*Snapshot, error) {
+	env, err := ie.Retrieve(envID)
+	if err != nil {
+		return nil, err
+	}
+	svcs, err := is.ListByEnvID(envID, pod)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{
+		Environment: env.Name,
+		EnvID:       envID,
+		Pod:         pod,
+		ExportedAt:  time.Now().UTC().Format(time.RFC3339),
+		Services:    []ServiceSnapshot{},
+		Certs:       []CertSnapshot{},
+		Sites:       []SiteSnapshot{},
+	}
+	labels := map[string]string{}
+	for _, svc := range *svcs {
+		labels[svc.ID] = svc.Label
+	}
+	for _, svc := range *svcs {
+		logrus.Debugf("Exporting %s", svc.Label)
+		s := ServiceSnapshot{
+			Label:          svc.Label,
+			Name:           svc.Name,
+			Type:           svc.Type,
+			Size:           svc.Size,
+			Scale:          svc.Scale,
+			ReleaseVersion: svc.ReleaseVersion,
+		}
+		envVars, err := iv.List(svc.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to export the environment variables for %s: %s", svc.Label, err)
+		}
+		if maskSecrets {
+			for k := range envVars {
+				envVars[k] = maskedValue
+			}
+		}
+		s.Vars = envVars
+		if svc.Type == "code" {
+			workers, err := iw.Retrieve(svc.ID)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to export the workers for %s: %s", svc.Label, err)
+			}
+			s.Workers = workers.Workers
+		}
+		svcFiles, err := ifiles.List(svc.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to export the service files for %s: %s", svc.Label, err)
+		}
+		for _, f := range *svcFiles {
+			s.Files = append(s.Files, FileSnapshot{Name: f.Name, Mode: f.Mode, UID: f.UID, GID: f.GID, UpdatedAt: f.UpdatedAt})
+		}
+		snapshot.Services = append(snapshot.Services, s)
+
+		if svc.Label != "service_proxy" {
+			continue
+		}
+		proxyCerts, err := ic.List(svc.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to export the certs: %s", err)
+		}
+		for _, c := range *proxyCerts {
+			cert := CertSnapshot{Name: c.Name, LetsEncrypt: c.LetsEncrypt != models.NormalCert}
+			if cert.LetsEncrypt {
+				cert.LetsEncryptStatus = c.LetsEncrypt.String()
+			}
+			snapshot.Certs = append(snapshot.Certs, cert)
+		}
+		proxySites, err := isites.List(svc.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to export the sites: %s", err)
+		}
+		for _, listed := range *proxySites {
+			// the list does not always include site values
+			site, err := isites.Retrieve(listed.ID, svc.ID)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to export the site %s: %s", listed.Name, err)
+			}
+			upstream := labels[site.UpstreamService]
+			if upstream == "" {
+				upstream = site.UpstreamService
+			}
+			snapshot.Sites = append(snapshot.Sites, SiteSnapshot{Name: site.Name, Service: upstream, Cert: site.Cert, Values: site.SiteValues})
+		}
+	}
+	return snapshot, nil
+}
+
+// marshalSnapshot encodes a snapshot as JSON or YAML. YAML is round tripped
+// through JSON so keys match the json tags, the same as the global --output
+// option.
+func marshalSnapshot(snapshot *Snapshot, format string) ([]byte, error) {
+	b, err := json.MarshalIndent(snapshot, "", "    ")
+	if err != nil || format == output.JSON {
+		return b, err
+	}
+	var generic interface{}
+	if err = json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(generic)
+}
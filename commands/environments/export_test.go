@@ -0,0 +1,127 @@
+package environments
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daticahealth/cli/commands/certs"
+	"github.com/daticahealth/cli/commands/files"
+	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/commands/sites"
+	"github.com/daticahealth/cli/commands/vars"
+	"github.com/daticahealth/cli/commands/worker"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/daticahealth/cli/test"
+)
+
+var exportTests = []struct {
+	envID       string
+	fileName    string
+	maskSecrets bool
+	expected    []string
+	expectErr   bool
+}{
+	{test.EnvID, "snapshot.yml", false, []string{"environment: " + test.EnvName, "SECRET: hunter2", "worker: 2", "lets_encrypt: true", "service: " + test.SvcLabel, "clientMaxBodySize: 20m", "name: nginx.conf"}, false},
+	{test.EnvID, "snapshot.yml", true, []string{"SECRET: '********'"}, false},
+	{test.EnvID, "snapshot.json", true, []string{`"SECRET": "********"`, `"environment": "` + test.EnvName + `"`}, false},
+	{test.EnvIDAlt, "snapshot.yml", false, nil, true},
+}
+
+func TestExport(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	svcPath := "/environments/" + test.EnvID + "/services/"
+	mux.HandleFunc("/environments/"+test.EnvID,
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","name":"%s","namespace":"%s","organizationId":"%s"}`, test.EnvID, test.EnvName, test.Namespace, test.OrgID))
+		},
+	)
+	mux.HandleFunc("/environments/"+test.EnvID+"/services",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "GET")
+			fmt.Fprint(w, fmt.Sprintf(`[{"id":"%s","label":"%s","type":"code","size":{"ram":1,"cpu":1}},{"id":"proxy","label":"service_proxy","type":"utility"}]`, test.SvcID, test.SvcLabel))
+		},
+	)
+	mux.HandleFunc(svcPath+test.SvcID+"/env",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"SECRET":"hunter2"}`)
+		},
+	)
+	mux.HandleFunc(svcPath+test.SvcID+"/workers",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"workers":{"worker":2}}`)
+		},
+	)
+	mux.HandleFunc(svcPath+test.SvcID+"/files",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[]`)
+		},
+	)
+	mux.HandleFunc(svcPath+"proxy/env",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{}`)
+		},
+	)
+	mux.HandleFunc(svcPath+"proxy/files",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"id":1,"name":"nginx.conf","mode":"0644","contents":"do not export"}]`)
+		},
+	)
+	mux.HandleFunc(svcPath+"proxy/certs",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"name":"example.com","letsEncrypt":2}]`)
+		},
+	)
+	mux.HandleFunc(svcPath+"proxy/sites",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"id":1,"name":"example.com"}]`)
+		},
+	)
+	mux.HandleFunc(svcPath+"proxy/sites/1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":1,"name":"example.com","cert":"example.com","upstreamService":"%s","site_values":{"clientMaxBodySize":"20m"}}`, test.SvcID))
+		},
+	)
+
+	for _, data := range exportTests {
+		t.Logf("Data: %+v", data)
+		filePath := filepath.Join(dir, data.fileName)
+
+		// test
+		err := CmdExport(data.envID, test.Pod, filePath, data.maskSecrets, output.New(settings), New(settings), services.New(settings), vars.New(settings), sites.New(settings), certs.New(settings), worker.New(settings), files.New(settings))
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if data.expectErr {
+			continue
+		}
+		b, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, expected := range data.expected {
+			if !strings.Contains(string(b), expected) {
+				t.Errorf("Expected the snapshot to contain %q, got %s", expected, string(b))
+			}
+		}
+		if strings.Contains(string(b), "do not export") {
+			t.Errorf("Service file contents were exported: %s", string(b))
+		}
+	}
+}
@@ -17,7 +17,7 @@ func (e *SEnvironments) Update(envID string, updates map[string]string) error {
 		return err
 	}
 	headers := e.Settings.HTTPManager.GetHeaders(e.Settings.SessionToken, e.Settings.Version, e.Settings.Pod, e.Settings.UsersID)
-	resp, statusCode, err := e.Settings.HTTPManager.Put(b, fmt.Sprintf("%s%s/environments/%s", e.Settings.PaasHost, e.Settings.PaasHostVersion, envID), headers)
+	resp, statusCode, err := e.Settings.HTTPManager.Put(e.Settings.Context, b, fmt.Sprintf("%s%s/environments/%s", e.Settings.PaasHost, e.Settings.PaasHostVersion, envID), headers)
 	if err != nil {
 		return err
 	}
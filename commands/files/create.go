@@ -26,7 +26,7 @@ func (f *SFiles) Create(svcID, filePath, name, mode string) (*models.ServiceFile
 		return nil, err
 	}
 	headers := f.Settings.HTTPManager.GetHeaders(f.Settings.SessionToken, f.Settings.Version, f.Settings.Pod, f.Settings.UsersID)
-	resp, statusCode, err := f.Settings.HTTPManager.Post(body, fmt.Sprintf("%s%s/environments/%s/services/%s/files", f.Settings.PaasHost, f.Settings.PaasHostVersion, f.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := f.Settings.HTTPManager.Post(f.Settings.Context, body, fmt.Sprintf("%s%s/environments/%s/services/%s/files", f.Settings.PaasHost, f.Settings.PaasHostVersion, f.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return nil, err
 	}
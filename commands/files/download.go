@@ -47,7 +47,7 @@ func (f *SFiles) Retrieve(fileName string, svcID string) (*models.ServiceFile, e
 	for _, ff := range *files {
 		if ff.Name == fileName {
 			headers := f.Settings.HTTPManager.GetHeaders(f.Settings.SessionToken, f.Settings.Version, f.Settings.Pod, f.Settings.UsersID)
-			resp, statusCode, err := f.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/files/%d", f.Settings.PaasHost, f.Settings.PaasHostVersion, f.Settings.EnvironmentID, svcID, ff.ID), headers)
+			resp, statusCode, err := f.Settings.HTTPManager.Get(f.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/files/%d", f.Settings.PaasHost, f.Settings.PaasHostVersion, f.Settings.EnvironmentID, svcID, ff.ID), headers)
 			if err != nil {
 				return nil, err
 			}
@@ -29,7 +29,7 @@ func CmdAccept(inviteCode string, ii IInvites, ia auth.IAuth, ip prompts.IPrompt
 
 func (i *SInvites) Accept(inviteCode string) (string, error) {
 	headers := i.Settings.HTTPManager.GetHeaders(i.Settings.SessionToken, i.Settings.Version, i.Settings.Pod, i.Settings.UsersID)
-	resp, statusCode, err := i.Settings.HTTPManager.Post(nil, fmt.Sprintf("%s%s/orgs/accept-invite/%s", i.Settings.AuthHost, i.Settings.AuthHostVersion, inviteCode), headers)
+	resp, statusCode, err := i.Settings.HTTPManager.Post(i.Settings.Context, nil, fmt.Sprintf("%s%s/orgs/accept-invite/%s", i.Settings.AuthHost, i.Settings.AuthHostVersion, inviteCode), headers)
 	if err != nil {
 		return "", err
 	}
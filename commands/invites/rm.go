@@ -19,7 +19,7 @@ func CmdRm(inviteID string, ii IInvites) error {
 // accepted.
 func (i *SInvites) Rm(inviteID string) error {
 	headers := i.Settings.HTTPManager.GetHeaders(i.Settings.SessionToken, i.Settings.Version, i.Settings.Pod, i.Settings.UsersID)
-	resp, statusCode, err := i.Settings.HTTPManager.Delete(nil, fmt.Sprintf("%s%s/orgs/%s/invites/%s", i.Settings.AuthHost, i.Settings.AuthHostVersion, i.Settings.OrgID, inviteID), headers)
+	resp, statusCode, err := i.Settings.HTTPManager.Delete(i.Settings.Context, nil, fmt.Sprintf("%s%s/orgs/%s/invites/%s", i.Settings.AuthHost, i.Settings.AuthHostVersion, i.Settings.OrgID, inviteID), headers)
 	if err != nil {
 		return err
 	}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
@@ -25,7 +26,7 @@ func (jobs SortedJobs) Less(i, j int) bool {
 	return jobs[i].Type < jobs[j].Type
 }
 
-func CmdList(svcName string, ij IJobs, is services.IServices) error {
+func CmdList(svcName string, ij IJobs, is services.IServices, out output.IOutput) error {
 	service, err := is.RetrieveByLabel(svcName)
 	if err != nil {
 		return err
@@ -39,12 +40,20 @@ func CmdList(svcName string, ij IJobs, is services.IServices) error {
 		return err
 	}
 
+	if jbs != nil {
+		sort.Sort(SortedJobs(*jbs))
+	}
+	return out.Render(jbs, func() error {
+		return printJobs(jbs)
+	})
+}
+
+func printJobs(jbs *[]models.Job) error {
 	if jbs == nil || len(*jbs) == 0 {
 		logrus.Println("No releases found")
 		return nil
 	}
 
-	sort.Sort(SortedJobs(*jbs))
 	const dateForm = "2006-01-02T15:04:05"
 	data := [][]string{{"Job Id", "Status", "Created At", "Type", "Target"}}
 	for _, j := range *jbs {
@@ -68,7 +77,7 @@ func CmdList(svcName string, ij IJobs, is services.IServices) error {
 
 func (j *SJobs) List(svcID string) (*[]models.Job, error) {
 	headers := j.Settings.HTTPManager.GetHeaders(j.Settings.SessionToken, j.Settings.Version, j.Settings.Pod, j.Settings.UsersID)
-	resp, statusCode, err := j.Settings.HTTPManager.Get(nil,
+	resp, statusCode, err := j.Settings.HTTPManager.Get(j.Settings.Context, nil,
 		fmt.Sprintf("%s%s/environments/%s/services/%s/jobs",
 			j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
@@ -34,7 +34,7 @@ func CmdStop(jobID string, svcName string, ij IJobs, is services.IServices, forc
 func (j *SJobs) Stop(jobID string, svcID string) error {
 
 	headers := j.Settings.HTTPManager.GetHeaders(j.Settings.SessionToken, j.Settings.Version, j.Settings.Pod, j.Settings.UsersID)
-	resp, statusCode, err := j.Settings.HTTPManager.Post(nil,
+	resp, statusCode, err := j.Settings.HTTPManager.Post(j.Settings.Context, nil,
 		fmt.Sprintf("%s%s/environments/%s/services/%s/jobs/%s/stop",
 			j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID, jobID), headers)
 	if err != nil {
@@ -59,7 +59,7 @@ func (k *SKeys) Add(name, publicKey string) error {
 		return err
 	}
 	headers := k.Settings.HTTPManager.GetHeaders(k.Settings.SessionToken, k.Settings.Version, k.Settings.Pod, k.Settings.UsersID)
-	resp, status, err := k.Settings.HTTPManager.Post(body, fmt.Sprintf("%s%s/keys", k.Settings.AuthHost, k.Settings.AuthHostVersion), headers)
+	resp, status, err := k.Settings.HTTPManager.Post(k.Settings.Context, body, fmt.Sprintf("%s%s/keys", k.Settings.AuthHost, k.Settings.AuthHostVersion), headers)
 	if err != nil {
 		return err
 	}
@@ -4,6 +4,7 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/deploykeys"
 	"github.com/daticahealth/cli/lib/auth"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/lib/prompts"
 	"github.com/daticahealth/cli/models"
 	"github.com/jault3/mow.cli"
@@ -66,7 +67,7 @@ var ListSubCmd = models.Command{
 				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdList(New(settings), deploykeys.New(settings))
+				err := CmdList(New(settings), deploykeys.New(settings), output.New(settings))
 				if err != nil {
 					logrus.Fatal(err)
 				}
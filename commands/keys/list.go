@@ -8,15 +8,22 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/deploykeys"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
 
-func CmdList(ik IKeys, id deploykeys.IDeployKeys) error {
+func CmdList(ik IKeys, id deploykeys.IDeployKeys, out output.IOutput) error {
 	keys, err := ik.List()
 	if err != nil {
 		return err
 	}
+	return out.Render(keys, func() error {
+		return printKeys(keys, id)
+	})
+}
+
+func printKeys(keys *[]models.UserKey, id deploykeys.IDeployKeys) error {
 
 	if keys == nil || len(*keys) == 0 {
 		logrus.Println("No keys found")
@@ -58,7 +65,7 @@ func CmdList(ik IKeys, id deploykeys.IDeployKeys) error {
 
 func (k *SKeys) List() (*[]models.UserKey, error) {
 	headers := k.Settings.HTTPManager.GetHeaders(k.Settings.SessionToken, k.Settings.Version, k.Settings.Pod, k.Settings.UsersID)
-	resp, status, err := k.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/keys", k.Settings.AuthHost, k.Settings.AuthHostVersion), headers)
+	resp, status, err := k.Settings.HTTPManager.Get(k.Settings.Context, nil, fmt.Sprintf("%s%s/keys", k.Settings.AuthHost, k.Settings.AuthHostVersion), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +52,7 @@ func CmdRemove(name, privateKeyPath string, ik IKeys, id deploykeys.IDeployKeys)
 
 func (k *SKeys) Remove(name string) error {
 	headers := k.Settings.HTTPManager.GetHeaders(k.Settings.SessionToken, k.Settings.Version, k.Settings.Pod, k.Settings.UsersID)
-	resp, status, err := k.Settings.HTTPManager.Delete(nil, fmt.Sprintf("%s%s/keys/%s", k.Settings.AuthHost, k.Settings.AuthHostVersion, name), headers)
+	resp, status, err := k.Settings.HTTPManager.Delete(k.Settings.Context, nil, fmt.Sprintf("%s%s/keys/%s", k.Settings.AuthHost, k.Settings.AuthHostVersion, name), headers)
 	if err != nil {
 		return err
 	}
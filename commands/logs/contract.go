@@ -1,6 +1,7 @@
 package logs
 
 import (
+	"os"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -21,6 +22,8 @@ type CMDLogQuery struct {
 	Hours   int
 	Minutes int
 	Seconds int
+	Since   string
+	Until   string
 	Service string
 	JobID   string
 	Target  string
@@ -35,25 +38,40 @@ var Cmd = models.Command{
 		"If you do not see your logs, try adjusting the number of hours, minutes, or seconds of logs that are retrieved with the <code>--hours</code>, <code>--minutes</code>, and <code>--seconds</code> options respectively. " +
 		"To specify a specific service, job, or target use the '--service', '--job-id', and '--target' commands. " +
 		"You must specify a service to use '--job-id' or '--target', and you cannot specify both a job-id and a target at the same time. " +
+		"The QUERY narrows down which logs are shown. It is made of words, which may use <code>*</code> and <code>?</code> wildcards, and quoted phrases, which search the log message unless they are prefixed with a field name such as <code>host:web*</code>. " +
+		"Combine them with <code>AND</code>, <code>OR</code>, <code>NOT</code>, and parentheses. Words next to each other must all match. Searches of the log message ignore case, while other fields must match exactly. " +
+		"To see the logs from an exact window of time instead, use <code>--since</code> and <code>--until</code> with RFC3339 timestamps, such as <code>2017-10-11T02:10:00Z</code>, or durations before now, such as <code>90m</code>. " +
 		"You can also follow the logs with the <code>-f</code> option. " +
 		"When using <code>-f</code> all logs will be printed to the console within the given time frame as well as any new logs that are sent to the logging Dashboard for the duration of the command. " +
-		"When using the <code>-f</code> option, hit ctrl-c to stop. Here are some sample commands\n\n" +
+		"Use <code>--since</code> with <code>-f</code> to choose where following starts. " +
+		"When using the <code>-f</code> option, hit ctrl-c to stop. " +
+		"Use <code>--json</code> to print one JSON object per line with the <code>@timestamp</code>, <code>message</code>, <code>host</code>, and <code>source</code> of each log, or <code>--format</code> to print each log with a Go template using the fields <code>.Timestamp</code>, <code>.Message</code>, <code>.Host</code>, and <code>.Source</code>. " +
+		"With either option, only the logs are printed to stdout so the output can be piped into tools such as jq or lnav. Here are some sample commands\n\n" +
 		"<pre>\ndatica -E \"<your_env_name>\" logs --hours=6 --minutes=30\n" +
 		"datica -E \"<your_env_name>\" logs -f\n" +
+		"datica -E \"<your_env_name>\" logs --since=2017-10-11T02:10:00Z --until=2017-10-11T02:40:00Z\n" +
+		"datica -E \"<your_env_name>\" logs -f --since=2h\n" +
 		"datica -E \"<your_env_name>\" logs --service=\"<your_service_name>\"\n" +
-		"datica -E \"<your_env_name>\" logs --service=\"<your_service_name>\" --job-id=\"<your_job_id>\"\n</pre>",
+		"datica -E \"<your_env_name>\" logs --service=\"<your_service_name>\" --job-id=\"<your_job_id>\"\n" +
+		"datica -E \"<your_env_name>\" logs 'level:error AND host:web* AND NOT \"healthcheck\"'\n" +
+		"datica -E \"<your_env_name>\" logs -f --json | jq -r .message\n" +
+		"datica -E \"<your_env_name>\" logs --format '{{.Timestamp}} {{.Host}} {{.Message}}'\n</pre>",
 	// TODO: add documentation here
 	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
 		return func(cmd *cli.Cmd) {
-			query := cmd.StringArg("QUERY", "*", "The query to send to your logging dashboard's elastic search (regex is supported)")
+			query := cmd.StringArg("QUERY", "*", "The logs to show, such as 'level:error AND host:web* AND NOT \"healthcheck\"'")
 			follow := cmd.BoolOpt("f follow", false, "Tail/follow the logs (Equivalent to -t)")
 			tail := cmd.BoolOpt("t tail", false, "Tail/follow the logs (Equivalent to -f)")
 			hours := cmd.IntOpt("hours", 0, "The number of hours before now (in combination with minutes and seconds) to retrieve logs")
 			mins := cmd.IntOpt("minutes", 0, "The number of minutes before now (in combination with hours and seconds) to retrieve logs")
 			secs := cmd.IntOpt("seconds", 0, "The number of seconds before now (in combination with hours and minutes) to retrieve logs")
+			since := cmd.StringOpt("since", "", "Retrieve logs after this RFC3339 timestamp, such as 2017-10-11T02:10:00Z, or duration before now, such as 90m")
+			until := cmd.StringOpt("until", "", "Retrieve logs up to this RFC3339 timestamp or duration before now")
 			service := cmd.StringOpt("service", "", "Query logs for a specific service label")
 			jobID := cmd.StringOpt("job-id", "", "Query logs for a particular job by id")
 			target := cmd.StringOpt("target", "", "Query logs for a particular procfile target")
+			jsonFlag := cmd.BoolOpt("json", false, "Print each log as a JSON object on its own line")
+			format := cmd.StringOpt("format", "", "Print each log with a Go template, such as '{{.Timestamp}} {{.Host}} {{.Message}}'")
 			cmd.Action = func() {
 				if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
 					logrus.Fatal(err.Error())
@@ -61,44 +79,56 @@ var Cmd = models.Command{
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
+				formatter, err := NewFormatter(*jsonFlag, *format)
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+				if *jsonFlag || *format != "" {
+					// keep stdout to just the logs
+					logrus.SetOutput(os.Stderr)
+				}
 				cmdQuery := CMDLogQuery{
 					Query:   *query,
 					Follow:  *follow || *tail,
 					Hours:   *hours,
 					Minutes: *mins,
 					Seconds: *secs,
+					Since:   *since,
+					Until:   *until,
 					Service: *service,
 					JobID:   *jobID,
 					Target:  *target,
 				}
-				err := CmdLogs(&cmdQuery, settings.EnvironmentID, settings, New(settings), prompts.New(), environments.New(settings), services.New(settings), jobs.New(settings), sites.New(settings))
+				err = CmdLogs(&cmdQuery, settings.EnvironmentID, settings, New(settings, formatter), prompts.New(), environments.New(settings), services.New(settings), jobs.New(settings), sites.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
 			}
-			cmd.Spec = "[QUERY] [(-f | -t)] [--hours] [--minutes] [--seconds] [--service [(--job-id | --target)]]"
+			cmd.Spec = "[QUERY] [(-f | -t)] [--hours] [--minutes] [--seconds] [--since] [--until] [--service [(--job-id | --target)]] [(--json | --format)]"
 		}
 	},
 }
 
-type queryGenerator func(queryString, appLogsIdentifier, appLogsValue string, timestamp time.Time, from int, hostNames []string, fileName string) ([]byte, error)
+type queryGenerator func(query Query, appLogsIdentifier, appLogsValue string, timestamp, until time.Time, from int, hostNames []string, fileName string) ([]byte, error)
 
 // ILogs ...
 type ILogs interface {
-	Output(queryString, domain string, generator queryGenerator, from int, startTimestamp time.Time, endTimestamp time.Time, hostNames []string, fileName string) (int, error)
+	Output(query Query, domain string, generator queryGenerator, from int, startTimestamp time.Time, endTimestamp time.Time, hostNames []string, fileName string) (int, error)
 	RetrieveElasticsearchVersion(domain string) (string, error)
-	Stream(queryString, domain string, generator queryGenerator, from int, timestamp time.Time, hostNames []string, fileName string) error
-	Watch(queryString, domain string) error
+	Stream(query Query, domain string, generator queryGenerator, from int, timestamp time.Time, hostNames []string, fileName string) error
+	Watch(query Query, domain string) error
 }
 
 // SLogs is a concrete implementation of ILogs
 type SLogs struct {
-	Settings *models.Settings
+	Settings  *models.Settings
+	Formatter Formatter
 }
 
-// New returns an instance of ILogs
-func New(settings *models.Settings) ILogs {
+// New returns an instance of ILogs that prints with the given Formatter
+func New(settings *models.Settings, formatter Formatter) ILogs {
 	return &SLogs{
-		Settings: settings,
+		Settings:  settings,
+		Formatter: formatter,
 	}
 }
@@ -1,9 +1,7 @@
 package logs
 
 import (
-	"bytes"
 	"encoding/json"
-	"fmt"
 	"strings"
 	"time"
 )
@@ -18,146 +16,72 @@ func chooseQueryGenerator(version string) queryGenerator {
 	return generator
 }
 
-func generateES5Query(queryString, appLogsIdentifier, appLogsValue string, timestamp time.Time, from int, hostNames []string, fileName string) ([]byte, error) {
-	hostFilter, fileFilter := createFilters(hostNames, fileName)
-	query := `{
-	"_source": ["@timestamp", "message", "` + appLogsIdentifier + `"],
-	"query": {
-		"bool": {
-			"must": [
-				{"wildcard": {"message": "` + queryString + `"}},
-				{"term": {"` + appLogsIdentifier + `": "` + appLogsValue + `"}},` + fileFilter + `
-				{"range": {"@timestamp": {"gt": "` + fmt.Sprintf("%04d-%02d-%02dT%02d:%02d:%02dZ", timestamp.Year(), timestamp.Month(), timestamp.Day(), timestamp.Hour(), timestamp.Minute(), timestamp.Second()) + `"}}}
-			]` + hostFilter + `
-		}
-	},
-	"sort": [
-		{
-			"@timestamp": {
-				"order": "asc",
-				"unmapped_type":"boolean"
-			}
+func generateES5Query(query Query, appLogsIdentifier, appLogsValue string, timestamp, until time.Time, from int, hostNames []string, fileName string) ([]byte, error) {
+	filter := createFilter(appLogsIdentifier, appLogsValue, timestamp, until, hostNames, fileName)
+	// the user's query is the first clause, before the filters
+	filter["must"] = append([]esQuery{query.toES()}, filter["must"].([]esQuery)...)
+	return json.Marshal(esQuery{
+		"_source": []string{"@timestamp", "message", "host", appLogsIdentifier},
+		"query":   esQuery{"bool": filter},
+		"sort": []esQuery{
+			{"@timestamp": esQuery{"order": "asc", "unmapped_type": "boolean"}},
+			{"message.raw": esQuery{"order": "asc", "unmapped_type": "boolean"}},
 		},
-		{
-			"message.raw": {
-				"order": "asc",
-				"unmapped_type":"boolean"
-			}
-		}
-	],
-	"from": ` + fmt.Sprintf("%d", from) + `,
-	"size": ` + fmt.Sprintf("%d", size) + `
-	}`
-	var buf bytes.Buffer
-	err := json.Compact(&buf, []byte(query))
-	if err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
+		"from": from,
+		"size": size,
+	})
 }
 
-func generateES2Query(queryString, appLogsIdentifier, appLogsValue string, timestamp time.Time, from int, hostNames []string, fileName string) ([]byte, error) {
-	hostFilter, fileFilter := createFilters(hostNames, fileName)
-	query := `{
-	"fields": ["@timestamp", "message", "` + appLogsIdentifier + `"],
-	"query": {
-		"wildcard": {
-			"message": "` + queryString + `"
-		}
-	},
-	"filter": {
-		"query": {
-			"bool": {
-				"must": [
-					{"term": {"` + appLogsIdentifier + `": "` + appLogsValue + `"}},
-					` + fileFilter + `
-					{"range": {"@timestamp": {"gt": "` + fmt.Sprintf("%04d-%02d-%02dT%02d:%02d:%02dZ", timestamp.Year(), timestamp.Month(), timestamp.Day(), timestamp.Hour(), timestamp.Minute(), timestamp.Second()) + `"}}}
-				]` + hostFilter + `
-			}
-		}
-	},
-	"sort": [
-		{
-			"@timestamp": {
-				"order": "asc",
-				"unmapped_type":"boolean"
-			}
+func generateES2Query(query Query, appLogsIdentifier, appLogsValue string, timestamp, until time.Time, from int, hostNames []string, fileName string) ([]byte, error) {
+	return json.Marshal(esQuery{
+		"fields": []string{"@timestamp", "message", "host", appLogsIdentifier},
+		"query":  query.toES(),
+		"filter": esQuery{"query": esQuery{"bool": createFilter(appLogsIdentifier, appLogsValue, timestamp, until, hostNames, fileName)}},
+		"sort": []esQuery{
+			{"@timestamp": esQuery{"order": "asc", "unmapped_type": "boolean"}},
+			{"message.raw": esQuery{"order": "asc", "unmapped_type": "boolean"}},
 		},
-		{
-			"message.raw": {
-				"order": "asc",
-				"unmapped_type":"boolean"
-			}
-		}
-	],
-	"from": ` + fmt.Sprintf("%d", from) + `,
-	"size": ` + fmt.Sprintf("%d", size) + `
-	}`
-	var buf bytes.Buffer
-	err := json.Compact(&buf, []byte(query))
-	if err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
+		"from": from,
+		"size": size,
+	})
 }
 
-func generateES1Query(queryString, appLogsIdentifier, appLogsValue string, timestamp time.Time, from int, hostNames []string, fileName string) ([]byte, error) {
-	hostFilter, fileFilter := createFilters(hostNames, fileName)
-	query := `{
-	"fields": ["@timestamp", "message", "` + appLogsIdentifier + `"],
-	"query": {
-		"wildcard": {
-			"message": "` + queryString + `"
-		}
-	},
-	"filter": {
-		"query": {
-			"bool": {
-				"must": [
-					{"term": {"` + appLogsIdentifier + `": "` + appLogsValue + `"}},
-					` + fileFilter + `
-					{"range": {"@timestamp": {"gt": "` + fmt.Sprintf("%04d-%02d-%02dT%02d:%02d:%02dZ", timestamp.Year(), timestamp.Month(), timestamp.Day(), timestamp.Hour(), timestamp.Minute(), timestamp.Second()) + `"}}}
-				]` + hostFilter + `
-			}
-		}
-	},
-	"sort": {
-		"@timestamp": {
-			"order": "asc"
+func generateES1Query(query Query, appLogsIdentifier, appLogsValue string, timestamp, until time.Time, from int, hostNames []string, fileName string) ([]byte, error) {
+	return json.Marshal(esQuery{
+		"fields": []string{"@timestamp", "message", "host", appLogsIdentifier},
+		"query":  query.toES(),
+		"filter": esQuery{"query": esQuery{"bool": createFilter(appLogsIdentifier, appLogsValue, timestamp, until, hostNames, fileName)}},
+		// keys are marshalled in sorted order, so @timestamp is sorted on first
+		"sort": esQuery{
+			"@timestamp": esQuery{"order": "asc"},
+			"message":    esQuery{"order": "asc"},
 		},
-		"message": {
-			"order": "asc"
-		}
-	},
-	"from": ` + fmt.Sprintf("%d", from) + `,
-	"size": ` + fmt.Sprintf("%d", size) + `
-	}`
-	var buf bytes.Buffer
-	json.Compact(&buf, []byte(query))
-	return buf.Bytes(), nil
+		"from": from,
+		"size": size,
+	})
 }
 
-func createFilters(hostNames []string, fileName string) (string, string) {
-	var hostFilter string
-	var fileFilter string
+// createFilter returns the body of the bool query that limits results to the
+// app logs after the timestamp and up to until, if it is set, for any of the
+// given hosts or the given file
+func createFilter(appLogsIdentifier, appLogsValue string, timestamp, until time.Time, hostNames []string, fileName string) esQuery {
+	must := []esQuery{{"term": esQuery{appLogsIdentifier: appLogsValue}}}
+	if len(hostNames) == 0 && len(fileName) > 0 {
+		must = append(must, esQuery{"match_phrase": esQuery{"file": fileName}})
+	}
+	timeRange := esQuery{"gt": timestamp.UTC().Format("2006-01-02T15:04:05Z")}
+	if !until.IsZero() {
+		timeRange["lte"] = until.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	must = append(must, esQuery{"range": esQuery{"@timestamp": timeRange}})
+	filter := esQuery{"must": must}
 	if len(hostNames) > 0 {
-		formattedHostNames := make([]string, len(hostNames))
+		should := make([]esQuery, len(hostNames))
 		for i, hostName := range hostNames {
-			formattedHostNames[i] = fmt.Sprintf(`"%s"`, hostName)
-		}
-		hostFilter = `,
-			"should": [`
-		for _, hostName := range hostNames {
-			hostFilter += `
-				{"match_phrase": {"host": "` + hostName + `"}},`
+			should[i] = esQuery{"match_phrase": esQuery{"host": hostName}}
 		}
-		hostFilter = strings.TrimSuffix(hostFilter, ",")
-		hostFilter += `
-			],
-			"minimum_should_match": 1`
-	} else if len(fileName) > 0 {
-		fileFilter += `
-		{"match_phrase": {"file": "` + fileName + `"}},`
+		filter["should"] = should
+		filter["minimum_should_match"] = 1
 	}
-	return hostFilter, fileFilter
+	return filter
 }
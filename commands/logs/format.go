@@ -0,0 +1,93 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// stdout is swapped out in tests
+var stdout io.Writer = os.Stdout
+
+// LogEntry is a single log line as it is printed. The fields are available to
+// --format templates.
+type LogEntry struct {
+	Timestamp string `json:"@timestamp"`
+	Message   string `json:"message"`
+	Host      string `json:"host,omitempty"`
+	Source    string `json:"source,omitempty"`
+}
+
+// Formatter specifies that all concrete implementations should be able to
+// print a single log entry and any heading that goes before the entries.
+type Formatter interface {
+	Header()
+	Print(entry LogEntry) error
+}
+
+// TextFormatter prints "timestamp - message" lines for reading in a terminal
+type TextFormatter struct{}
+
+func (f *TextFormatter) Header() {
+	logrus.Println("        @timestamp       -        message")
+}
+
+func (f *TextFormatter) Print(entry LogEntry) error {
+	logrus.Printf("%s - %s", entry.Timestamp, entry.Message)
+	return nil
+}
+
+// JSONFormatter prints one JSON object per line for tools such as jq or lnav
+type JSONFormatter struct {
+	Writer io.Writer
+}
+
+func (f *JSONFormatter) Header() {}
+
+func (f *JSONFormatter) Print(entry LogEntry) error {
+	return json.NewEncoder(f.Writer).Encode(entry)
+}
+
+// TemplateFormatter prints each entry with a Go template given by --format
+type TemplateFormatter struct {
+	Template *template.Template
+	Writer   io.Writer
+}
+
+func (f *TemplateFormatter) Header() {}
+
+func (f *TemplateFormatter) Print(entry LogEntry) error {
+	return f.Template.Execute(f.Writer, entry)
+}
+
+// NewFormatter returns the Formatter for the --json and --format options. Text
+// is used when neither is given.
+func NewFormatter(jsonFlag bool, format string) (Formatter, error) {
+	if jsonFlag && format != "" {
+		return nil, fmt.Errorf("Specifying \"--json\" in combination with \"--format\" is unsupported.")
+	}
+	if jsonFlag {
+		return &JSONFormatter{Writer: stdout}, nil
+	}
+	if format != "" {
+		if !strings.HasSuffix(format, "\n") {
+			format += "\n"
+		}
+		tmpl, err := template.New("format").Parse(format)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid --format template: %s", err)
+		}
+		// catch fields that do not exist before any logs are retrieved
+		if err = tmpl.Execute(ioutil.Discard, LogEntry{}); err != nil {
+			return nil, fmt.Errorf("Invalid --format template: %s", err)
+		}
+		return &TemplateFormatter{Template: tmpl, Writer: stdout}, nil
+	}
+	return &TextFormatter{}, nil
+}
@@ -44,6 +44,37 @@ func CmdLogs(query *CMDLogQuery, envID string, settings *models.Settings, il ILo
 	if len(query.Target) > 0 && len(query.Service) == 0 {
 		return fmt.Errorf("You must specify a code service to query the logs for a particular target")
 	}
+	if len(query.Since) > 0 && (query.Hours > 0 || query.Minutes > 0 || query.Seconds > 0) {
+		return fmt.Errorf("Specifying \"--since\" in combination with \"--hours\", \"--minutes\", or \"--seconds\" is unsupported.")
+	}
+	if query.Follow && len(query.Until) > 0 {
+		return fmt.Errorf("Specifying \"-f\" in combination with \"--until\" is unsupported.")
+	}
+	now := time.Now().In(time.UTC)
+	offset := time.Duration(query.Hours)*time.Hour + time.Duration(query.Minutes)*time.Minute + time.Duration(query.Seconds)*time.Second
+	timestamp := now.Add(-1 * offset)
+	if len(query.Since) > 0 {
+		since, err := parseLogTime("--since", query.Since, now)
+		if err != nil {
+			return err
+		}
+		timestamp = since
+	}
+	endTimestamp := now
+	if len(query.Until) > 0 {
+		until, err := parseLogTime("--until", query.Until, now)
+		if err != nil {
+			return err
+		}
+		if !until.After(timestamp) {
+			return fmt.Errorf("The \"--until\" time %s must be after the start time %s.", until.Format(time.RFC3339), timestamp.Format(time.RFC3339))
+		}
+		endTimestamp = until
+	}
+	parsedQuery, err := ParseQuery(query.Query)
+	if err != nil {
+		return err
+	}
 	var hostNames []string
 	var fileName string
 	isServiceQuery := false
@@ -166,29 +197,29 @@ func CmdLogs(query *CMDLogQuery, envID string, settings *models.Settings, il ILo
 		version = ""
 	}
 	generator := chooseQueryGenerator(version)
-	if query.Follow && !isServiceQuery {
-		if err = il.Watch(query.Query, domain); err != nil {
+	// the websocket only streams new logs, so it can not start from --since,
+	// and its logs only carry some fields, so other fields are polled for
+	if query.Follow && !isServiceQuery && len(query.Since) == 0 && parsedQuery.watchable() {
+		if err = il.Watch(parsedQuery, domain); err != nil {
 			logrus.Debugf("Error attempting to stream logs from logwatch: %s", err)
 		} else {
 			return nil
 		}
 	}
 	from := 0
-	offset := time.Duration(query.Hours)*time.Hour + time.Duration(query.Minutes)*time.Minute + time.Duration(query.Seconds)*time.Second
-	timestamp := time.Now().In(time.UTC).Add(-1 * offset)
-	from, err = il.Output(query.Query, domain, generator, from, timestamp, time.Now(), hostNames, fileName)
+	from, err = il.Output(parsedQuery, domain, generator, from, timestamp, endTimestamp, hostNames, fileName)
 	if err != nil {
 		return err
 	}
 	if query.Follow {
-		return il.Stream(query.Query, domain, generator, from, timestamp, hostNames, fileName)
+		return il.Stream(parsedQuery, domain, generator, from, timestamp, hostNames, fileName)
 	}
 	return nil
 }
 
 func (l *SLogs) RetrieveElasticsearchVersion(domain string) (string, error) {
 	headers := map[string][]string{"Cookie": {"sessionToken=" + url.QueryEscape(l.Settings.SessionToken)}}
-	resp, statusCode, err := l.Settings.HTTPManager.Get(nil, fmt.Sprintf("https://%s/__es/", domain), headers)
+	resp, statusCode, err := l.Settings.HTTPManager.Get(l.Settings.Context, nil, fmt.Sprintf("https://%s/__es/", domain), headers)
 	if err != nil {
 		return "", err
 	}
@@ -202,7 +233,7 @@ func (l *SLogs) RetrieveElasticsearchVersion(domain string) (string, error) {
 	return wrapper.Version.Number, nil
 }
 
-func (l *SLogs) Output(queryString, domain string, generator queryGenerator, from int, startTimestamp, endTimestamp time.Time, hostNames []string, fileName string) (int, error) {
+func (l *SLogs) Output(query Query, domain string, generator queryGenerator, from int, startTimestamp, endTimestamp time.Time, hostNames []string, fileName string) (int, error) {
 	appLogsIdentifier := "source"
 	appLogsValue := "app"
 	if strings.HasPrefix(domain, "csb01") {
@@ -214,16 +245,16 @@ func (l *SLogs) Output(queryString, domain string, generator queryGenerator, fro
 
 	headers := map[string][]string{"Cookie": {"sessionToken=" + url.QueryEscape(l.Settings.SessionToken)}}
 
-	logrus.Println("        @timestamp       -        message")
+	l.Formatter.Header()
 	for {
-		queryBytes, err := generator(queryString, appLogsIdentifier, appLogsValue, startTimestamp, from, hostNames, fileName)
+		queryBytes, err := generator(query, appLogsIdentifier, appLogsValue, startTimestamp, endTimestamp, from, hostNames, fileName)
 		if err != nil {
 			return -1, fmt.Errorf("Error generating query: %s", err)
 		} else if queryBytes == nil || len(queryBytes) == 0 {
 			return -1, errors.New("Error generating query")
 		}
 
-		resp, statusCode, err := l.Settings.HTTPManager.Get(queryBytes, fmt.Sprintf("%s/_search", urlString), headers)
+		resp, statusCode, err := l.Settings.HTTPManager.Get(l.Settings.Context, queryBytes, fmt.Sprintf("%s/_search", urlString), headers)
 		if err != nil {
 			return from, err
 		}
@@ -235,10 +266,12 @@ func (l *SLogs) Output(queryString, domain string, generator queryGenerator, fro
 
 		end := time.Time{}
 		for _, lh := range *logs.Hits.Hits {
-			timestamp, message := getLogData(lh)
-			if len(timestamp) != 0 && len(message) != 0 { // QUESTION: Do we care if the timestamp is missing? Would that ever happen?
-				logrus.Printf("%s - %s", timestamp, message)
-				end, _ = time.Parse(time.RFC3339Nano, timestamp)
+			entry := getLogData(lh, appLogsIdentifier)
+			if len(entry.Timestamp) != 0 && len(entry.Message) != 0 { // QUESTION: Do we care if the timestamp is missing? Would that ever happen?
+				if err = l.Formatter.Print(entry); err != nil {
+					return from, err
+				}
+				end, _ = time.Parse(time.RFC3339Nano, entry.Timestamp)
 			}
 		}
 		amount := len(*logs.Hits.Hits)
@@ -249,19 +282,31 @@ func (l *SLogs) Output(queryString, domain string, generator queryGenerator, fro
 		if amount < size || end.After(endTimestamp) {
 			break
 		}
-		time.Sleep(config.JobPollTime * time.Second)
+		select {
+		case <-l.Settings.Context.Done():
+			return from, l.Settings.Context.Err()
+		case <-time.After(config.JobPollTime * time.Second):
+		}
 	}
 	return from, nil
 }
 
-func (l *SLogs) Stream(queryString, domain string, generator queryGenerator, from int, timestamp time.Time, hostNames []string, fileName string) error {
+func (l *SLogs) Stream(query Query, domain string, generator queryGenerator, from int, timestamp time.Time, hostNames []string, fileName string) error {
 	for {
-		f, err := l.Output(queryString, domain, generator, from, timestamp, time.Now(), hostNames, fileName)
+		f, err := l.Output(query, domain, generator, from, timestamp, time.Now(), hostNames, fileName)
+		if l.Settings.Context.Err() != nil {
+			// streaming stops when interrupted or timed out
+			return nil
+		}
 		if err != nil {
 			return err
 		}
 		from = f
-		time.Sleep(config.LogPollTime * time.Second)
+		select {
+		case <-l.Settings.Context.Done():
+			return nil
+		case <-time.After(config.LogPollTime * time.Second):
+		}
 	}
 }
 
@@ -277,11 +322,29 @@ func buildHostNames(jobs []models.Job, serviceLabel string) []string {
 	return hostNames
 }
 
-func getLogData(lh models.LogHits) (string, string) {
-	timestamp, tsOk := lh.Fields["@timestamp"]
-	message, msgOk := lh.Fields["message"]
-	if tsOk && msgOk {
-		return timestamp[0], message[0]
+// parseLogTime parses the value of --since or --until, which is either an
+// RFC3339 timestamp or a duration before now
+func parseLogTime(option, value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(time.UTC), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-1 * d), nil
+	}
+	return time.Time{}, fmt.Errorf("Invalid \"%s\" value \"%s\". Use an RFC3339 timestamp such as 2017-10-11T02:10:00Z or a duration before now such as 90m.", option, value)
+}
+
+func getLogData(lh models.LogHits, appLogsIdentifier string) LogEntry {
+	field := func(name string) string {
+		if values, ok := lh.Fields[name]; ok && len(values) > 0 {
+			return values[0]
+		}
+		return lh.Source[name]
+	}
+	return LogEntry{
+		Timestamp: field("@timestamp"),
+		Message:   field("message"),
+		Host:      field("host"),
+		Source:    field(appLogsIdentifier),
 	}
-	return lh.Source["@timestamp"], lh.Source["message"]
 }
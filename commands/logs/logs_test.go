@@ -1,6 +1,7 @@
 package logs
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/http"
@@ -20,13 +21,18 @@ import (
 
 type SLogsMock struct {
 	Settings *models.Settings
+	// the time window of the last Output call
+	Start time.Time
+	End   time.Time
+	// whether Watch was called
+	Watched bool
 }
 
 func (l *SLogsMock) RetrieveElasticsearchVersion(domain string) (string, error) {
 	return "5", nil
 }
 
-func (l *SLogsMock) Output(queryString, domain string, generator queryGenerator, from int, startTimestamp, endTimestamp time.Time, hostNames []string, fileName string) (int, error) {
+func (l *SLogsMock) Output(query Query, domain string, generator queryGenerator, from int, startTimestamp, endTimestamp time.Time, hostNames []string, fileName string) (int, error) {
 	appLogsIdentifier := "source"
 	appLogsValue := "app"
 	if strings.HasPrefix(domain, "csb01") {
@@ -34,9 +40,10 @@ func (l *SLogsMock) Output(queryString, domain string, generator queryGenerator,
 		appLogsValue = "supervisord"
 	}
 
+	l.Start, l.End = startTimestamp, endTimestamp
 	logrus.Println("        @timestamp       -        message")
 	for {
-		queryBytes, err := generator(queryString, appLogsIdentifier, appLogsValue, startTimestamp, from, hostNames, fileName)
+		queryBytes, err := generator(query, appLogsIdentifier, appLogsValue, startTimestamp, endTimestamp, from, hostNames, fileName)
 		if err != nil {
 			return -1, fmt.Errorf("Error generating query: %s", err)
 		} else if queryBytes == nil || len(queryBytes) == 0 {
@@ -80,10 +87,10 @@ func (l *SLogsMock) Output(queryString, domain string, generator queryGenerator,
 	return from, nil
 }
 
-func (l *SLogsMock) Stream(queryString, domain string, generator queryGenerator, from int, timestamp time.Time, hostNames []string, fileName string) error {
+func (l *SLogsMock) Stream(query Query, domain string, generator queryGenerator, from int, timestamp time.Time, hostNames []string, fileName string) error {
 	//Don't want to run stream forever in test
 	for i := 0; i < 2; i++ {
-		f, err := l.Output(queryString, domain, generator, from, timestamp, time.Now(), hostNames, fileName)
+		f, err := l.Output(query, domain, generator, from, timestamp, time.Now(), hostNames, fileName)
 		if err != nil {
 			return err
 		}
@@ -93,7 +100,8 @@ func (l *SLogsMock) Stream(queryString, domain string, generator queryGenerator,
 	return nil
 }
 
-func (l *SLogsMock) Watch(queryString, domain string) error {
+func (l *SLogsMock) Watch(query Query, domain string) error {
+	l.Watched = true
 	//TODO: Mock it better?
 	return errors.New("Run Stream")
 }
@@ -243,6 +251,43 @@ func TestLogsStream(t *testing.T) {
 	}
 }
 
+var logsWatchTests = []struct {
+	query   string
+	watched bool
+}{
+	{"error AND host:web*", true},
+	// the websocket does not carry the level, so the logs are polled for
+	{"level:error AND host:web*", false},
+}
+
+func TestLogsWatchFields(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	muxSetup(mux, t, "code", []string{test.GoodDate}, &CMDLogQuery{})
+	for _, data := range logsWatchTests {
+		t.Logf("Data: %+v", data)
+		cmdQuery := CMDLogQuery{
+			Query:  data.query,
+			Follow: true,
+		}
+		ilogs := &SLogsMock{
+			Settings: settings,
+		}
+
+		// test
+		err := CmdLogs(&cmdQuery, settings.EnvironmentID, settings, ilogs, &test.FakePrompts{}, environments.New(settings), services.New(settings), jobs.New(settings), sites.New(settings))
+
+		// assert
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if ilogs.Watched != data.watched {
+			t.Errorf("Expected %s to be watched: %t, actual: %t", data.query, data.watched, ilogs.Watched)
+		}
+	}
+}
+
 func TestLogsBadService(t *testing.T) {
 	mux, server, baseURL := test.Setup()
 	defer test.Teardown(server)
@@ -452,3 +497,132 @@ func TestLogsOneValidHostName(t *testing.T) {
 		t.Fatalf("Unexpected error: %s", err)
 	}
 }
+
+var logFormatTests = []struct {
+	jsonFlag  bool
+	format    string
+	expected  string
+	expectErr bool
+}{
+	{true, "", `{"@timestamp":"2017-10-11T15:04:00Z","message":"Wow so log","host":"web-abc123","source":"app"}` + "\n" + `{"@timestamp":"2017-10-11T15:04:01Z","message":"Wow so \"quoted\" log","host":"web-abc123","source":"app"}` + "\n", false},
+	{false, "{{.Timestamp}} {{.Host}} {{.Message}}", "2017-10-11T15:04:00Z web-abc123 Wow so log\n2017-10-11T15:04:01Z web-abc123 Wow so \"quoted\" log\n", false},
+	{false, "{{.Source}}: {{.Message}}\n", "app: Wow so log\napp: Wow so \"quoted\" log\n", false},
+	{true, "{{.Message}}", "", true},
+	{false, "{{.Message", "", true},
+	{false, "{{.Level}} {{.Message}}", "", true},
+}
+
+func TestLogsFormat(t *testing.T) {
+	var buf bytes.Buffer
+	oldStdout := stdout
+	stdout = &buf
+	defer func() { stdout = oldStdout }()
+	hits := []models.LogHits{
+		// elasticsearch 5 returns _source
+		{Source: map[string]string{"@timestamp": "2017-10-11T15:04:00Z", "message": "Wow so log", "host": "web-abc123", "source": "app"}},
+		// elasticsearch 1 and 2 return fields
+		{Fields: map[string][]string{"@timestamp": {"2017-10-11T15:04:01Z"}, "message": {`Wow so "quoted" log`}, "host": {"web-abc123"}, "source": {"app"}}},
+	}
+	for _, data := range logFormatTests {
+		t.Logf("Data: %+v", data)
+		buf.Reset()
+
+		// test
+		formatter, err := NewFormatter(data.jsonFlag, data.format)
+		if err == nil {
+			formatter.Header()
+			for _, lh := range hits {
+				if err = formatter.Print(getLogData(lh, "source")); err != nil {
+					break
+				}
+			}
+		}
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		test.AssertEquals(t, data.expected, buf.String())
+	}
+}
+
+var logsTimeRangeTests = []struct {
+	since     string
+	until     string
+	hours     int
+	follow    bool
+	start     string
+	end       string
+	expectErr bool
+}{
+	{"2017-10-11T02:10:00Z", "2017-10-11T02:40:00Z", 0, false, "2017-10-11T02:10:00Z", "2017-10-11T02:40:00Z", false},
+	{"2017-10-11T04:10:00+02:00", "2017-10-11T02:40:00Z", 0, false, "2017-10-11T02:10:00Z", "2017-10-11T02:40:00Z", false},
+	{"2017-10-11T02:40:00Z", "2017-10-11T02:10:00Z", 0, false, "", "", true},
+	{"2017-10-11T02:10:00Z", "", 6, false, "", "", true},
+	{"", "2017-10-11T02:40:00Z", 0, true, "", "", true},
+	{"yesterday", "", 0, false, "", "", true},
+	{"-5m", "", 0, false, "", "", true},
+	{"", "not a time", 0, false, "", "", true},
+}
+
+func TestLogsTimeRange(t *testing.T) {
+	for _, data := range logsTimeRangeTests {
+		t.Logf("Data: %+v", data)
+		mux, server, baseURL := test.Setup()
+		settings := test.GetSettings(baseURL.String())
+		cmdQuery := CMDLogQuery{
+			Query:   "",
+			Follow:  data.follow,
+			Hours:   data.hours,
+			Since:   data.since,
+			Until:   data.until,
+			Service: test.SvcLabel,
+		}
+		muxSetup(mux, t, "code", []string{test.GoodDate}, &cmdQuery)
+		ilogs := &SLogsMock{
+			Settings: settings,
+		}
+
+		// test
+		err := CmdLogs(&cmdQuery, settings.EnvironmentID, settings, ilogs, &test.FakePrompts{}, environments.New(settings), services.New(settings), jobs.New(settings), sites.New(settings))
+		test.Teardown(server)
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if data.expectErr {
+			continue
+		}
+		test.AssertEquals(t, data.start, ilogs.Start.Format(time.RFC3339))
+		test.AssertEquals(t, data.end, ilogs.End.Format(time.RFC3339))
+	}
+}
+
+func TestLogsSinceDuration(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	cmdQuery := CMDLogQuery{
+		Query:   "",
+		Since:   "90m",
+		Service: test.SvcLabel,
+	}
+	muxSetup(mux, t, "code", []string{test.GoodDate}, &cmdQuery)
+	ilogs := &SLogsMock{
+		Settings: settings,
+	}
+
+	// test
+	err := CmdLogs(&cmdQuery, settings.EnvironmentID, settings, ilogs, &test.FakePrompts{}, environments.New(settings), services.New(settings), jobs.New(settings), sites.New(settings))
+
+	// assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if window := ilogs.End.Sub(ilogs.Start); window != 90*time.Minute {
+		t.Errorf("Expected logs from the last 90m but got a window of %s", window)
+	}
+}
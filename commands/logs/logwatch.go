@@ -8,7 +8,6 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
-	"regexp"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -23,16 +22,10 @@ type LogMessage struct {
 	Message   string `json:"message"`
 	Timestamp string `json:"@timestamp"`
 	Source    string `json:"source"`
+	Host      string `json:"host"`
 }
 
-func (l *SLogs) Watch(queryString, domain string) error {
-	if queryString == "*" {
-		queryString = ""
-	}
-	query, err := regexp.Compile(queryString)
-	if err != nil {
-		return err
-	}
+func (l *SLogs) Watch(query Query, domain string) error {
 	logrus.Println("Streaming logs...")
 	dialer := &websocket.Dialer{
 		Proxy: http.ProxyFromEnvironment,
@@ -56,14 +49,14 @@ func (l *SLogs) Watch(queryString, domain string) error {
 		<-interrupt
 		done <- struct{}{}
 	}()
-	go readWS(c, query, done)
+	go readWS(c, query, l.Formatter, done)
 	<-done
 	logrus.Println("Disconnected")
 	return nil
 }
 
-// Reads incoming data from the websocket and forwards it to stdout.
-func readWS(ws *websocket.Conn, query *regexp.Regexp, done chan struct{}) {
+// Reads incoming data from the websocket and prints it with the formatter.
+func readWS(ws *websocket.Conn, query Query, formatter Formatter, done chan struct{}) {
 	defer func() {
 		done <- struct{}{}
 	}()
@@ -79,8 +72,11 @@ func readWS(ws *websocket.Conn, query *regexp.Regexp, done chan struct{}) {
 		var log LogMessage
 		err = json.Unmarshal(msg, &log)
 		if err == nil {
-			if query == nil || query.MatchString(log.Message) {
-				logrus.Printf("%s - %s", log.Timestamp, log.Message)
+			entry := LogEntry{Timestamp: log.Timestamp, Message: log.Message, Host: log.Host, Source: log.Source}
+			if query.Matches(entry) {
+				if err = formatter.Print(entry); err != nil {
+					logrus.Debugf("Error printing a streamed log: %s", err)
+				}
 			}
 		} else {
 			logrus.StandardLogger().Out.Write(msg)
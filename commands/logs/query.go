@@ -0,0 +1,399 @@
+package logs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// defaultQueryField is the field searched by terms without a "field:" prefix
+const defaultQueryField = "message"
+
+// esQuery is a piece of the Elasticsearch query DSL. It is marshalled to JSON
+// so user input is always escaped.
+type esQuery map[string]interface{}
+
+// Query is a parsed log query such as
+//
+//	level:error AND host:web* AND NOT "healthcheck"
+//
+// Terms are words, which may use * and ? wildcards, or quoted phrases, with
+// an optional field prefix. Terms are combined with AND, OR, NOT and
+// parentheses, and terms next to each other must both match. Terms in the
+// message ignore case, while other fields must match exactly.
+type Query interface {
+	// toES returns the query in the Elasticsearch query DSL, which is the same
+	// for every supported version
+	toES() esQuery
+	// Matches reports whether a log streamed over the websocket matches the
+	// query
+	Matches(entry LogEntry) bool
+	// watchable reports whether the query only uses fields that logs
+	// streamed over the websocket carry, so Matches can be trusted
+	watchable() bool
+}
+
+// watchFields are the fields of a LogEntry streamed over the websocket
+var watchFields = map[string]bool{"@timestamp": true, "message": true, "host": true, "source": true}
+
+type matchAllQuery struct{}
+
+func (q *matchAllQuery) toES() esQuery {
+	return esQuery{"match_all": esQuery{}}
+}
+
+func (q *matchAllQuery) Matches(entry LogEntry) bool {
+	return true
+}
+
+func (q *matchAllQuery) watchable() bool {
+	return true
+}
+
+type termQuery struct {
+	field  string
+	value  string
+	phrase bool
+}
+
+func (q *termQuery) toES() esQuery {
+	if q.phrase {
+		return esQuery{"match_phrase": esQuery{q.field: q.value}}
+	}
+	return esQuery{"wildcard": esQuery{q.field: q.value}}
+}
+
+func (q *termQuery) Matches(entry LogEntry) bool {
+	var value string
+	switch q.field {
+	case "@timestamp":
+		value = entry.Timestamp
+	case "message":
+		value = entry.Message
+	case "host":
+		value = entry.Host
+	case "source":
+		value = entry.Source
+	default:
+		return false
+	}
+	// the message is analyzed by Elasticsearch, so a term matches any part of
+	// it regardless of case, while other fields must match entirely
+	pattern := regexp.QuoteMeta(q.value)
+	if !q.phrase {
+		pattern = wildcardPattern(q.value)
+	}
+	if q.field == defaultQueryField {
+		pattern = "(?i)" + pattern
+	} else {
+		pattern = "^" + pattern + "$"
+	}
+	return regexp.MustCompile(pattern).MatchString(value)
+}
+
+func (q *termQuery) watchable() bool {
+	return watchFields[q.field]
+}
+
+// wildcardPattern converts an Elasticsearch wildcard value to a regular
+// expression
+func wildcardPattern(value string) string {
+	pattern := ""
+	runes := []rune(value)
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes):
+			i++
+			pattern += regexp.QuoteMeta(string(runes[i]))
+		case runes[i] == '*':
+			pattern += ".*"
+		case runes[i] == '?':
+			pattern += "."
+		default:
+			pattern += regexp.QuoteMeta(string(runes[i]))
+		}
+	}
+	return pattern
+}
+
+type boolQuery struct {
+	and     bool
+	clauses []Query
+}
+
+func (q *boolQuery) toES() esQuery {
+	clauses := make([]esQuery, len(q.clauses))
+	for i, clause := range q.clauses {
+		clauses[i] = clause.toES()
+	}
+	if q.and {
+		return esQuery{"bool": esQuery{"must": clauses}}
+	}
+	return esQuery{"bool": esQuery{"should": clauses, "minimum_should_match": 1}}
+}
+
+func (q *boolQuery) Matches(entry LogEntry) bool {
+	for _, clause := range q.clauses {
+		if clause.Matches(entry) != q.and {
+			return !q.and
+		}
+	}
+	return q.and
+}
+
+func (q *boolQuery) watchable() bool {
+	for _, clause := range q.clauses {
+		if !clause.watchable() {
+			return false
+		}
+	}
+	return true
+}
+
+type notQuery struct {
+	clause Query
+}
+
+func (q *notQuery) toES() esQuery {
+	return esQuery{"bool": esQuery{"must_not": []esQuery{q.clause.toES()}}}
+}
+
+func (q *notQuery) Matches(entry LogEntry) bool {
+	return !q.clause.Matches(entry)
+}
+
+func (q *notQuery) watchable() bool {
+	return q.clause.watchable()
+}
+
+type tokenType int
+
+const (
+	tokenWord tokenType = iota
+	tokenPhrase
+	tokenField
+	tokenLeftParen
+	tokenRightParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenEnd
+)
+
+type token struct {
+	typ   tokenType
+	value string
+	pos   int
+}
+
+var fieldNameRegex = regexp.MustCompile(`^[A-Za-z0-9_@][A-Za-z0-9_@.\-]*$`)
+
+// lexQuery splits a query into tokens. A word immediately followed by a colon
+// is a field name.
+func lexQuery(input string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLeftParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRightParen, ")", i})
+			i++
+		case r == '"':
+			start := i
+			var value []rune
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value = append(value, runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("Invalid query: the quote at position %d is never closed", start+1)
+			}
+			i++
+			tokens = append(tokens, token{tokenPhrase, string(value), start})
+		default:
+			start := i
+			var value []rune
+			for ; i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"'; i++ {
+				if runes[i] == ':' && len(value) > 0 && fieldNameRegex.MatchString(string(value)) && !hasFieldToken(tokens, start) {
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					// wildcards keep their escape so they are matched literally
+					if strings.ContainsRune(`*?\\`, runes[i]) {
+						value = append(value, '\\')
+					}
+				}
+				value = append(value, runes[i])
+			}
+			if i < len(runes) && runes[i] == ':' {
+				i++
+				if i == len(runes) || unicode.IsSpace(runes[i]) || runes[i] == ')' {
+					return nil, fmt.Errorf("Invalid query: the field \"%s\" at position %d has no value", string(value), start+1)
+				}
+				tokens = append(tokens, token{tokenField, string(value), start})
+				continue
+			}
+			word := string(value)
+			typ := tokenWord
+			switch word {
+			case "AND":
+				typ = tokenAnd
+			case "OR":
+				typ = tokenOr
+			case "NOT":
+				typ = tokenNot
+			}
+			tokens = append(tokens, token{typ, word, start})
+		}
+	}
+	return append(tokens, token{tokenEnd, "", len(runes)}), nil
+}
+
+// hasFieldToken reports whether the word starting at pos is already the value
+// of a field, so colons in values like host:web:1 are kept
+func hasFieldToken(tokens []token, pos int) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	return last.typ == tokenField && last.pos+len([]rune(last.value))+1 == pos
+}
+
+type queryParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+// ParseQuery parses a log query. An empty query or * matches every log.
+func ParseQuery(input string) (Query, error) {
+	if strings.TrimSpace(input) == "" || strings.TrimSpace(input) == "*" {
+		return &matchAllQuery{}, nil
+	}
+	tokens, err := lexQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.typ != tokenEnd {
+		return nil, fmt.Errorf("Invalid query: unexpected \"%s\" at position %d", t.value, t.pos+1)
+	}
+	return q, nil
+}
+
+func (p *queryParser) parseOr() (Query, error) {
+	clauses := []Query{}
+	for {
+		q, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, q)
+		if p.peek().typ != tokenOr {
+			break
+		}
+		p.next()
+	}
+	if len(clauses) == 1 {
+		return clauses[0], nil
+	}
+	return &boolQuery{and: false, clauses: clauses}, nil
+}
+
+func (p *queryParser) parseAnd() (Query, error) {
+	clauses := []Query{}
+	for {
+		q, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, q)
+		switch p.peek().typ {
+		case tokenAnd:
+			p.next()
+			continue
+		case tokenWord, tokenPhrase, tokenField, tokenLeftParen, tokenNot:
+			// terms next to each other must both match
+			continue
+		}
+		break
+	}
+	if len(clauses) == 1 {
+		return clauses[0], nil
+	}
+	return &boolQuery{and: true, clauses: clauses}, nil
+}
+
+func (p *queryParser) parseNot() (Query, error) {
+	if p.peek().typ == tokenNot {
+		p.next()
+		q, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notQuery{clause: q}, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *queryParser) parseTerm() (Query, error) {
+	t := p.next()
+	switch t.typ {
+	case tokenLeftParen:
+		q, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if end := p.next(); end.typ != tokenRightParen {
+			return nil, fmt.Errorf("Invalid query: the parenthesis at position %d is never closed", t.pos+1)
+		}
+		return q, nil
+	case tokenField:
+		value := p.next()
+		if value.typ != tokenWord && value.typ != tokenPhrase {
+			return nil, fmt.Errorf("Invalid query: the field \"%s\" at position %d has no value", t.value, t.pos+1)
+		}
+		return newTermQuery(t.value, value.value, value.typ == tokenPhrase), nil
+	case tokenWord, tokenPhrase:
+		return newTermQuery(defaultQueryField, t.value, t.typ == tokenPhrase), nil
+	case tokenEnd:
+		return nil, fmt.Errorf("Invalid query: expected a term at the end of the query")
+	}
+	return nil, fmt.Errorf("Invalid query: unexpected \"%s\" at position %d", t.value, t.pos+1)
+}
+
+// newTermQuery makes a term query. Elasticsearch lowercases the message when
+// indexing it but does not lowercase wildcard terms, so words in the message
+// are lowercased to match regardless of case.
+func newTermQuery(field, value string, phrase bool) *termQuery {
+	if field == defaultQueryField && !phrase {
+		value = strings.ToLower(value)
+	}
+	return &termQuery{field: field, value: value, phrase: phrase}
+}
@@ -0,0 +1,253 @@
+package logs
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daticahealth/cli/test"
+)
+
+var parseQueryTests = []struct {
+	query    string
+	expected string
+}{
+	{"", `{"match_all":{}}`},
+	{"*", `{"match_all":{}}`},
+	{"error", `{"wildcard":{"message":"error"}}`},
+	{"ERR*", `{"wildcard":{"message":"err*"}}`},
+	{"message:Error", `{"wildcard":{"message":"error"}}`},
+	{`"Connection Refused"`, `{"match_phrase":{"message":"Connection Refused"}}`},
+	{"host:Web*", `{"wildcard":{"host":"Web*"}}`},
+	{`"connection refused"`, `{"match_phrase":{"message":"connection refused"}}`},
+	{"host:web*", `{"wildcard":{"host":"web*"}}`},
+	{`host:"web 1"`, `{"match_phrase":{"host":"web 1"}}`},
+	{"host:web:1", `{"wildcard":{"host":"web:1"}}`},
+	{`level:error AND host:web* AND NOT "healthcheck"`, `{"bool":{"must":[{"wildcard":{"level":"error"}},{"wildcard":{"host":"web*"}},{"bool":{"must_not":[{"match_phrase":{"message":"healthcheck"}}]}}]}}`},
+	{"timeout error", `{"bool":{"must":[{"wildcard":{"message":"timeout"}},{"wildcard":{"message":"error"}}]}}`},
+	{"a OR b AND c", `{"bool":{"minimum_should_match":1,"should":[{"wildcard":{"message":"a"}},{"bool":{"must":[{"wildcard":{"message":"b"}},{"wildcard":{"message":"c"}}]}}]}}`},
+	{"(a OR b) c", `{"bool":{"must":[{"bool":{"minimum_should_match":1,"should":[{"wildcard":{"message":"a"}},{"wildcard":{"message":"b"}}]}},{"wildcard":{"message":"c"}}]}}`},
+	{"NOT NOT a", `{"bool":{"must_not":[{"bool":{"must_not":[{"wildcard":{"message":"a"}}]}}]}}`},
+	{"and or not", `{"bool":{"must":[{"wildcard":{"message":"and"}},{"wildcard":{"message":"or"}},{"wildcard":{"message":"not"}}]}}`},
+	{`"say \"hi\" \\ bye"`, `{"match_phrase":{"message":"say \"hi\" \\ bye"}}`},
+	{`a\ b\:c`, `{"wildcard":{"message":"a b:c"}}`},
+	{`100\* a\\b`, `{"bool":{"must":[{"wildcard":{"message":"100\\*"}},{"wildcard":{"message":"a\\\\b"}}]}}`},
+	{`message:"\"}},{\"match_all\":{}}"`, `{"match_phrase":{"message":"\"}},{\"match_all\":{}}"}}`},
+}
+
+func TestParseQuery(t *testing.T) {
+	for _, data := range parseQueryTests {
+		t.Logf("Data: %+v", data)
+
+		// test
+		q, err := ParseQuery(data.query)
+
+		// assert
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		b, err := json.Marshal(q.toES())
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		test.AssertEquals(t, data.expected, string(b))
+	}
+}
+
+var parseQueryErrorTests = []struct {
+	query    string
+	expected string
+}{
+	{`"unclosed`, "Invalid query: the quote at position 1 is never closed"},
+	{"(a OR b", "Invalid query: the parenthesis at position 1 is never closed"},
+	{"a)", `Invalid query: unexpected ")" at position 2`},
+	{"a AND", "Invalid query: expected a term at the end of the query"},
+	{"OR a", `Invalid query: unexpected "OR" at position 1`},
+	{"host: web", `Invalid query: the field "host" at position 1 has no value`},
+	{"host:(a OR b)", `Invalid query: the field "host" at position 1 has no value`},
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, data := range parseQueryErrorTests {
+		t.Logf("Data: %+v", data)
+
+		// test
+		_, err := ParseQuery(data.query)
+
+		// assert
+		if err == nil {
+			t.Errorf("Expected an error parsing %s", data.query)
+			continue
+		}
+		test.AssertEquals(t, data.expected, err.Error())
+	}
+}
+
+func TestQueryGenerators(t *testing.T) {
+	// input that would have broken out of the JSON strings the queries used to
+	// be concatenated from
+	q, err := ParseQuery(`"\"}}]} \\" host:web*`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timestamp := time.Date(2017, 10, 11, 15, 4, 5, 0, time.UTC)
+	for _, version := range []string{"1.7.3", "2.4.0", "5.6.2"} {
+		for _, hostNames := range [][]string{nil, {"web-abc123", `web"def456`}} {
+			t.Logf("Data: %s %v", version, hostNames)
+
+			// test
+			b, err := chooseQueryGenerator(version)(q, "source", "app", timestamp, time.Time{}, 50, hostNames, "/data/log/app/web/current")
+
+			// assert
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				continue
+			}
+			var body struct {
+				Source []string        `json:"_source"`
+				Fields []string        `json:"fields"`
+				Query  json.RawMessage `json:"query"`
+				Filter struct {
+					Query json.RawMessage `json:"query"`
+				} `json:"filter"`
+				From int `json:"from"`
+				Size int `json:"size"`
+			}
+			if err = json.Unmarshal(b, &body); err != nil {
+				t.Errorf("Generated invalid JSON %s: %s", b, err)
+				continue
+			}
+			userQuery := `{"bool":{"must":[{"match_phrase":{"message":"\"}}]} \\"}},{"wildcard":{"host":"web*"}}]}}`
+			filter := `{"bool":{"must":[{"term":{"source":"app"}},{"match_phrase":{"file":"/data/log/app/web/current"}},{"range":{"@timestamp":{"gt":"2017-10-11T15:04:05Z"}}}]}}`
+			if hostNames != nil {
+				filter = `{"bool":{"minimum_should_match":1,"must":[{"term":{"source":"app"}},{"range":{"@timestamp":{"gt":"2017-10-11T15:04:05Z"}}}],"should":[{"match_phrase":{"host":"web-abc123"}},{"match_phrase":{"host":"web\"def456"}}]}}`
+			}
+			if version == "5.6.2" {
+				test.AssertEquals(t, `["@timestamp","message","host","source"]`, marshal(t, body.Source))
+				var must struct {
+					Bool struct {
+						Must []json.RawMessage `json:"must"`
+					} `json:"bool"`
+				}
+				json.Unmarshal(body.Query, &must)
+				test.AssertEquals(t, userQuery, string(must.Bool.Must[0]))
+			} else {
+				test.AssertEquals(t, `["@timestamp","message","host","source"]`, marshal(t, body.Fields))
+				test.AssertEquals(t, userQuery, string(body.Query))
+				test.AssertEquals(t, filter, string(body.Filter.Query))
+			}
+			if body.From != 50 || body.Size != size {
+				t.Errorf("Expected from 50 and size %d but got from %d and size %d", size, body.From, body.Size)
+			}
+		}
+	}
+}
+
+func marshal(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+var queryMatchesTests = []struct {
+	query    string
+	entry    LogEntry
+	expected bool
+}{
+	{"", LogEntry{Message: "anything"}, true},
+	{"error", LogEntry{Message: "An ERROR happened"}, true},
+	{"ERROR", LogEntry{Message: "an error happened"}, true},
+	{"host:Web*", LogEntry{Message: "x", Host: "web-abc123"}, false},
+	{"err*d", LogEntry{Message: "an error happened"}, true},
+	{"error", LogEntry{Message: "all good"}, false},
+	{`"error happened"`, LogEntry{Message: "an Error happened"}, true},
+	{`"err*"`, LogEntry{Message: "an error happened"}, false},
+	{"host:web*", LogEntry{Message: "x", Host: "web-abc123"}, true},
+	{"host:web*", LogEntry{Message: "x", Host: "worker-web"}, false},
+	{"source:app", LogEntry{Source: "app"}, true},
+	{"level:error", LogEntry{Message: "level:error"}, false},
+	{`host:web* AND NOT "healthcheck"`, LogEntry{Message: "GET /healthcheck 200", Host: "web-1"}, false},
+	{`host:web* AND NOT "healthcheck"`, LogEntry{Message: "GET /patients 200", Host: "web-1"}, true},
+	{"timeout OR refused", LogEntry{Message: "connection refused"}, true},
+	{"timeout refused", LogEntry{Message: "connection refused"}, false},
+	{"a.b", LogEntry{Message: "axb"}, false},
+	{`100\*`, LogEntry{Message: "100* done"}, true},
+	{`100\*`, LogEntry{Message: "1000 done"}, false},
+}
+
+func TestQueryMatches(t *testing.T) {
+	for _, data := range queryMatchesTests {
+		t.Logf("Data: %+v", data)
+		q, err := ParseQuery(data.query)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+
+		// test
+		actual := q.Matches(data.entry)
+
+		// assert
+		if actual != data.expected {
+			t.Errorf("Expected %s to match %+v: %t, actual: %t", data.query, data.entry, data.expected, actual)
+		}
+	}
+}
+
+var queryWatchableTests = []struct {
+	query    string
+	expected bool
+}{
+	{"", true},
+	{`error AND host:web* AND NOT source:"app"`, true},
+	{"@timestamp:2017*", true},
+	{"level:error", false},
+	{"error OR level:error", false},
+	{"NOT (host:web* level:error)", false},
+}
+
+func TestQueryWatchable(t *testing.T) {
+	for _, data := range queryWatchableTests {
+		t.Logf("Data: %+v", data)
+		q, err := ParseQuery(data.query)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+
+		// test
+		actual := q.watchable()
+
+		// assert
+		if actual != data.expected {
+			t.Errorf("Expected %s to be watchable: %t, actual: %t", data.query, data.expected, actual)
+		}
+	}
+}
+
+func TestQueryGeneratorsUntil(t *testing.T) {
+	q, _ := ParseQuery("")
+	since := time.Date(2017, 10, 11, 2, 10, 0, 0, time.UTC)
+	until := time.Date(2017, 10, 11, 4, 40, 0, 0, time.FixedZone("CEST", 2*60*60))
+	for _, version := range []string{"1.7.3", "2.4.0", "5.6.2"} {
+		t.Logf("Data: %s", version)
+
+		// test
+		b, err := chooseQueryGenerator(version)(q, "source", "app", since, until, 0, nil, "")
+
+		// assert
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		expected := `{"range":{"@timestamp":{"gt":"2017-10-11T02:10:00Z","lte":"2017-10-11T02:40:00Z"}}}`
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("Expected %s to contain %s", b, expected)
+		}
+	}
+}
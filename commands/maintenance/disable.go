@@ -48,7 +48,7 @@ func CmdDisable(svcName string, im IMaintenance, is services.IServices) error {
 
 func (m *SMaintenance) Disable(svcProxyID, upstreamID string) error {
 	headers := m.Settings.HTTPManager.GetHeaders(m.Settings.SessionToken, m.Settings.Version, m.Settings.Pod, m.Settings.UsersID)
-	resp, statusCode, err := m.Settings.HTTPManager.Delete(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/maintenance?upstream=%s", m.Settings.PaasHost, m.Settings.PaasHostVersion, m.Settings.EnvironmentID, svcProxyID, upstreamID), headers)
+	resp, statusCode, err := m.Settings.HTTPManager.Delete(m.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/maintenance?upstream=%s", m.Settings.PaasHost, m.Settings.PaasHostVersion, m.Settings.EnvironmentID, svcProxyID, upstreamID), headers)
 	if err != nil {
 		return err
 	}
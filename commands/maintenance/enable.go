@@ -42,7 +42,7 @@ func (m *SMaintenance) Enable(svcProxyID, upstreamID string) error {
 		return err
 	}
 	headers := m.Settings.HTTPManager.GetHeaders(m.Settings.SessionToken, m.Settings.Version, m.Settings.Pod, m.Settings.UsersID)
-	resp, statusCode, err := m.Settings.HTTPManager.Post(b, fmt.Sprintf("%s%s/environments/%s/services/%s/maintenance", m.Settings.PaasHost, m.Settings.PaasHostVersion, m.Settings.EnvironmentID, svcProxyID), headers)
+	resp, statusCode, err := m.Settings.HTTPManager.Post(m.Settings.Context, b, fmt.Sprintf("%s%s/environments/%s/services/%s/maintenance", m.Settings.PaasHost, m.Settings.PaasHostVersion, m.Settings.EnvironmentID, svcProxyID), headers)
 	if err != nil {
 		return err
 	}
@@ -62,7 +62,7 @@ func CmdShow(svcName, envID, podID string, im IMaintenance, is services.IService
 
 func (m *SMaintenance) List(svcProxyID string) (*[]models.Maintenance, error) {
 	headers := m.Settings.HTTPManager.GetHeaders(m.Settings.SessionToken, m.Settings.Version, m.Settings.Pod, m.Settings.UsersID)
-	resp, statusCode, err := m.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/maintenance", m.Settings.PaasHost, m.Settings.PaasHostVersion, m.Settings.EnvironmentID, svcProxyID), headers)
+	resp, statusCode, err := m.Settings.HTTPManager.Get(m.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/maintenance", m.Settings.PaasHost, m.Settings.PaasHostVersion, m.Settings.EnvironmentID, svcProxyID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +66,7 @@ var CPUSubCmd = models.Command{
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdMetrics(*serviceName, CPU, *json, *csv, *text, *stream, *mins, New(settings), services.New(settings))
+				err := CmdMetrics(settings.Context, *serviceName, CPU, *json, *csv, *text, *stream, *mins, New(settings), services.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
@@ -105,7 +105,7 @@ var MemorySubCmd = models.Command{
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdMetrics(*serviceName, Memory, *json, *csv, *text, *stream, *mins, New(settings), services.New(settings))
+				err := CmdMetrics(settings.Context, *serviceName, Memory, *json, *csv, *text, *stream, *mins, New(settings), services.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
@@ -143,7 +143,7 @@ var NetworkInSubCmd = models.Command{
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdMetrics(*serviceName, NetworkIn, *json, *csv, *text, *stream, *mins, New(settings), services.New(settings))
+				err := CmdMetrics(settings.Context, *serviceName, NetworkIn, *json, *csv, *text, *stream, *mins, New(settings), services.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
@@ -182,7 +182,7 @@ var NetworkOutSubCmd = models.Command{
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdMetrics(*serviceName, NetworkOut, *json, *csv, *text, *stream, *mins, New(settings), services.New(settings))
+				err := CmdMetrics(settings.Context, *serviceName, NetworkOut, *json, *csv, *text, *stream, *mins, New(settings), services.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"fmt"
 	"time"
@@ -33,7 +34,7 @@ type Transformer interface {
 
 // CmdMetrics prints out metrics for a given service or if the service is not
 // specified, metrics for the entire environment are printed.
-func CmdMetrics(svcName string, metricType MetricType, jsonFlag, csvFlag, textFlag, streamFlag bool, mins int, im IMetrics, is services.IServices) error {
+func CmdMetrics(ctx context.Context, svcName string, metricType MetricType, jsonFlag, csvFlag, textFlag, streamFlag bool, mins int, im IMetrics, is services.IServices) error {
 	if streamFlag && (jsonFlag || csvFlag || mins != 1) {
 		return fmt.Errorf("--stream cannot be used with CSV or JSON formats and multiple records")
 	}
@@ -62,14 +63,18 @@ func CmdMetrics(svcName string, metricType MetricType, jsonFlag, csvFlag, textFl
 		if service == nil {
 			return fmt.Errorf("Could not find a service with the label \"%s\"", svcName)
 		}
-		return CmdServiceMetrics(metricType, streamFlag, mins, service, mt, im)
+		return CmdServiceMetrics(ctx, metricType, streamFlag, mins, service, mt, im)
 	}
-	return CmdEnvironmentMetrics(metricType, streamFlag, mins, mt, im)
+	return CmdEnvironmentMetrics(ctx, metricType, streamFlag, mins, mt, im)
 }
 
-func CmdEnvironmentMetrics(metricType MetricType, stream bool, mins int, t Transformer, im IMetrics) error {
+func CmdEnvironmentMetrics(ctx context.Context, metricType MetricType, stream bool, mins int, t Transformer, im IMetrics) error {
 	for {
 		metrics, err := im.RetrieveEnvironmentMetrics(mins)
+		if ctx.Err() != nil {
+			// streaming stops when interrupted or timed out
+			return nil
+		}
 		if err != nil {
 			logrus.Fatal(err.Error())
 		}
@@ -86,14 +91,22 @@ func CmdEnvironmentMetrics(metricType MetricType, stream bool, mins int, t Trans
 		if !stream {
 			break
 		}
-		time.Sleep(time.Minute)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Minute):
+		}
 	}
 	return nil
 }
 
-func CmdServiceMetrics(metricType MetricType, stream bool, mins int, service *models.Service, t Transformer, im IMetrics) error {
+func CmdServiceMetrics(ctx context.Context, metricType MetricType, stream bool, mins int, service *models.Service, t Transformer, im IMetrics) error {
 	for {
 		metrics, err := im.RetrieveServiceMetrics(mins, service.ID)
+		if ctx.Err() != nil {
+			// streaming stops when interrupted or timed out
+			return nil
+		}
 		if err != nil {
 			logrus.Fatal(err.Error())
 		}
@@ -110,7 +123,11 @@ func CmdServiceMetrics(metricType MetricType, stream bool, mins int, service *mo
 		if !stream {
 			break
 		}
-		time.Sleep(time.Minute)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Minute):
+		}
 	}
 	return nil
 }
@@ -134,7 +151,7 @@ func metricsTypeToString(metricType MetricType) string {
 // the associated environment.
 func (m *SMetrics) RetrieveEnvironmentMetrics(mins int) (*[]models.Metrics, error) {
 	headers := m.Settings.HTTPManager.GetHeaders(m.Settings.SessionToken, m.Settings.Version, m.Settings.Pod, m.Settings.UsersID)
-	resp, statusCode, err := m.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/metrics?time=%dm", m.Settings.PaasHost, m.Settings.PaasHostVersion, m.Settings.EnvironmentID, mins), headers)
+	resp, statusCode, err := m.Settings.HTTPManager.Get(m.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/metrics?time=%dm", m.Settings.PaasHost, m.Settings.PaasHostVersion, m.Settings.EnvironmentID, mins), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -149,7 +166,7 @@ func (m *SMetrics) RetrieveEnvironmentMetrics(mins int) (*[]models.Metrics, erro
 // RetrieveServiceMetrics retrieves metrics data for the given service.
 func (m *SMetrics) RetrieveServiceMetrics(mins int, svcID string) (*models.Metrics, error) {
 	headers := m.Settings.HTTPManager.GetHeaders(m.Settings.SessionToken, m.Settings.Version, m.Settings.Pod, m.Settings.UsersID)
-	resp, statusCode, err := m.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/metrics?time=%dm", m.Settings.PaasHost, m.Settings.PaasHostVersion, m.Settings.EnvironmentID, svcID, mins), headers)
+	resp, statusCode, err := m.Settings.HTTPManager.Get(m.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/metrics?time=%dm", m.Settings.PaasHost, m.Settings.PaasHostVersion, m.Settings.EnvironmentID, svcID, mins), headers)
 	if err != nil {
 		return nil, err
 	}
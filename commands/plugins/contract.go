@@ -0,0 +1,100 @@
+package plugins
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/config"
+	"github.com/daticahealth/cli/lib/auth"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/daticahealth/cli/lib/prompts"
+	"github.com/daticahealth/cli/models"
+	"github.com/jault3/mow.cli"
+)
+
+// Cmd is the contract between the user and the CLI. This specifies the command
+// name, arguments, and required/optional arguments and flags for the command.
+var Cmd = models.Command{
+	Name:      "plugins",
+	ShortHelp: "Manage external plugin commands",
+	LongHelp: "The <code>plugins</code> command gives information about plugins. " +
+		"A plugin is any executable on your PATH named <code>" + Prefix + "&lt;name&gt;</code>. " +
+		"Running <code>datica &lt;name&gt;</code> runs the plugin with all remaining arguments after signing in and resolving your environment. " +
+		"The plugin is given the session token, environment, pod, org, and API hosts through these environment variables: " +
+		"<code>" + SessionTokenEnvVar + "</code>, <code>" + UsersIDEnvVar + "</code>, <code>" + EnvIDEnvVar + "</code>, <code>" + EnvNameEnvVar + "</code>, <code>" + PodEnvVar + "</code>, <code>" + OrgIDEnvVar + "</code>, " +
+		"<code>" + AccountsURLEnvVar + "</code>, <code>" + AuthURLEnvVar + "</code>, and <code>" + PaasURLEnvVar + "</code>. " +
+		"Plugins that do not need an environment can run without one, so <code>" + EnvIDEnvVar + "</code> and <code>" + EnvNameEnvVar + "</code> are empty when no environment is associated and none is given with <code>-E</code>. " +
+		"Built in commands always take precedence over a plugin with the same name. The plugins command can not be run directly but has subcommands.",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(cmd *cli.Cmd) {
+			cmd.CommandLong(ListSubCmd.Name, ListSubCmd.ShortHelp, ListSubCmd.LongHelp, ListSubCmd.CmdFunc(settings))
+		}
+	},
+}
+
+var ListSubCmd = models.Command{
+	Name:      "list",
+	ShortHelp: "List all plugins found on your PATH",
+	LongHelp: "<code>plugins list</code> lists every plugin found on your PATH along with its location. " +
+		"When more than one plugin has the same name, the first one on your PATH is used. Here is a sample command\n\n" +
+		"<pre>\ndatica plugins list\n</pre>",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			subCmd.Action = func() {
+				err := CmdList(New(settings), output.New(settings))
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+		}
+	},
+}
+
+// PluginCmd builds the command that runs the given plugin. The plugin's
+// arguments are split off before the CLI parses the command line so they are
+// passed through untouched, including flags such as --help.
+func PluginCmd(plugin *Plugin, args []string) models.Command {
+	return models.Command{
+		Name:      plugin.Name,
+		ShortHelp: fmt.Sprintf("Run the %s plugin", plugin.Name),
+		LongHelp:  fmt.Sprintf("Runs the plugin at <code>%s</code>.", plugin.Path),
+		CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+			return func(cmd *cli.Cmd) {
+				cmd.Action = func() {
+					if _, err := auth.New(settings, prompts.New()).Signin(); err != nil {
+						logrus.Fatal(err.Error())
+					}
+					if err := config.CheckRequiredAssociation(settings); err != nil {
+						logrus.Debugf("Running the %s plugin without an environment: %s", plugin.Name, err)
+					}
+					code, err := CmdRun(plugin, args, New(settings))
+					if err != nil {
+						logrus.Fatal(err.Error())
+					}
+					if code != 0 {
+						cli.Exit(code)
+					}
+				}
+			}
+		},
+	}
+}
+
+// IPlugins
+type IPlugins interface {
+	Find(name string) (*Plugin, error)
+	List() (*[]Plugin, error)
+	Run(plugin *Plugin, args []string) (int, error)
+}
+
+// SPlugins is a concrete implementation of IPlugins
+type SPlugins struct {
+	Settings *models.Settings
+}
+
+// New returns an instance of IPlugins
+func New(settings *models.Settings) IPlugins {
+	return &SPlugins{
+		Settings: settings,
+	}
+}
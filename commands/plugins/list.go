@@ -0,0 +1,97 @@
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/olekukonko/tablewriter"
+)
+
+// Prefix is the prefix every plugin executable's name starts with
+const Prefix = "datica-"
+
+// Plugin is an executable on the PATH that can be run as a datica command
+type Plugin struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+func CmdList(ip IPlugins, out output.IOutput) error {
+	plugins, err := ip.List()
+	if err != nil {
+		return err
+	}
+	return out.Render(plugins, func() error {
+		if len(*plugins) == 0 {
+			logrus.Printf("No plugins found. Plugins are executables on your PATH named %s<name>.", Prefix)
+			return nil
+		}
+		data := [][]string{{"NAME", "PATH"}}
+		for _, p := range *plugins {
+			data = append(data, []string{p.Name, p.Path})
+		}
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.AppendBulk(data)
+		table.Render()
+		return nil
+	})
+}
+
+// List finds every plugin on the PATH. When two directories contain a plugin
+// with the same name, only the first one is returned since that is the one
+// that will be run.
+func (p *SPlugins) List() (*[]Plugin, error) {
+	plugins := []Plugin{}
+	found := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			logrus.Debugf("Skipping %s while looking for plugins: %s", dir, err)
+			continue
+		}
+		for _, f := range files {
+			name, ok := pluginName(f)
+			if !ok || found[name] {
+				continue
+			}
+			found[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: filepath.Join(dir, f.Name())})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return &plugins, nil
+}
+
+// pluginName returns the command name of a plugin executable
+func pluginName(f os.FileInfo) (string, bool) {
+	if f.IsDir() || !strings.HasPrefix(f.Name(), Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(f.Name(), Prefix)
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	} else if f.Mode()&0111 == 0 {
+		return "", false
+	}
+	return name, name != ""
+}
@@ -0,0 +1,79 @@
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/daticahealth/cli/test"
+)
+
+var runTests = []struct {
+	args         []string
+	expectedCode int
+	expectedOut  string
+}{
+	{[]string{}, 0, test.EnvID + " " + test.OrgID},
+	{[]string{"--help"}, 0, "--help"},
+	{[]string{"fail"}, 3, "fail"},
+}
+
+func TestPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts are not executable on windows")
+	}
+	_, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	settings := test.GetSettings(baseURL.String())
+	dir, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "out")
+	script := "#!/bin/sh\necho \"$@ $" + EnvIDEnvVar + " $" + OrgIDEnvVar + "\" > " + out + "\n[ \"$1\" = fail ] && exit 3\nexit 0\n"
+	if err = ioutil.WriteFile(filepath.Join(dir, Prefix+"hello"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// not executable, so not a plugin
+	if err = ioutil.WriteFile(filepath.Join(dir, Prefix+"readme"), []byte("docs"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	plugins, err := New(settings).List()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(*plugins) != 1 || (*plugins)[0].Name != "hello" {
+		t.Fatalf("Expected only the hello plugin, got %+v", *plugins)
+	}
+	plugin, err := New(settings).Find("hello")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, data := range runTests {
+		t.Logf("Data: %+v", data)
+
+		// test
+		code, err := CmdRun(plugin, data.args, New(settings))
+
+		// assert
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if code != data.expectedCode {
+			t.Errorf("Expected exit code %d, got %d", data.expectedCode, code)
+		}
+		b, _ := ioutil.ReadFile(out)
+		if !strings.Contains(string(b), data.expectedOut) {
+			t.Errorf("Expected the plugin output to contain %q, got %q", data.expectedOut, string(b))
+		}
+	}
+}
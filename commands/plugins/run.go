@@ -0,0 +1,68 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Environment variables used to hand the resolved context to a plugin
+const (
+	SessionTokenEnvVar = "DATICA_SESSION_TOKEN"
+	UsersIDEnvVar      = "DATICA_USER_ID"
+	EnvIDEnvVar        = "DATICA_ENV_ID"
+	EnvNameEnvVar      = "DATICA_ENV_NAME"
+	PodEnvVar          = "DATICA_POD"
+	OrgIDEnvVar        = "DATICA_ORG_ID"
+	AccountsURLEnvVar  = "DATICA_ACCOUNTS_URL"
+	AuthURLEnvVar      = "DATICA_AUTH_URL"
+	PaasURLEnvVar      = "DATICA_PAAS_URL"
+)
+
+// CmdRun runs a plugin and returns its exit code
+func CmdRun(plugin *Plugin, args []string, ip IPlugins) (int, error) {
+	return ip.Run(plugin, args)
+}
+
+// Find looks up the plugin for the given command name on the PATH
+func (p *SPlugins) Find(name string) (*Plugin, error) {
+	path, err := exec.LookPath(Prefix + name)
+	if err != nil {
+		return nil, err
+	}
+	return &Plugin{Name: name, Path: path}, nil
+}
+
+// Run runs a plugin with the terminal attached, passing the session token,
+// environment, and API hosts of the current command through environment
+// variables. Hosts include the API version, so a plugin can append paths to
+// them directly.
+func (p *SPlugins) Run(plugin *Plugin, args []string) (int, error) {
+	cmd := exec.Command(plugin.Path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%s", SessionTokenEnvVar, p.Settings.SessionToken),
+		fmt.Sprintf("%s=%s", UsersIDEnvVar, p.Settings.UsersID),
+		fmt.Sprintf("%s=%s", EnvIDEnvVar, p.Settings.EnvironmentID),
+		fmt.Sprintf("%s=%s", EnvNameEnvVar, p.Settings.EnvironmentName),
+		fmt.Sprintf("%s=%s", PodEnvVar, p.Settings.Pod),
+		fmt.Sprintf("%s=%s", OrgIDEnvVar, p.Settings.OrgID),
+		fmt.Sprintf("%s=%s", AccountsURLEnvVar, p.Settings.AccountsHost),
+		fmt.Sprintf("%s=%s%s", AuthURLEnvVar, p.Settings.AuthHost, p.Settings.AuthHostVersion),
+		fmt.Sprintf("%s=%s%s", PaasURLEnvVar, p.Settings.PaasHost, p.Settings.PaasHostVersion),
+	)
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus(), nil
+		}
+		return 1, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Failed to run the %s plugin: %s", plugin.Name, err)
+	}
+	return 0, nil
+}
@@ -0,0 +1,34 @@
+package profile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/config"
+	"github.com/daticahealth/cli/models"
+)
+
+// CmdAdd creates a new empty profile with the given hosts.
+func CmdAdd(name, accountsHost, authHost, paasHost string, settings *models.Settings) error {
+	if strings.ContainsAny(name, config.InvalidChars) {
+		return fmt.Errorf("Invalid profile name. Names must not contain the following characters: %s", config.InvalidChars)
+	}
+	if _, ok := settings.Profiles[name]; ok {
+		return fmt.Errorf("A profile named \"%s\" already exists", name)
+	}
+	if settings.Profiles == nil {
+		settings.Profiles = map[string]models.Profile{}
+	}
+	settings.Profiles[name] = models.Profile{
+		AccountsHost: strings.TrimRight(accountsHost, "/"),
+		AuthHost:     strings.TrimRight(authHost, "/"),
+		PaasHost:     strings.TrimRight(paasHost, "/"),
+		Environments: map[string]models.AssociatedEnvV2{},
+	}
+	if err := config.SaveSettings(settings); err != nil {
+		return err
+	}
+	logrus.Printf("Added profile \"%s\". Run \"datica profile use %s\" to make it the default or pass \"--profile %s\" to a single command.", name, name, name)
+	return nil
+}
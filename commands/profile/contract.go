@@ -0,0 +1,110 @@
+package profile
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/daticahealth/cli/models"
+	"github.com/jault3/mow.cli"
+)
+
+// Cmd is the contract between the user and the CLI. This specifies the command
+// name, arguments, and required/optional arguments and flags for the command.
+var Cmd = models.Command{
+	Name:      "profile",
+	ShortHelp: "Manage named profiles for multiple accounts and API hosts",
+	LongHelp: "The <code>profile</code> command allows you to keep separate credentials, API hosts, and environment data for each Datica account you use. " +
+		"A profile can be picked for a single command with the global <code>--profile</code> option or made the default with <code>profile use</code>. " +
+		"The profile command can not be run directly but has subcommands.",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(cmd *cli.Cmd) {
+			cmd.CommandLong(AddSubCmd.Name, AddSubCmd.ShortHelp, AddSubCmd.LongHelp, AddSubCmd.CmdFunc(settings))
+			cmd.CommandLong(ListSubCmd.Name, ListSubCmd.ShortHelp, ListSubCmd.LongHelp, ListSubCmd.CmdFunc(settings))
+			cmd.CommandLong(RmSubCmd.Name, RmSubCmd.ShortHelp, RmSubCmd.LongHelp, RmSubCmd.CmdFunc(settings))
+			cmd.CommandLong(UseSubCmd.Name, UseSubCmd.ShortHelp, UseSubCmd.LongHelp, UseSubCmd.CmdFunc(settings))
+		}
+	},
+}
+
+var AddSubCmd = models.Command{
+	Name:      "add",
+	ShortHelp: "Add a new profile",
+	LongHelp: "<code>profile add</code> creates a new, empty profile. " +
+		"Any hosts given are saved on the profile and used for every command run with it, unless overridden by the <code>ACCOUNTS_HOST</code>, <code>AUTH_HOST</code>, or <code>PAAS_HOST</code> environment variables. " +
+		"Hosts that are not given default to the production Datica hosts. " +
+		"You will be asked to sign in the first time you run a command with the new profile. Here are some sample commands\n\n" +
+		"<pre>\ndatica profile add staging --paas-host https://paas-api.staging.example.com --auth-host https://auth.staging.example.com\n" +
+		"datica --profile staging environments list\n</pre>",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			name := subCmd.StringArg("NAME", "", "The name of the new profile")
+			accountsHost := subCmd.StringOpt("accounts-host", "", "The accounts host to use with this profile")
+			authHost := subCmd.StringOpt("auth-host", "", "The auth host to use with this profile")
+			paasHost := subCmd.StringOpt("paas-host", "", "The PaaS host to use with this profile")
+			subCmd.Action = func() {
+				err := CmdAdd(*name, *accountsHost, *authHost, *paasHost, settings)
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "NAME [--accounts-host] [--auth-host] [--paas-host]"
+		}
+	},
+}
+
+var ListSubCmd = models.Command{
+	Name:      "list",
+	ShortHelp: "List all profiles",
+	LongHelp: "<code>profile list</code> prints out every profile in your settings file along with the hosts it uses. " +
+		"The profile used by default is marked with a <code>*</code>. Here is a sample command\n\n" +
+		"<pre>\ndatica profile list\n</pre>",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			subCmd.Action = func() {
+				err := CmdList(settings, output.New(settings))
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+		}
+	},
+}
+
+var RmSubCmd = models.Command{
+	Name:      "rm",
+	ShortHelp: "Remove a profile",
+	LongHelp: "<code>profile rm</code> removes a profile and all of its saved credentials and environment data from your settings file. " +
+		"The profile in use by the current command cannot be removed. Here is a sample command\n\n" +
+		"<pre>\ndatica profile rm staging\n</pre>",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			name := subCmd.StringArg("NAME", "", "The name of the profile to remove")
+			subCmd.Action = func() {
+				err := CmdRm(*name, settings)
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "NAME"
+		}
+	},
+}
+
+var UseSubCmd = models.Command{
+	Name:      "use",
+	ShortHelp: "Set the profile used when no --profile is given",
+	LongHelp: "<code>profile use</code> sets the default profile for all future commands. " +
+		"You can still pick a different profile for a single command with the global <code>--profile</code> option. Here is a sample command\n\n" +
+		"<pre>\ndatica profile use staging\n</pre>",
+	CmdFunc: func(settings *models.Settings) func(cmd *cli.Cmd) {
+		return func(subCmd *cli.Cmd) {
+			name := subCmd.StringArg("NAME", "", "The name of the profile to use")
+			subCmd.Action = func() {
+				err := CmdUse(*name, settings)
+				if err != nil {
+					logrus.Fatal(err.Error())
+				}
+			}
+			subCmd.Spec = "NAME"
+		}
+	},
+}
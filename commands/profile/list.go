@@ -0,0 +1,76 @@
+package profile
+
+import (
+	"sort"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/config"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/daticahealth/cli/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+// profileSummary is what gets printed for each profile. Credentials are
+// intentionally left out.
+type profileSummary struct {
+	Name         string `json:"name"`
+	Current      bool   `json:"current"`
+	AccountsHost string `json:"accounts_host"`
+	AuthHost     string `json:"auth_host"`
+	PaasHost     string `json:"paas_host"`
+	SignedIn     bool   `json:"signed_in"`
+}
+
+// CmdList prints every profile in the settings file.
+func CmdList(settings *models.Settings, out output.IOutput) error {
+	var names []string
+	for name := range settings.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	summaries := []profileSummary{}
+	for _, name := range names {
+		p := settings.Profiles[name]
+		summaries = append(summaries, profileSummary{
+			Name:         name,
+			Current:      name == settings.CurrentProfile,
+			AccountsHost: hostOrDefault(p.AccountsHost, config.AccountsHost),
+			AuthHost:     hostOrDefault(p.AuthHost, config.AuthHost),
+			PaasHost:     hostOrDefault(p.PaasHost, config.PaasHost),
+			SignedIn:     p.SessionToken != "",
+		})
+	}
+	return out.Render(summaries, func() error {
+		if len(summaries) == 0 {
+			logrus.Println("No profiles found")
+			return nil
+		}
+		data := [][]string{{"NAME", "AUTH HOST", "PAAS HOST"}}
+		for _, s := range summaries {
+			name := s.Name
+			if s.Current {
+				name = "*" + name
+			}
+			data = append(data, []string{name, s.AuthHost, s.PaasHost})
+		}
+
+		table := tablewriter.NewWriter(logrus.StandardLogger().Out)
+		table.SetBorder(false)
+		table.SetRowLine(false)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.AppendBulk(data)
+		table.Render()
+
+		logrus.Println("\n* denotes the default profile")
+		return nil
+	})
+}
+
+func hostOrDefault(host, fallback string) string {
+	if host == "" {
+		return fallback
+	}
+	return host
+}
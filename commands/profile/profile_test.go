@@ -0,0 +1,79 @@
+package profile
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/daticahealth/cli/config"
+	"github.com/daticahealth/cli/lib/output"
+	"github.com/daticahealth/cli/models"
+	"github.com/daticahealth/cli/test"
+)
+
+func TestProfiles(t *testing.T) {
+	f, err := ioutil.TempFile("", "datica-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	oldSettingsFile := config.SettingsFile
+	config.SettingsFile = f.Name()
+	defer func() { config.SettingsFile = oldSettingsFile }()
+	defer os.Remove(f.Name() + ".credentials")
+	oldCredentials := config.Credentials
+	config.Credentials = config.FileCredentialStore{}
+	defer func() { config.Credentials = oldCredentials }()
+
+	settings := test.GetSettings("")
+	settings.Profile = config.DefaultProfile
+	settings.CurrentProfile = config.DefaultProfile
+	settings.Profiles = map[string]models.Profile{config.DefaultProfile: models.Profile{}}
+
+	if err = CmdAdd("staging", "", "https://auth.example.com/", "https://paas.example.com", settings); err != nil {
+		t.Fatalf("Unexpected error adding a profile: %s", err)
+	}
+	if settings.Profiles["staging"].AuthHost != "https://auth.example.com" {
+		t.Errorf("Expected the trailing slash to be trimmed from the auth host, got %s", settings.Profiles["staging"].AuthHost)
+	}
+	if err = CmdAdd("staging", "", "", "", settings); err == nil {
+		t.Errorf("Expected an error adding a duplicate profile")
+	}
+	if err = CmdUse("missing", settings); err == nil {
+		t.Errorf("Expected an error using a profile that does not exist")
+	}
+	if err = CmdUse("staging", settings); err != nil {
+		t.Fatalf("Unexpected error using a profile: %s", err)
+	}
+	if settings.CurrentProfile != "staging" {
+		t.Errorf("Expected the current profile to be staging, got %s", settings.CurrentProfile)
+	}
+	if err = CmdList(settings, output.New(settings)); err != nil {
+		t.Errorf("Unexpected error listing profiles: %s", err)
+	}
+	if err = CmdRm(config.DefaultProfile, settings); err == nil {
+		t.Errorf("Expected an error removing the profile in use")
+	}
+	if err = CmdRm("staging", settings); err != nil {
+		t.Fatalf("Unexpected error removing a profile: %s", err)
+	}
+	if _, ok := settings.Profiles["staging"]; ok {
+		t.Errorf("The staging profile should have been removed")
+	}
+	if settings.CurrentProfile != config.DefaultProfile {
+		t.Errorf("Expected the current profile to fall back to %s, got %s", config.DefaultProfile, settings.CurrentProfile)
+	}
+
+	// the settings file only keeps the default profile, with the current token
+	s, err := config.FileSettingsRetriever{}.GetSettings("", "", "", "", "", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error reading settings: %s", err)
+	}
+	if len(s.Profiles) != 1 || s.SessionToken != settings.SessionToken {
+		t.Errorf("Unexpected settings after save: %+v", s)
+	}
+	if _, err = (config.FileSettingsRetriever{}).GetSettings("staging", "", "", "", "", "", "", "", "", ""); err == nil {
+		t.Errorf("Expected an error loading a removed profile")
+	}
+}
@@ -0,0 +1,32 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/config"
+	"github.com/daticahealth/cli/models"
+)
+
+// CmdRm removes a profile and everything stored with it.
+func CmdRm(name string, settings *models.Settings) error {
+	if _, ok := settings.Profiles[name]; !ok {
+		return fmt.Errorf("Could not find a profile named \"%s\". You can list profiles with the \"datica profile list\" command.", name)
+	}
+	if name == settings.Profile {
+		return fmt.Errorf("The profile \"%s\" is in use by this command and cannot be removed. Run \"datica --profile <other_profile> profile rm %s\" instead.", name, name)
+	}
+	delete(settings.Profiles, name)
+	if err := config.Credentials.Delete(name); err != nil {
+		logrus.Debugf("Could not remove the session token for profile %s: %s", name, err)
+	}
+	if name == settings.CurrentProfile {
+		settings.CurrentProfile = settings.Profile
+		logrus.Printf("\"%s\" was the default profile. \"%s\" is now the default.", name, settings.Profile)
+	}
+	if err := config.SaveSettings(settings); err != nil {
+		return err
+	}
+	logrus.Printf("Removed profile \"%s\"", name)
+	return nil
+}
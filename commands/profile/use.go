@@ -0,0 +1,22 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/config"
+	"github.com/daticahealth/cli/models"
+)
+
+// CmdUse sets the profile used when no --profile option is given.
+func CmdUse(name string, settings *models.Settings) error {
+	if _, ok := settings.Profiles[name]; !ok {
+		return fmt.Errorf("Could not find a profile named \"%s\". You can list profiles with the \"datica profile list\" command.", name)
+	}
+	settings.CurrentProfile = name
+	if err := config.SaveSettings(settings); err != nil {
+		return err
+	}
+	logrus.Printf("Now using profile \"%s\"", name)
+	return nil
+}
@@ -36,7 +36,7 @@ func (r *SRake) Run(taskName, svcID string) error {
 		return err
 	}
 	headers := r.Settings.HTTPManager.GetHeaders(r.Settings.SessionToken, r.Settings.Version, r.Settings.Pod, r.Settings.UsersID)
-	resp, statusCode, err := r.Settings.HTTPManager.Post(b, fmt.Sprintf("%s%s/environments/%s/services/%s/rake", r.Settings.PaasHost, r.Settings.PaasHostVersion, r.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := r.Settings.HTTPManager.Post(r.Settings.Context, b, fmt.Sprintf("%s%s/environments/%s/services/%s/rake", r.Settings.PaasHost, r.Settings.PaasHostVersion, r.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return err
 	}
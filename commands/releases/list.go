@@ -8,6 +8,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
@@ -27,7 +28,7 @@ func (rls SortedReleases) Less(i, j int) bool {
 	return rls[i].CreatedAt > rls[j].CreatedAt
 }
 
-func CmdList(svcName string, ir IReleases, is services.IServices) error {
+func CmdList(svcName string, ir IReleases, is services.IServices, out output.IOutput) error {
 	service, err := is.RetrieveByLabel(svcName)
 	if err != nil {
 		return err
@@ -41,12 +42,20 @@ func CmdList(svcName string, ir IReleases, is services.IServices) error {
 		return err
 	}
 
+	if rls != nil {
+		sort.Sort(SortedReleases(*rls))
+	}
+	return out.Render(rls, func() error {
+		return printReleases(rls, service)
+	})
+}
+
+func printReleases(rls *[]models.Release, service *models.Service) error {
 	if rls == nil || len(*rls) == 0 {
 		logrus.Println("No releases found")
 		return nil
 	}
 
-	sort.Sort(SortedReleases(*rls))
 	const dateForm = "2006-01-02T15:04:05"
 	data := [][]string{{"Release Name", "Created At", "Notes"}}
 	for _, r := range *rls {
@@ -78,7 +87,7 @@ func CmdList(svcName string, ir IReleases, is services.IServices) error {
 
 func (r *SReleases) List(svcID string) (*[]models.Release, error) {
 	headers := r.Settings.HTTPManager.GetHeaders(r.Settings.SessionToken, r.Settings.Version, r.Settings.Pod, r.Settings.UsersID)
-	resp, statusCode, err := r.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/releases", r.Settings.PaasHost, r.Settings.PaasHostVersion, r.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := r.Settings.HTTPManager.Get(r.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/releases", r.Settings.PaasHost, r.Settings.PaasHostVersion, r.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +101,7 @@ func (r *SReleases) List(svcID string) (*[]models.Release, error) {
 
 func (r *SReleases) Retrieve(releaseName, svcID string) (*models.Release, error) {
 	headers := r.Settings.HTTPManager.GetHeaders(r.Settings.SessionToken, r.Settings.Version, r.Settings.Pod, r.Settings.UsersID)
-	resp, statusCode, err := r.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/releases/%s", r.Settings.PaasHost, r.Settings.PaasHostVersion, r.Settings.EnvironmentID, svcID, releaseName), headers)
+	resp, statusCode, err := r.Settings.HTTPManager.Get(r.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/releases/%s", r.Settings.PaasHost, r.Settings.PaasHostVersion, r.Settings.EnvironmentID, svcID, releaseName), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -30,7 +30,7 @@ func CmdRm(svcName, releaseName string, ir IReleases, is services.IServices) err
 
 func (r *SReleases) Rm(releaseName, svcID string) error {
 	headers := r.Settings.HTTPManager.GetHeaders(r.Settings.SessionToken, r.Settings.Version, r.Settings.Pod, r.Settings.UsersID)
-	resp, statusCode, err := r.Settings.HTTPManager.Delete(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/releases/%s", r.Settings.PaasHost, r.Settings.PaasHostVersion, r.Settings.EnvironmentID, svcID, releaseName), headers)
+	resp, statusCode, err := r.Settings.HTTPManager.Delete(r.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/releases/%s", r.Settings.PaasHost, r.Settings.PaasHostVersion, r.Settings.EnvironmentID, svcID, releaseName), headers)
 	if err != nil {
 		return err
 	}
@@ -4,18 +4,25 @@ import (
 	"fmt"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/lib/volumes"
 	"github.com/daticahealth/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
 
 // CmdServices lists the names of all services for an environment.
-func CmdServices(is IServices, v volumes.IVolumes) error {
+func CmdServices(is IServices, v volumes.IVolumes, out output.IOutput) error {
 	svcs, err := is.List()
 
 	if err != nil {
 		return err
 	}
+	return out.Render(svcs, func() error {
+		return printServices(svcs, v)
+	})
+}
+
+func printServices(svcs *[]models.Service, v volumes.IVolumes) error {
 	if svcs == nil || len(*svcs) == 0 {
 		logrus.Println("No services found")
 		return nil
@@ -63,7 +70,7 @@ func (s *SServices) List() (*[]models.Service, error) {
 
 func (s *SServices) ListByEnvID(envID, podID string) (*[]models.Service, error) {
 	headers := s.Settings.HTTPManager.GetHeaders(s.Settings.SessionToken, s.Settings.Version, podID, s.Settings.UsersID)
-	resp, statusCode, err := s.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services", s.Settings.PaasHost, s.Settings.PaasHostVersion, envID), headers)
+	resp, statusCode, err := s.Settings.HTTPManager.Get(s.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services", s.Settings.PaasHost, s.Settings.PaasHostVersion, envID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +84,7 @@ func (s *SServices) ListByEnvID(envID, podID string) (*[]models.Service, error)
 
 func (s *SServices) Retrieve(svcID string) (*models.Service, error) {
 	headers := s.Settings.HTTPManager.GetHeaders(s.Settings.SessionToken, s.Settings.Version, s.Settings.Pod, s.Settings.UsersID)
-	resp, statusCode, err := s.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := s.Settings.HTTPManager.Get(s.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return nil, err
 	}
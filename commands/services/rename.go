@@ -31,7 +31,7 @@ func (s *SServices) Update(svcID string, updates map[string]string) error {
 		return err
 	}
 	headers := s.Settings.HTTPManager.GetHeaders(s.Settings.SessionToken, s.Settings.Version, s.Settings.Pod, s.Settings.UsersID)
-	resp, statusCode, err := s.Settings.HTTPManager.Put(b, fmt.Sprintf("%s%s/environments/%s/services/%s", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := s.Settings.HTTPManager.Put(s.Settings.Context, b, fmt.Sprintf("%s%s/environments/%s/services/%s", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return err
 	}
@@ -54,7 +54,7 @@ func (s *SSites) Create(name, cert, upstreamServiceID, svcID string, siteValues
 		return nil, err
 	}
 	headers := s.Settings.HTTPManager.GetHeaders(s.Settings.SessionToken, s.Settings.Version, s.Settings.Pod, s.Settings.UsersID)
-	resp, statusCode, err := s.Settings.HTTPManager.Post(b, fmt.Sprintf("%s%s/environments/%s/services/%s/sites", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := s.Settings.HTTPManager.Post(s.Settings.Context, b, fmt.Sprintf("%s%s/environments/%s/services/%s/sites", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -5,11 +5,12 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
 
-func CmdList(is ISites, iservices services.IServices, downStream string) error {
+func CmdList(is ISites, iservices services.IServices, downStream string, out output.IOutput) error {
 	serviceProxy, err := iservices.RetrieveByLabel(downStream)
 	if err != nil {
 		return err
@@ -18,11 +19,20 @@ func CmdList(is ISites, iservices services.IServices, downStream string) error {
 	if err != nil {
 		return err
 	}
+	return out.Render(sites, func() error {
+		return printSites(sites, iservices)
+	})
+}
+
+func printSites(sites *[]models.Site, iservices services.IServices) error {
 	if sites == nil || len(*sites) == 0 {
 		logrus.Println("No sites found")
 		return nil
 	}
 	svcs, err := iservices.List()
+	if err != nil {
+		return err
+	}
 	svcMap := map[string]string{}
 	for _, s := range *svcs {
 		svcMap[s.ID] = s.Label
@@ -46,7 +56,7 @@ func CmdList(is ISites, iservices services.IServices, downStream string) error {
 
 func (s *SSites) List(svcID string) (*[]models.Site, error) {
 	headers := s.Settings.HTTPManager.GetHeaders(s.Settings.SessionToken, s.Settings.Version, s.Settings.Pod, s.Settings.UsersID)
-	resp, statusCode, err := s.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/sites", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := s.Settings.HTTPManager.Get(s.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/sites", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -38,7 +38,7 @@ func CmdRm(name string, is ISites, iservices services.IServices, downStream stri
 
 func (s *SSites) Rm(siteID int, svcID string) error {
 	headers := s.Settings.HTTPManager.GetHeaders(s.Settings.SessionToken, s.Settings.Version, s.Settings.Pod, s.Settings.UsersID)
-	resp, statusCode, err := s.Settings.HTTPManager.Delete(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/sites/%d", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID, siteID), headers)
+	resp, statusCode, err := s.Settings.HTTPManager.Delete(s.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/sites/%d", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID, siteID), headers)
 	if err != nil {
 		return err
 	}
@@ -5,11 +5,12 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/models"
 	"github.com/forana/simpletable"
 )
 
-func CmdShow(name string, is ISites, iservices services.IServices, downStream string) error {
+func CmdShow(name string, is ISites, iservices services.IServices, downStream string, out output.IOutput) error {
 	serviceProxy, err := iservices.RetrieveByLabel(downStream)
 	if err != nil {
 		return err
@@ -32,17 +33,19 @@ func CmdShow(name string, is ISites, iservices services.IServices, downStream st
 	if err != nil {
 		return err
 	}
-	table, err := simpletable.New(simpletable.HeadersForType(models.Site{}), []models.Site{*site})
-	if err != nil {
-		return err
-	}
-	table.Write(logrus.StandardLogger().Out)
-	return nil
+	return out.Render(site, func() error {
+		table, err := simpletable.New(simpletable.HeadersForType(models.Site{}), []models.Site{*site})
+		if err != nil {
+			return err
+		}
+		table.Write(logrus.StandardLogger().Out)
+		return nil
+	})
 }
 
 func (s *SSites) Retrieve(siteID int, svcID string) (*models.Site, error) {
 	headers := s.Settings.HTTPManager.GetHeaders(s.Settings.SessionToken, s.Settings.Version, s.Settings.Pod, s.Settings.UsersID)
-	resp, statusCode, err := s.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/sites/%d", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID, siteID), headers)
+	resp, statusCode, err := s.Settings.HTTPManager.Get(s.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/sites/%d", s.Settings.PaasHost, s.Settings.PaasHostVersion, s.Settings.EnvironmentID, svcID, siteID), headers)
 	if err != nil {
 		return nil, err
 	}
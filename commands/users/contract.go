@@ -5,6 +5,7 @@ import (
 	"github.com/daticahealth/cli/commands/invites"
 	"github.com/daticahealth/cli/config"
 	"github.com/daticahealth/cli/lib/auth"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/lib/prompts"
 	"github.com/daticahealth/cli/models"
 	"github.com/jault3/mow.cli"
@@ -41,7 +42,7 @@ var ListSubCmd = models.Command{
 				if err := config.CheckRequiredAssociation(settings); err != nil {
 					logrus.Fatal(err.Error())
 				}
-				err := CmdList(settings.UsersID, New(settings), invites.New(settings))
+				err := CmdList(settings.UsersID, New(settings), invites.New(settings), output.New(settings))
 				if err != nil {
 					logrus.Fatal(err.Error())
 				}
@@ -6,15 +6,22 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/invites"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
 
-func CmdList(myUsersID string, iu IUsers, ii invites.IInvites) error {
+func CmdList(myUsersID string, iu IUsers, ii invites.IInvites, out output.IOutput) error {
 	orgUsers, err := iu.List()
 	if err != nil {
 		return err
 	}
+	return out.Render(orgUsers, func() error {
+		return printUsers(orgUsers, ii)
+	})
+}
+
+func printUsers(orgUsers *[]models.OrgUser, ii invites.IInvites) error {
 	if orgUsers == nil || len(*orgUsers) == 0 {
 		logrus.Println("No users found")
 		return nil
@@ -51,7 +58,7 @@ func CmdList(myUsersID string, iu IUsers, ii invites.IInvites) error {
 
 func (u *SUsers) List() (*[]models.OrgUser, error) {
 	headers := u.Settings.HTTPManager.GetHeaders(u.Settings.SessionToken, u.Settings.Version, u.Settings.Pod, u.Settings.UsersID)
-	resp, statusCode, err := u.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/orgs/%s/users", u.Settings.AuthHost, u.Settings.AuthHostVersion, u.Settings.OrgID), headers)
+	resp, statusCode, err := u.Settings.HTTPManager.Get(u.Settings.Context, nil, fmt.Sprintf("%s%s/orgs/%s/users", u.Settings.AuthHost, u.Settings.AuthHostVersion, u.Settings.OrgID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +32,7 @@ func CmdRm(email string, iu IUsers) error {
 
 func (u *SUsers) Rm(usersID string) error {
 	headers := u.Settings.HTTPManager.GetHeaders(u.Settings.SessionToken, u.Settings.Version, u.Settings.Pod, u.Settings.UsersID)
-	resp, statusCode, err := u.Settings.HTTPManager.Delete(nil, fmt.Sprintf("%s%s/orgs/%s/users/%s", u.Settings.AuthHost, u.Settings.AuthHostVersion, u.Settings.OrgID, usersID), headers)
+	resp, statusCode, err := u.Settings.HTTPManager.Delete(u.Settings.Context, nil, fmt.Sprintf("%s%s/orgs/%s/users/%s", u.Settings.AuthHost, u.Settings.AuthHostVersion, u.Settings.OrgID, usersID), headers)
 	if err != nil {
 		return err
 	}
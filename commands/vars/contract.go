@@ -5,6 +5,7 @@ import (
 	"github.com/daticahealth/cli/commands/services"
 	"github.com/daticahealth/cli/config"
 	"github.com/daticahealth/cli/lib/auth"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/lib/prompts"
 	"github.com/daticahealth/cli/models"
 	"github.com/jault3/mow.cli"
@@ -29,7 +30,7 @@ var ListSubCmd = models.Command{
 	Name:      "list",
 	ShortHelp: "List all environment variables",
 	LongHelp: "<code>vars list</code> prints out all known environment variables for the given code service. " +
-		"You can print out environment variables in JSON or YAML format through the <code>--json</code> or <code>--yaml</code> flags. " +
+		"You can print out environment variables in JSON or YAML format through the <code>--json</code> or <code>--yaml</code> flags or the global <code>--output</code> option. " +
 		"Here are some sample commands\n\n" +
 		"<pre>\ndatica -E \"<your_env_name>\" vars list code-1\n" +
 		"datica -E \"<your_env_name>\" vars list code-1 --json\n</pre>",
@@ -46,9 +47,9 @@ var ListSubCmd = models.Command{
 					logrus.Fatal(err.Error())
 				}
 				var formatter Formatter
-				if *json {
+				if *json || settings.OutputFormat == output.JSON {
 					formatter = &JSONFormatter{}
-				} else if *yaml {
+				} else if *yaml || settings.OutputFormat == output.YAML {
 					formatter = &YAMLFormatter{}
 				} else {
 					formatter = &PlainFormatter{}
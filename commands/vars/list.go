@@ -76,7 +76,7 @@ func CmdList(svcName string, formatter Formatter, iv IVars, is services.IService
 // List lists all environment variables.
 func (v *SVars) List(svcID string) (map[string]string, error) {
 	headers := v.Settings.HTTPManager.GetHeaders(v.Settings.SessionToken, v.Settings.Version, v.Settings.Pod, v.Settings.UsersID)
-	resp, statusCode, err := v.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/env", v.Settings.PaasHost, v.Settings.PaasHostVersion, v.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := v.Settings.HTTPManager.Get(v.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/env", v.Settings.PaasHost, v.Settings.PaasHostVersion, v.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -30,7 +30,7 @@ func CmdUnset(svcName string, variables []string, iv IVars, is services.IService
 // or via `datica redeploy`.
 func (v *SVars) Unset(svcID, variable string) error {
 	headers := v.Settings.HTTPManager.GetHeaders(v.Settings.SessionToken, v.Settings.Version, v.Settings.Pod, v.Settings.UsersID)
-	resp, statusCode, err := v.Settings.HTTPManager.Delete(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/env/%s", v.Settings.PaasHost, v.Settings.PaasHostVersion, v.Settings.EnvironmentID, svcID, variable), headers)
+	resp, statusCode, err := v.Settings.HTTPManager.Delete(v.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/env/%s", v.Settings.PaasHost, v.Settings.PaasHostVersion, v.Settings.EnvironmentID, svcID, variable), headers)
 	if err != nil {
 		return err
 	}
@@ -6,11 +6,12 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/commands/services"
 	"github.com/daticahealth/cli/lib/jobs"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/models"
 	"github.com/olekukonko/tablewriter"
 )
 
-func CmdList(svcName string, iw IWorker, is services.IServices, ij jobs.IJobs) error {
+func CmdList(svcName string, iw IWorker, is services.IServices, ij jobs.IJobs, out output.IOutput) error {
 	service, err := is.RetrieveByLabel(svcName)
 	if err != nil {
 		return err
@@ -22,7 +23,12 @@ func CmdList(svcName string, iw IWorker, is services.IServices, ij jobs.IJobs) e
 	if err != nil {
 		return err
 	}
+	return out.Render(workers, func() error {
+		return printWorkers(svcName, service, workers, ij)
+	})
+}
 
+func printWorkers(svcName string, service *models.Service, workers *models.Workers, ij jobs.IJobs) error {
 	jobs, err := ij.RetrieveByType(service.ID, "worker", 1, 1000)
 	if err != nil {
 		return err
@@ -70,7 +76,7 @@ func CmdList(svcName string, iw IWorker, is services.IServices, ij jobs.IJobs) e
 
 func (w *SWorker) Retrieve(svcID string) (*models.Workers, error) {
 	headers := w.Settings.HTTPManager.GetHeaders(w.Settings.SessionToken, w.Settings.Version, w.Settings.Pod, w.Settings.UsersID)
-	resp, statusCode, err := w.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/workers", w.Settings.PaasHost, w.Settings.PaasHostVersion, w.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := w.Settings.HTTPManager.Get(w.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/workers", w.Settings.PaasHost, w.Settings.PaasHostVersion, w.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -85,7 +85,7 @@ func (w *SWorker) Update(svcID string, workers *models.Workers) error {
 		return err
 	}
 	headers := w.Settings.HTTPManager.GetHeaders(w.Settings.SessionToken, w.Settings.Version, w.Settings.Pod, w.Settings.UsersID)
-	resp, statusCode, err := w.Settings.HTTPManager.Post(b, fmt.Sprintf("%s%s/environments/%s/services/%s/workers", w.Settings.PaasHost, w.Settings.PaasHostVersion, w.Settings.EnvironmentID, svcID), headers)
+	resp, statusCode, err := w.Settings.HTTPManager.Post(w.Settings.Context, b, fmt.Sprintf("%s%s/environments/%s/services/%s/workers", w.Settings.PaasHost, w.Settings.PaasHostVersion, w.Settings.EnvironmentID, svcID), headers)
 	if err != nil {
 		return err
 	}
@@ -27,6 +27,12 @@ const (
 	JobPollTime = 5
 	// LogPollTime is the amount of time in seconds to wait between polls for new logs
 	LogPollTime = 3
+	// MaxRetries is the number of times a request failing with a transient error is retried
+	MaxRetries = 3
+	// RetryBaseDelay is the amount of time in milliseconds the retry backoff starts at
+	RetryBaseDelay = 500
+	// RetryMaxDelay is the maximum amount of time in seconds to wait between retries
+	RetryMaxDelay = 30
 
 	// AccountsHostEnvVar is the env variable used to override AccountsHost
 	AccountsHostEnvVar = "ACCOUNTS_HOST"
@@ -48,6 +54,24 @@ const (
 	LogLevelEnvVar = "DATICA_LOG_LEVEL"
 	// SkipVerifyEnvVar is the env variable used to accept invalid SSL certificates
 	SkipVerifyEnvVar = "SKIP_VERIFY"
+	// DaticaProfileEnvVar is the env variable used to override the settings profile used in the current command
+	DaticaProfileEnvVar = "DATICA_PROFILE"
+	// OutputFormatEnvVar is the env variable used to override the output format of list and show commands
+	OutputFormatEnvVar = "DATICA_OUTPUT"
+	// TimeoutEnvVar is the env variable used to limit how long a command may run
+	TimeoutEnvVar = "DATICA_TIMEOUT"
+	// RecordEnvVar is the env variable used to record all requests and responses into a directory
+	RecordEnvVar = "DATICA_RECORD"
+	// ReplayEnvVar is the env variable used to replay requests and responses recorded with RecordEnvVar
+	ReplayEnvVar = "DATICA_REPLAY"
+	// CredentialStoreEnvVar is the env variable used to choose where session tokens are stored (keychain, secret-service, or file)
+	CredentialStoreEnvVar = "DATICA_CREDENTIAL_STORE"
+	// MaxRetriesEnvVar is the env variable used to override MaxRetries
+	MaxRetriesEnvVar = "DATICA_MAX_RETRIES"
+	// RetryMaxDelayEnvVar is the env variable used to override RetryMaxDelay
+	RetryMaxDelayEnvVar = "DATICA_RETRY_MAX_DELAY"
+	// RetryPostEnvVar is the env variable used to opt in to retrying POST requests
+	RetryPostEnvVar = "DATICA_RETRY_POST"
 	// DaticaConfigFile points the CLI at a .datica file
 	DaticaConfigFile = "DATICA_CONFIG_FILE"
 
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// credentialService is the name session tokens are stored under in the OS
+	// secret store
+	credentialService = "datica-cli"
+
+	credentialStoreFile          = "file"
+	credentialStoreKeychain      = "keychain"
+	credentialStoreSecretService = "secret-service"
+)
+
+// CredentialStore keeps the session token for each profile out of the
+// settings file. Get returns an empty string when nothing is stored for the
+// profile.
+type CredentialStore interface {
+	Get(profile string) (string, error)
+	Set(profile, secret string) error
+	Delete(profile string) error
+}
+
+// Credentials is the store used for session tokens. It is chosen once at
+// startup and can be replaced in tests.
+var Credentials = defaultCredentialStore()
+
+// defaultCredentialStore picks the OS secret store when one is available,
+// falling back to a file only readable by the current user. The choice can be
+// forced with the CredentialStoreEnvVar env variable.
+func defaultCredentialStore() CredentialStore {
+	name := os.Getenv(CredentialStoreEnvVar)
+	if name == "" {
+		switch runtime.GOOS {
+		case "darwin":
+			name = credentialStoreKeychain
+		case "linux", "freebsd", "openbsd":
+			name = credentialStoreSecretService
+		default:
+			name = credentialStoreFile
+		}
+	}
+	switch name {
+	case credentialStoreKeychain:
+		if _, err := exec.LookPath("security"); err == nil {
+			return &fallbackCredentialStore{primary: KeychainCredentialStore{}}
+		}
+	case credentialStoreSecretService:
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			return &fallbackCredentialStore{primary: SecretServiceCredentialStore{}}
+		}
+	}
+	return FileCredentialStore{}
+}
+
+// fallbackCredentialStore uses an OS secret store, falling back to the
+// credentials file whenever the OS store cannot be reached, such as on a
+// headless machine without a keyring.
+type fallbackCredentialStore struct {
+	primary CredentialStore
+	file    FileCredentialStore
+}
+
+func (s *fallbackCredentialStore) Get(profile string) (string, error) {
+	secret, err := s.primary.Get(profile)
+	if err == nil && secret != "" {
+		return secret, nil
+	}
+	if err != nil {
+		logrus.Debugf("Could not read from the OS credential store: %s", err)
+	}
+	return s.file.Get(profile)
+}
+
+func (s *fallbackCredentialStore) Set(profile, secret string) error {
+	err := s.primary.Set(profile, secret)
+	if err == nil {
+		return s.file.Delete(profile)
+	}
+	logrus.Debugf("Could not write to the OS credential store, falling back to %s: %s", credentialsFile(), err)
+	return s.file.Set(profile, secret)
+}
+
+func (s *fallbackCredentialStore) Delete(profile string) error {
+	if err := s.primary.Delete(profile); err != nil {
+		logrus.Debugf("Could not delete from the OS credential store: %s", err)
+	}
+	return s.file.Delete(profile)
+}
+
+// FileCredentialStore keeps session tokens in a file next to the settings file
+// that only the current user can read.
+type FileCredentialStore struct{}
+
+// credentialsFile is the path of the file used by FileCredentialStore
+func credentialsFile() string {
+	return SettingsFile + ".credentials"
+}
+
+func (s FileCredentialStore) read() (map[string]string, error) {
+	secrets := map[string]string{}
+	b, err := ioutil.ReadFile(credentialsFile())
+	if os.IsNotExist(err) {
+		return secrets, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return secrets, nil
+	}
+	if err = json.Unmarshal(b, &secrets); err != nil {
+		return nil, fmt.Errorf("Invalid or corrupt credentials file. Please remove %s and sign in again", credentialsFile())
+	}
+	return secrets, nil
+}
+
+func (s FileCredentialStore) update(f func(secrets map[string]string)) error {
+	unlock, err := lockFile(credentialsFile())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+	f(secrets)
+	b, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(credentialsFile(), b, 0600)
+}
+
+func (s FileCredentialStore) Get(profile string) (string, error) {
+	secrets, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	return secrets[profile], nil
+}
+
+func (s FileCredentialStore) Set(profile, secret string) error {
+	return s.update(func(secrets map[string]string) {
+		secrets[profile] = secret
+	})
+}
+
+func (s FileCredentialStore) Delete(profile string) error {
+	if _, err := os.Stat(credentialsFile()); os.IsNotExist(err) {
+		return nil
+	}
+	return s.update(func(secrets map[string]string) {
+		delete(secrets, profile)
+	})
+}
+
+// KeychainCredentialStore keeps session tokens in the macOS keychain
+type KeychainCredentialStore struct{}
+
+func (s KeychainCredentialStore) Get(profile string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", credentialService, "-a", profile, "-w").Output()
+	if err != nil {
+		// exit status 44 means the item could not be found
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.Error() == "exit status 44" {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (s KeychainCredentialStore) Set(profile, secret string) error {
+	// the secret is passed on stdin so it never shows up in the process list
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %q -w %q\n", credentialService, profile, secret))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (s KeychainCredentialStore) Delete(profile string) error {
+	if token, err := s.Get(profile); err != nil || token == "" {
+		return err
+	}
+	return exec.Command("security", "delete-generic-password", "-s", credentialService, "-a", profile).Run()
+}
+
+// SecretServiceCredentialStore keeps session tokens in the freedesktop secret
+// service, such as GNOME Keyring or KWallet, through secret-tool.
+type SecretServiceCredentialStore struct{}
+
+func (s SecretServiceCredentialStore) Get(profile string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "service", credentialService, "profile", profile)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// secret-tool exits 1 without any output when nothing is stored
+		if stderr.Len() == 0 {
+			return "", nil
+		}
+		return "", fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (s SecretServiceCredentialStore) Set(profile, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", fmt.Sprintf("Datica CLI (%s)", profile), "service", credentialService, "profile", profile)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (s SecretServiceCredentialStore) Delete(profile string) error {
+	return exec.Command("secret-tool", "clear", "service", credentialService, "profile", profile).Run()
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// lockTimeout is how long to wait for another datica process to release a lock
+	lockTimeout = 10 * time.Second
+	// lockStale is the age after which a lock is assumed to be left behind by a
+	// process that crashed
+	lockStale = 30 * time.Second
+)
+
+// lockFile takes an exclusive lock on path by creating path.lock, waiting for
+// any other datica process holding it. The returned func releases the lock.
+func lockFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("Timed out waiting for another datica command to finish writing %s. If no other datica command is running, remove %s and try again.", path, lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// writeFileAtomic writes b to a temporary file next to path and renames it
+// into place, so readers never see a partially written file.
+func writeFileAtomic(path string, b []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -4,9 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/daticahealth/cli/models"
@@ -16,13 +16,29 @@ import (
 const (
 	settingsFormatV1 = "v1"
 	settingsFormatV2 = "v2"
+	settingsFormatV3 = "v3"
 
 	OldSettingsFile = ".catalyze"
-	currentFormat   = settingsFormatV2
+	currentFormat   = settingsFormatV3
+
+	// DefaultProfile is the profile used when none has been chosen
+	DefaultProfile = "default"
 )
 
 var SettingsFile = resolveSettingsPath()
 
+// storedTokens holds the session token loaded for each profile so the
+// credential store is only written when a token changes.
+var storedTokens = map[string]string{}
+var storedTokensLock sync.Mutex
+
+// loadedProfiles holds the profile names and current profile last read from or
+// written to the settings file, so SaveSettings only writes back the changes
+// made by this command.
+var loadedProfiles = map[string]bool{}
+var loadedCurrentProfile string
+var loadedProfilesLock sync.Mutex
+
 func resolveSettingsPath() string {
 	settingsPath := os.Getenv(DaticaConfigFile)
 	if len(settingsPath) == 0 {
@@ -40,15 +56,17 @@ func resolveSettingsPath() string {
 // for retrieving settings based on the settings file or generating a settings
 // object based on a directly entered environment ID and service ID.
 type SettingsRetriever interface {
-	GetSettings(string, string, string, string, string, string, string, string, string) (*models.Settings, error)
+	GetSettings(string, string, string, string, string, string, string, string, string, string) (*models.Settings, error)
 }
 
 // FileSettingsRetriever reads in data from the SettingsFile and generates a
 // settings object.
 type FileSettingsRetriever struct{}
 
-// GetSettings returns a Settings object for the current context
-func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authHost, ignoreAuthHostVersion, paasHost, ignorePaasHostVersion, email, password string) (*models.Settings, error) {
+// GetSettings returns a Settings object for the current context. The given
+// profile is loaded from the settings file, falling back to the current profile
+// if none is given. Hosts given here override those stored on the profile.
+func (s FileSettingsRetriever) GetSettings(profileName, envName, svcName, accountsHost, authHost, ignoreAuthHostVersion, paasHost, ignorePaasHostVersion, email, password string) (*models.Settings, error) {
 	home, err := homedir.Dir()
 	if err != nil {
 		return nil, err
@@ -61,25 +79,52 @@ func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authH
 		}
 	}
 
-	file, err := os.Open(SettingsFile)
-	if os.IsNotExist(err) {
-		file, err = os.Create(SettingsFile)
-	}
-	defer file.Close()
+	stored, err := readSettingsFile()
 	if err != nil {
 		return nil, err
 	}
-	var settings models.Settings
-	json.NewDecoder(file).Decode(&settings)
-	if settings.Format != currentFormat {
-		if settings.Format == "" {
-			settings.Format = "v1"
+	loadedProfilesLock.Lock()
+	loadedProfiles = map[string]bool{}
+	for name := range stored.Profiles {
+		loadedProfiles[name] = true
+	}
+	loadedCurrentProfile = stored.CurrentProfile
+	loadedProfilesLock.Unlock()
+	if profileName == "" {
+		profileName = stored.CurrentProfile
+	}
+	profile, ok := stored.Profiles[profileName]
+	if !ok {
+		if profileName != DefaultProfile {
+			return nil, fmt.Errorf("Could not find a profile named \"%s\". You can list profiles with the \"datica profile list\" command.", profileName)
 		}
-		file.Seek(0, 0)
-		settings, err = migrateSettings(file, settings.Format, currentFormat)
+		stored.Profiles[profileName] = profile
+	}
+
+	// tokens are kept in the credential store, unless this profile was saved by
+	// an older version of the CLI
+	sessionToken := profile.SessionToken
+	if sessionToken == "" {
+		sessionToken, err = Credentials.Get(profileName)
 		if err != nil {
-			return nil, err
+			logrus.Debugf("Could not read the session token for profile %s: %s", profileName, err)
 		}
+		storedTokensLock.Lock()
+		storedTokens[profileName] = sessionToken
+		storedTokensLock.Unlock()
+	}
+
+	settings := models.Settings{
+		Profile:        profileName,
+		CurrentProfile: stored.CurrentProfile,
+		Profiles:       stored.Profiles,
+		PrivateKeyPath: profile.PrivateKeyPath,
+		SessionToken:   sessionToken,
+		UsersID:        profile.UsersID,
+		Environments:   profile.Environments,
+		Pods:           profile.Pods,
+		PodCheck:       profile.PodCheck,
+		Format:         stored.Format,
 	}
 	if settings.Environments == nil {
 		settings.Environments = make(map[string]models.AssociatedEnvV2)
@@ -90,9 +135,9 @@ func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authH
 		SetGivenEnv(envName, &settings)
 	}
 
-	settings.AccountsHost = accountsHost
-	settings.AuthHost = authHost
-	settings.PaasHost = paasHost
+	settings.AccountsHost = resolveHost(accountsHost, profile.AccountsHost, AccountsHost)
+	settings.AuthHost = resolveHost(authHost, profile.AuthHost, AuthHost)
+	settings.PaasHost = resolveHost(paasHost, profile.PaasHost, PaasHost)
 	settings.Email = email
 	settings.Password = password
 
@@ -108,9 +153,10 @@ func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authH
 	}
 	settings.PaasHostVersion = paasHostVersion
 
-	logrus.Debugf("Accounts Host: %s", accountsHost)
-	logrus.Debugf("Auth Host: %s", authHost)
-	logrus.Debugf("Paas Host: %s", paasHost)
+	logrus.Debugf("Profile: %s", settings.Profile)
+	logrus.Debugf("Accounts Host: %s", settings.AccountsHost)
+	logrus.Debugf("Auth Host: %s", settings.AuthHost)
+	logrus.Debugf("Paas Host: %s", settings.PaasHost)
 	logrus.Debugf("Auth Host Version: %s", authHostVersion)
 	logrus.Debugf("Paas Host Version: %s", paasHostVersion)
 	logrus.Debugf("Environment ID: %s", settings.EnvironmentID)
@@ -122,6 +168,50 @@ func (s FileSettingsRetriever) GetSettings(envName, svcName, accountsHost, authH
 	return &settings, nil
 }
 
+// readSettingsFile reads the settings file, migrating it to the current format
+// and creating it if it does not exist.
+func readSettingsFile() (models.SettingsV3, error) {
+	file, err := os.Open(SettingsFile)
+	if os.IsNotExist(err) {
+		file, err = os.OpenFile(SettingsFile, os.O_RDWR|os.O_CREATE, 0600)
+	}
+	defer file.Close()
+	if err != nil {
+		return models.SettingsV3{}, err
+	}
+	var stored models.SettingsV3
+	json.NewDecoder(file).Decode(&stored)
+	if stored.Format != currentFormat {
+		if stored.Format == "" {
+			stored.Format = "v1"
+		}
+		file.Seek(0, 0)
+		stored, err = migrateSettings(file, stored.Format, currentFormat)
+		if err != nil {
+			return models.SettingsV3{}, err
+		}
+	}
+	if stored.Profiles == nil {
+		stored.Profiles = make(map[string]models.Profile)
+	}
+	if stored.CurrentProfile == "" {
+		stored.CurrentProfile = DefaultProfile
+	}
+	return stored, nil
+}
+
+// resolveHost picks the host given on the command line or through an env
+// variable, then the host saved on the profile, then the production default.
+func resolveHost(given, profile, fallback string) string {
+	if given != "" {
+		return given
+	}
+	if profile != "" {
+		return profile
+	}
+	return fallback
+}
+
 func StoreEnvironments(envs *[]models.Environment, settings *models.Settings) {
 	settings.Environments = map[string]models.AssociatedEnvV2{}
 	for _, env := range *envs {
@@ -134,25 +224,34 @@ func StoreEnvironments(envs *[]models.Environment, settings *models.Settings) {
 	}
 }
 
-func migrateSettings(file *os.File, oldFormat, newFormat string) (models.Settings, error) {
-	if oldFormat == "v1" {
-		return migrateFromV1(file)
+func migrateSettings(file *os.File, oldFormat, newFormat string) (models.SettingsV3, error) {
+	switch oldFormat {
+	case settingsFormatV1:
+		v2, err := migrateFromV1(file)
+		if err != nil {
+			return models.SettingsV3{}, err
+		}
+		return migrateFromV2(v2), nil
+	case settingsFormatV2:
+		var v2 models.SettingsV2
+		json.NewDecoder(file).Decode(&v2)
+		return migrateFromV2(v2), nil
 	}
-	return models.Settings{}, fmt.Errorf("Invalid or corrupt settings file. Please fix the %s file in your home directory or contact Datica support", SettingsFile)
+	return models.SettingsV3{}, fmt.Errorf("Invalid or corrupt settings file. Please fix the %s file in your home directory or contact Datica support", SettingsFile)
 }
 
-func migrateFromV1(file *os.File) (models.Settings, error) {
-	logrus.Debugf("Migrating settings from %s to %s", settingsFormatV1, currentFormat)
+func migrateFromV1(file *os.File) (models.SettingsV2, error) {
+	logrus.Debugf("Migrating settings from %s to %s", settingsFormatV1, settingsFormatV2)
 	var oldSettings models.SettingsV1
 	json.NewDecoder(file).Decode(&oldSettings)
-	newSettings := models.Settings{
+	newSettings := models.SettingsV2{
 		PrivateKeyPath: oldSettings.PrivateKeyPath,
 		SessionToken:   oldSettings.SessionToken,
 		UsersID:        oldSettings.UsersID,
 		Environments:   map[string]models.AssociatedEnvV2{},
 		Pods:           oldSettings.Pods,
 		PodCheck:       oldSettings.PodCheck,
-		Format:         currentFormat,
+		Format:         settingsFormatV2,
 	}
 	for _, env := range oldSettings.Environments {
 		newSettings.Environments[env.EnvironmentID] = models.AssociatedEnvV2{
@@ -165,10 +264,138 @@ func migrateFromV1(file *os.File) (models.Settings, error) {
 	return newSettings, nil
 }
 
-// SaveSettings persists the settings to disk
+// migrateFromV2 moves the single set of credentials in a v2 settings file into
+// the default profile.
+func migrateFromV2(oldSettings models.SettingsV2) models.SettingsV3 {
+	logrus.Debugf("Migrating settings from %s to %s", settingsFormatV2, settingsFormatV3)
+	return models.SettingsV3{
+		CurrentProfile: DefaultProfile,
+		Profiles: map[string]models.Profile{
+			DefaultProfile: models.Profile{
+				PrivateKeyPath: oldSettings.PrivateKeyPath,
+				SessionToken:   oldSettings.SessionToken,
+				UsersID:        oldSettings.UsersID,
+				Environments:   oldSettings.Environments,
+				Pods:           oldSettings.Pods,
+				PodCheck:       oldSettings.PodCheck,
+			},
+		},
+		Format: settingsFormatV3,
+	}
+}
+
+// SaveSettings persists the settings to disk. The settings file is read again
+// while holding a lock and only the changes made by this command are written
+// back: the credentials and environments of the profile in use, profiles that
+// were added or removed, and the current profile if it was changed. Everything
+// else is kept as other commands left it. Session tokens are moved into the
+// credential store and the settings file is replaced atomically, so concurrent
+// commands never leave a partially written file.
 func SaveSettings(settings *models.Settings) error {
-	b, _ := json.Marshal(&settings)
-	return ioutil.WriteFile(SettingsFile, b, 0644)
+	if settings.Profiles == nil {
+		settings.Profiles = map[string]models.Profile{}
+	}
+	profileName := settings.Profile
+	if profileName == "" {
+		profileName = DefaultProfile
+	}
+	currentProfile := settings.CurrentProfile
+	if currentProfile == "" {
+		currentProfile = DefaultProfile
+	}
+
+	unlock, err := lockFile(SettingsFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	stored, err := readSettingsFile()
+	if err != nil {
+		return err
+	}
+	loadedProfilesLock.Lock()
+	defer loadedProfilesLock.Unlock()
+	for name := range loadedProfiles {
+		if _, ok := settings.Profiles[name]; !ok {
+			delete(stored.Profiles, name)
+		}
+	}
+	for name, p := range settings.Profiles {
+		if !loadedProfiles[name] {
+			stored.Profiles[name] = p
+		}
+	}
+	if currentProfile != loadedCurrentProfile {
+		stored.CurrentProfile = currentProfile
+	}
+
+	profile, ok := stored.Profiles[profileName]
+	if !ok {
+		profile = settings.Profiles[profileName]
+	}
+	profile.PrivateKeyPath = settings.PrivateKeyPath
+	profile.SessionToken = settings.SessionToken
+	profile.UsersID = settings.UsersID
+	profile.Environments = settings.Environments
+	profile.Pods = settings.Pods
+	profile.PodCheck = settings.PodCheck
+	stored.Profiles[profileName] = profile
+
+	profiles := map[string]models.Profile{}
+	for name, p := range stored.Profiles {
+		if err := storeToken(name, p.SessionToken); err != nil {
+			return err
+		}
+		p.SessionToken = ""
+		profiles[name] = p
+	}
+	written := models.SettingsV3{
+		CurrentProfile: stored.CurrentProfile,
+		Profiles:       profiles,
+		Format:         currentFormat,
+	}
+	b, err := json.Marshal(&written)
+	if err != nil {
+		return err
+	}
+	if err = writeFileAtomic(SettingsFile, b, 0600); err != nil {
+		return err
+	}
+
+	// the settings now match the file, including changes from other commands
+	settings.Profiles = stored.Profiles
+	settings.CurrentProfile = stored.CurrentProfile
+	loadedProfiles = map[string]bool{}
+	for name := range stored.Profiles {
+		loadedProfiles[name] = true
+	}
+	loadedCurrentProfile = stored.CurrentProfile
+	return nil
+}
+
+// storeToken saves the session token for a profile in the credential store if
+// it changed since the settings were loaded.
+func storeToken(profileName, sessionToken string) error {
+	storedTokensLock.Lock()
+	defer storedTokensLock.Unlock()
+	if stored, ok := storedTokens[profileName]; ok && stored == sessionToken {
+		return nil
+	}
+	if _, ok := storedTokens[profileName]; !ok && sessionToken == "" {
+		// never loaded and nothing to migrate
+		return nil
+	}
+	var err error
+	if sessionToken == "" {
+		err = Credentials.Delete(profileName)
+	} else {
+		err = Credentials.Set(profileName, sessionToken)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to save the session token: %s", err)
+	}
+	storedTokens[profileName] = sessionToken
+	return nil
 }
 
 // SetGivenEnv takes the given env name and finds it in the env list
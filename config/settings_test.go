@@ -0,0 +1,185 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/daticahealth/cli/models"
+)
+
+func TestMigrateFromV2(t *testing.T) {
+	f, err := ioutil.TempFile("", "datica-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	b, _ := json.Marshal(models.SettingsV2{
+		SessionToken: "token",
+		UsersID:      "user",
+		Environments: map[string]models.AssociatedEnvV2{
+			"env": models.AssociatedEnvV2{EnvironmentID: "env", Name: "prod"},
+		},
+		Format: settingsFormatV2,
+	})
+	f.Write(b)
+	f.Close()
+	defer os.Remove(f.Name() + ".credentials")
+	oldSettingsFile := SettingsFile
+	SettingsFile = f.Name()
+	defer func() { SettingsFile = oldSettingsFile }()
+	oldCredentials := Credentials
+	Credentials = FileCredentialStore{}
+	defer func() { Credentials = oldCredentials }()
+
+	settings, err := FileSettingsRetriever{}.GetSettings("", "prod", "", "", "", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if settings.Profile != DefaultProfile || settings.SessionToken != "token" || settings.EnvironmentID != "env" {
+		t.Errorf("v2 settings were not migrated into the default profile: %+v", settings)
+	}
+	if settings.PaasHost != PaasHost {
+		t.Errorf("Expected the default PaaS host, got %s", settings.PaasHost)
+	}
+	if err = SaveSettings(settings); err != nil {
+		t.Fatalf("Unexpected error saving settings: %s", err)
+	}
+	var stored models.SettingsV3
+	b, _ = ioutil.ReadFile(f.Name())
+	json.Unmarshal(b, &stored)
+	if stored.Format != settingsFormatV3 || stored.Profiles[DefaultProfile].UsersID != "user" || strings.Contains(string(b), "token") {
+		t.Errorf("Unexpected settings file contents: %s", string(b))
+	}
+	if token, _ := Credentials.Get(DefaultProfile); token != "token" {
+		t.Errorf("Expected the session token to be moved to the credential store, got %s", token)
+	}
+	for _, name := range []string{f.Name(), f.Name() + ".credentials"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("Expected %s to only be readable by the current user, got %s", name, info.Mode().Perm())
+		}
+	}
+
+	settings, err = FileSettingsRetriever{}.GetSettings("", "", "", "", "", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if settings.SessionToken != "token" {
+		t.Errorf("Expected the session token to be read from the credential store, got %s", settings.SessionToken)
+	}
+}
+
+func TestSaveSettingsConcurrently(t *testing.T) {
+	f, err := ioutil.TempFile("", "datica-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	defer os.Remove(f.Name() + ".credentials")
+	oldSettingsFile := SettingsFile
+	SettingsFile = f.Name()
+	defer func() { SettingsFile = oldSettingsFile }()
+	oldCredentials := Credentials
+	Credentials = FileCredentialStore{}
+	defer func() { Credentials = oldCredentials }()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			settings := &models.Settings{
+				Profile:      DefaultProfile,
+				UsersID:      fmt.Sprintf("user%d", i),
+				Environments: map[string]models.AssociatedEnvV2{},
+			}
+			if err := SaveSettings(settings); err != nil {
+				t.Errorf("Unexpected error saving settings: %s", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var stored models.SettingsV3
+	b, _ := ioutil.ReadFile(f.Name())
+	if err = json.Unmarshal(b, &stored); err != nil {
+		t.Errorf("Settings file was corrupted: %s", string(b))
+	}
+}
+
+func TestSaveSettingsKeepsOtherChanges(t *testing.T) {
+	f, err := ioutil.TempFile("", "datica-settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(models.SettingsV3{
+		CurrentProfile: DefaultProfile,
+		Profiles: map[string]models.Profile{
+			DefaultProfile: models.Profile{UsersID: "user"},
+			"old":          models.Profile{UsersID: "old-user"},
+		},
+		Format: settingsFormatV3,
+	})
+	f.Write(b)
+	f.Close()
+	defer os.Remove(f.Name())
+	defer os.Remove(f.Name() + ".credentials")
+	oldSettingsFile := SettingsFile
+	SettingsFile = f.Name()
+	defer func() { SettingsFile = oldSettingsFile }()
+	oldCredentials := Credentials
+	Credentials = FileCredentialStore{}
+	defer func() { Credentials = oldCredentials }()
+
+	settings, err := FileSettingsRetriever{}.GetSettings("", "", "", "", "", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// another command adds a profile, removes one, and switches to the new one
+	// while this command is running
+	b, _ = json.Marshal(models.SettingsV3{
+		CurrentProfile: "staging",
+		Profiles: map[string]models.Profile{
+			DefaultProfile: models.Profile{UsersID: "user"},
+			"staging":      models.Profile{UsersID: "staging-user", AuthHost: "https://auth.example.com"},
+		},
+		Format: settingsFormatV3,
+	})
+	if err = ioutil.WriteFile(f.Name(), b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// test
+	settings.UsersID = "new-user"
+	err = SaveSettings(settings)
+
+	// assert
+	if err != nil {
+		t.Fatalf("Unexpected error saving settings: %s", err)
+	}
+	var stored models.SettingsV3
+	b, _ = ioutil.ReadFile(f.Name())
+	json.Unmarshal(b, &stored)
+	if stored.CurrentProfile != "staging" {
+		t.Errorf("Expected the current profile chosen by the other command to be kept: %s", string(b))
+	}
+	if stored.Profiles["staging"].UsersID != "staging-user" || stored.Profiles["staging"].AuthHost != "https://auth.example.com" {
+		t.Errorf("Expected the profile added by the other command to be kept: %s", string(b))
+	}
+	if _, ok := stored.Profiles["old"]; ok {
+		t.Errorf("Expected the profile removed by the other command to stay removed: %s", string(b))
+	}
+	if stored.Profiles[DefaultProfile].UsersID != "new-user" {
+		t.Errorf("Expected the profile in use to be saved: %s", string(b))
+	}
+}
@@ -1,15 +1,22 @@
 package datica
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/signal"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/daticahealth/cli/commands/apply"
 	"github.com/daticahealth/cli/commands/certs"
 	"github.com/daticahealth/cli/commands/clear"
+	"github.com/daticahealth/cli/commands/completion"
 	"github.com/daticahealth/cli/commands/console"
+	"github.com/daticahealth/cli/commands/crypto"
 	"github.com/daticahealth/cli/commands/db"
 	"github.com/daticahealth/cli/commands/deploy"
 	"github.com/daticahealth/cli/commands/deploykeys"
@@ -26,6 +33,8 @@ import (
 	"github.com/daticahealth/cli/commands/logs"
 	"github.com/daticahealth/cli/commands/maintenance"
 	"github.com/daticahealth/cli/commands/metrics"
+	"github.com/daticahealth/cli/commands/plugins"
+	"github.com/daticahealth/cli/commands/profile"
 	"github.com/daticahealth/cli/commands/rake"
 	"github.com/daticahealth/cli/commands/redeploy"
 	"github.com/daticahealth/cli/commands/releases"
@@ -47,6 +56,7 @@ import (
 
 	"github.com/daticahealth/cli/lib/auth"
 	"github.com/daticahealth/cli/lib/httpclient"
+	"github.com/daticahealth/cli/lib/output"
 	"github.com/daticahealth/cli/lib/pods"
 	"github.com/daticahealth/cli/lib/prompts"
 	"github.com/daticahealth/cli/lib/updater"
@@ -96,52 +106,124 @@ func Run() {
 	InitGlobalOpts(app, settings)
 	InitCLI(app, settings)
 
-	app.Run(os.Args)
+	if len(os.Args) > 1 && os.Args[1] == completion.CompleteArg {
+		// only completions may be printed, and a slow or unreachable API
+		// must not hang the shell
+		logrus.SetOutput(ioutil.Discard)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		completion.CmdComplete(app, os.Args[2:], func(profile, env string) completion.IValues {
+			return completion.NewFromSettingsFile(ctx, profile, env)
+		})
+		return
+	}
+
+	app.Run(InitPlugin(app, settings, os.Args))
+}
+
+// globalValueOpts are the names of the global options that take a separate
+// value. It is filled in by InitGlobalOpts as the options are registered.
+var globalValueOpts = map[string]bool{}
+
+// globalString registers a global option that takes a value and records its
+// names in globalValueOpts so InitPlugin can skip over the value
+func globalString(app *cli.Cli, opt cli.StringOpt) *string {
+	for _, name := range strings.Fields(opt.Name) {
+		if len(name) == 1 {
+			globalValueOpts["-"+name] = true
+		} else {
+			globalValueOpts["--"+name] = true
+		}
+	}
+	return app.String(opt)
+}
+
+// InitPlugin registers a plugin command when the command given in args is not
+// a built in command but a plugin with that name is on the PATH. The args
+// after the plugin name are handed to the plugin as is, so only the args up to
+// and including the plugin name are returned for the CLI to parse.
+func InitPlugin(app *cli.Cli, settings *models.Settings, args []string) []string {
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return args
+		}
+		if strings.HasPrefix(arg, "-") {
+			if globalValueOpts[arg] {
+				i++
+			}
+			continue
+		}
+		for _, c := range app.Commands {
+			if c.Name == arg {
+				return args
+			}
+		}
+		plugin, err := plugins.New(settings).Find(arg)
+		if err != nil {
+			return args
+		}
+		logrus.Debugf("Running plugin %s", plugin.Path)
+		cmd := plugins.PluginCmd(plugin, args[i+1:])
+		app.CommandLong(cmd.Name, cmd.ShortHelp, cmd.LongHelp, cmd.CmdFunc(settings))
+		return args[:i+1]
+	}
+	return args
 }
 
 func InitGlobalOpts(app *cli.Cli, settings *models.Settings) {
+	// hosts not overridden here fall back to the profile, then production
 	accountsHost := os.Getenv(config.AccountsHostEnvVar)
-	if accountsHost == "" {
-		accountsHost = config.AccountsHost
-	}
 	authHost := os.Getenv(config.AuthHostEnvVar)
-	if authHost == "" {
-		authHost = config.AuthHost
-	}
 	paasHost := os.Getenv(config.PaasHostEnvVar)
-	if paasHost == "" {
-		paasHost = config.PaasHost
-	}
-	email := app.String(cli.StringOpt{
+	email := globalString(app, cli.StringOpt{
 		Name:      "email",
 		Desc:      "Datica Email",
 		EnvVar:    config.DaticaEmailEnvVar,
 		HideValue: true,
 	})
-	username := app.String(cli.StringOpt{
+	username := globalString(app, cli.StringOpt{
 		Name:      "U username",
 		Desc:      "[DEPRECATED] Datica Username (This flag is deprecated. Please use --email instead)",
 		EnvVar:    config.DaticaUsernameEnvVarDeprecated,
 		HideValue: true,
 	})
-	password := app.String(cli.StringOpt{
+	password := globalString(app, cli.StringOpt{
 		Name:      "P password",
 		Desc:      "Datica Password",
 		EnvVar:    config.DaticaPasswordEnvVar,
 		HideValue: true,
 	})
-	givenEnvName := app.String(cli.StringOpt{
+	profileName := globalString(app, cli.StringOpt{
+		Name:      "profile",
+		Desc:      "The name of the settings profile to use for this command",
+		EnvVar:    config.DaticaProfileEnvVar,
+		HideValue: true,
+	})
+	givenEnvName := globalString(app, cli.StringOpt{
 		Name:      "E env",
 		Desc:      "The name of the environment in which this command will be run",
 		EnvVar:    config.DaticaEnvironmentEnvVar,
 		HideValue: true,
 	})
+	outputFormat := globalString(app, cli.StringOpt{
+		Name:   "output",
+		Value:  output.Table,
+		Desc:   fmt.Sprintf("The format used by list and show commands (%s)", strings.Join(output.Formats, ", ")),
+		EnvVar: config.OutputFormatEnvVar,
+	})
+	timeout := globalString(app, cli.StringOpt{
+		Name:   "timeout",
+		Desc:   "The maximum amount of time this command may run, such as 90s or 30m. By default there is no limit",
+		EnvVar: config.TimeoutEnvVar,
+	})
 	if loggingLevel := os.Getenv(config.LogLevelEnvVar); loggingLevel != "" {
 		if lvl, err := logrus.ParseLevel(loggingLevel); err == nil {
 			logrus.SetLevel(lvl)
 		}
 	}
 
+	cancel := func() {}
 	app.Before = func() {
 		if *email == "" {
 			*email = *username
@@ -152,15 +234,53 @@ func InitGlobalOpts(app *cli.Cli, settings *models.Settings) {
 		if config.Beta {
 			logrus.Println("This is a BETA release. Please contact Datica Support at https://datica.com/support with any issues.")
 		}
+		if err := output.Validate(*outputFormat); err != nil {
+			logrus.Println(err)
+			cli.Exit(1)
+		}
+		var timeoutDuration time.Duration
+		if *timeout != "" {
+			d, err := time.ParseDuration(*timeout)
+			if err != nil || d <= 0 {
+				logrus.Printf("Invalid timeout \"%s\". Please specify a positive duration such as 90s or 30m.", *timeout)
+				cli.Exit(1)
+			}
+			timeoutDuration = d
+		}
 		r := config.FileSettingsRetriever{}
-		s, err := r.GetSettings(*givenEnvName, "", accountsHost, authHost, "", paasHost, "", *email, *password)
+		s, err := r.GetSettings(*profileName, *givenEnvName, "", accountsHost, authHost, "", paasHost, "", *email, *password)
 		if err != nil {
 			logrus.Println(err)
 			cli.Exit(1)
 		}
 		*settings = *s
+		settings.OutputFormat = *outputFormat
+		settings.Context, cancel = commandContext(timeoutDuration)
 		skip, _ := strconv.ParseBool(os.Getenv(config.SkipVerifyEnvVar))
-		settings.HTTPManager = httpclient.NewTLSHTTPManager(skip)
+		retryPolicy := httpclient.DefaultRetryPolicy()
+		if maxRetries, err := strconv.Atoi(os.Getenv(config.MaxRetriesEnvVar)); err == nil && maxRetries >= 0 {
+			retryPolicy.MaxRetries = maxRetries
+		}
+		if maxDelay, err := strconv.Atoi(os.Getenv(config.RetryMaxDelayEnvVar)); err == nil && maxDelay > 0 {
+			retryPolicy.MaxDelay = time.Duration(maxDelay) * time.Second
+		}
+		retryPolicy.RetryPOST, _ = strconv.ParseBool(os.Getenv(config.RetryPostEnvVar))
+		settings.HTTPManager = httpclient.NewTLSHTTPManagerWithRetryPolicy(skip, retryPolicy)
+		if dir := os.Getenv(config.ReplayEnvVar); dir != "" {
+			settings.HTTPManager, err = httpclient.NewReplayHTTPManager(settings.HTTPManager, dir)
+			if err != nil {
+				logrus.Println(err)
+				cli.Exit(1)
+			}
+			logrus.Debugf("Replaying requests from %s", dir)
+		} else if dir := os.Getenv(config.RecordEnvVar); dir != "" {
+			settings.HTTPManager, err = httpclient.NewRecordingHTTPManager(settings.HTTPManager, dir)
+			if err != nil {
+				logrus.Println(err)
+				cli.Exit(1)
+			}
+			logrus.Debugf("Recording requests to %s", dir)
+		}
 		logrus.Debugf("%+v", settings)
 
 		if settings.Pods == nil || len(*settings.Pods) == 0 || settings.PodCheck < time.Now().Unix() {
@@ -187,6 +307,7 @@ func InitGlobalOpts(app *cli.Cli, settings *models.Settings) {
 		}
 	}
 	app.After = func() {
+		cancel()
 		config.SaveSettings(settings)
 	}
 
@@ -199,6 +320,32 @@ func InitGlobalOpts(app *cli.Cli, settings *models.Settings) {
 	app.Version("v version", versionString)
 }
 
+// commandContext returns the context for the current command. It is cancelled
+// on the first interrupt or once the timeout expires, whichever comes first,
+// so requests and polling can stop cleanly. A second interrupt exits
+// immediately.
+func commandContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			logrus.Println("\nInterrupted. Stopping the current operation, press Ctrl-C again to exit immediately.")
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(interrupt)
+	}()
+	return ctx, cancel
+}
+
 // InitLogrus sets up logrus for the correctly formatted log messages
 func InitLogrus() {
 	logrus.SetFormatter(&simpleLogger{})
@@ -208,9 +355,12 @@ func InitLogrus() {
 
 // InitCLI adds arguments and commands to the given cli instance
 func InitCLI(app *cli.Cli, settings *models.Settings) {
+	app.CommandLong(apply.Cmd.Name, apply.Cmd.ShortHelp, apply.Cmd.LongHelp, apply.Cmd.CmdFunc(settings))
 	app.CommandLong(certs.Cmd.Name, certs.Cmd.ShortHelp, certs.Cmd.LongHelp, certs.Cmd.CmdFunc(settings))
 	app.CommandLong(clear.Cmd.Name, clear.Cmd.ShortHelp, clear.Cmd.LongHelp, clear.Cmd.CmdFunc(settings))
+	app.CommandLong(completion.Cmd.Name, completion.Cmd.ShortHelp, completion.Cmd.LongHelp, completion.Cmd.CmdFunc(settings))
 	app.CommandLong(console.Cmd.Name, console.Cmd.ShortHelp, console.Cmd.LongHelp, console.Cmd.CmdFunc(settings))
+	app.CommandLong(crypto.Cmd.Name, crypto.Cmd.ShortHelp, crypto.Cmd.LongHelp, crypto.Cmd.CmdFunc(settings))
 	app.CommandLong(db.Cmd.Name, db.Cmd.ShortHelp, db.Cmd.LongHelp, db.Cmd.CmdFunc(settings))
 	app.CommandLong(deploy.Cmd.Name, deploy.Cmd.ShortHelp, deploy.Cmd.LongHelp, deploy.Cmd.CmdFunc(settings))
 	app.CommandLong(deploykeys.Cmd.Name, deploykeys.Cmd.ShortHelp, deploykeys.Cmd.LongHelp, deploykeys.Cmd.CmdFunc(settings))
@@ -227,6 +377,9 @@ func InitCLI(app *cli.Cli, settings *models.Settings) {
 	app.CommandLong(logs.Cmd.Name, logs.Cmd.ShortHelp, logs.Cmd.LongHelp, logs.Cmd.CmdFunc(settings))
 	app.CommandLong(maintenance.Cmd.Name, maintenance.Cmd.ShortHelp, maintenance.Cmd.LongHelp, maintenance.Cmd.CmdFunc(settings))
 	app.CommandLong(metrics.Cmd.Name, metrics.Cmd.ShortHelp, metrics.Cmd.LongHelp, metrics.Cmd.CmdFunc(settings))
+	app.CommandLong(apply.PlanCmd.Name, apply.PlanCmd.ShortHelp, apply.PlanCmd.LongHelp, apply.PlanCmd.CmdFunc(settings))
+	app.CommandLong(plugins.Cmd.Name, plugins.Cmd.ShortHelp, plugins.Cmd.LongHelp, plugins.Cmd.CmdFunc(settings))
+	app.CommandLong(profile.Cmd.Name, profile.Cmd.ShortHelp, profile.Cmd.LongHelp, profile.Cmd.CmdFunc(settings))
 	app.CommandLong(rake.Cmd.Name, rake.Cmd.ShortHelp, rake.Cmd.LongHelp, rake.Cmd.CmdFunc(settings))
 	app.CommandLong(redeploy.Cmd.Name, redeploy.Cmd.ShortHelp, redeploy.Cmd.LongHelp, redeploy.Cmd.CmdFunc(settings))
 	app.CommandLong(releases.Cmd.Name, releases.Cmd.ShortHelp, releases.Cmd.LongHelp, releases.Cmd.CmdFunc(settings))
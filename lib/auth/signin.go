@@ -90,7 +90,7 @@ func (a *SAuth) signInWithCredentials() (*signinResponse, error) {
 		return nil, err
 	}
 	headers := a.Settings.HTTPManager.GetHeaders(a.Settings.SessionToken, a.Settings.Version, a.Settings.Pod, a.Settings.UsersID)
-	resp, statusCode, err := a.Settings.HTTPManager.Post(b, fmt.Sprintf("%s%s/auth/signin", a.Settings.AuthHost, a.Settings.AuthHostVersion), headers)
+	resp, statusCode, err := a.Settings.HTTPManager.Post(a.Settings.Context, b, fmt.Sprintf("%s%s/auth/signin", a.Settings.AuthHost, a.Settings.AuthHostVersion), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +143,7 @@ func (a *SAuth) signInWithKey() (*signinResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, statusCode, err := a.Settings.HTTPManager.Post(b, fmt.Sprintf("%s%s/auth/signin/key", a.Settings.AuthHost, a.Settings.AuthHostVersion), headers)
+	resp, statusCode, err := a.Settings.HTTPManager.Post(a.Settings.Context, b, fmt.Sprintf("%s%s/auth/signin/key", a.Settings.AuthHost, a.Settings.AuthHostVersion), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -160,7 +160,7 @@ func (a *SAuth) mfaSignin(mfaID string, preferredMode string) (*models.User, err
 	if err != nil {
 		return nil, err
 	}
-	resp, statusCode, err := a.Settings.HTTPManager.Post(b, fmt.Sprintf("%s%s/auth/signin/mfa/%s", a.Settings.AuthHost, a.Settings.AuthHostVersion, mfaID), headers)
+	resp, statusCode, err := a.Settings.HTTPManager.Post(a.Settings.Context, b, fmt.Sprintf("%s%s/auth/signin/mfa/%s", a.Settings.AuthHost, a.Settings.AuthHostVersion, mfaID), headers)
 	user := &models.User{}
 	err = a.Settings.HTTPManager.ConvertResp(resp, statusCode, user)
 	if err != nil {
@@ -172,7 +172,7 @@ func (a *SAuth) mfaSignin(mfaID string, preferredMode string) (*models.User, err
 // Signout signs out a user by their session token.
 func (a *SAuth) Signout() error {
 	headers := a.Settings.HTTPManager.GetHeaders(a.Settings.SessionToken, a.Settings.Version, a.Settings.Pod, a.Settings.UsersID)
-	resp, statusCode, err := a.Settings.HTTPManager.Delete(nil, fmt.Sprintf("%s%s/auth/signout", a.Settings.AuthHost, a.Settings.AuthHostVersion), headers)
+	resp, statusCode, err := a.Settings.HTTPManager.Delete(a.Settings.Context, nil, fmt.Sprintf("%s%s/auth/signout", a.Settings.AuthHost, a.Settings.AuthHostVersion), headers)
 	if err != nil {
 		return err
 	}
@@ -183,7 +183,7 @@ func (a *SAuth) Signout() error {
 // valid, the returned error will be nil.
 func (a *SAuth) Verify() (*models.User, error) {
 	headers := a.Settings.HTTPManager.GetHeaders(a.Settings.SessionToken, a.Settings.Version, a.Settings.Pod, a.Settings.UsersID)
-	resp, statusCode, err := a.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/auth/verify", a.Settings.AuthHost, a.Settings.AuthHostVersion), headers)
+	resp, statusCode, err := a.Settings.HTTPManager.Get(a.Settings.Context, nil, fmt.Sprintf("%s%s/auth/verify", a.Settings.AuthHost, a.Settings.AuthHostVersion), headers)
 	if err != nil {
 		return nil, err
 	}
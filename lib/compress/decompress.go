@@ -2,6 +2,7 @@ package compress
 
 import (
 	"compress/gzip"
+	"fmt"
 	"io"
 )
 
@@ -9,33 +10,37 @@ import (
 type DecompressWriteCloser struct {
 	dst io.WriteCloser
 
-	cpw *io.PipeWriter
-	cpr *io.PipeReader
-	done chan bool
+	cpw  *io.PipeWriter
+	cpr  *io.PipeReader
+	done chan error
 }
 
 // NewDecompressWriteCloser takes an io.WriteCloser and wraps it in a type
 // that will decompress Writes to the io.WriteCloser as they are written.
+// Invalid or truncated compressed data is reported by Write or Close.
 func (c *SCompress) NewDecompressWriteCloser(writeCloser io.WriteCloser) (*DecompressWriteCloser, error) {
 	// Supported compression format: gzip
 	pr, pw := io.Pipe()
-	done := make(chan bool)
+	done := make(chan error, 1)
 	go func() {
 		// goroutine adapted per Zhang Xiaofeng (2017)
-		defer pw.Close()
 		decompressReader, err := gzip.NewReader(pr)
-		defer decompressReader.Close()
+		if err == nil {
+			_, err = io.Copy(writeCloser, decompressReader)
+			decompressReader.Close()
+		}
 		if err != nil {
-			pw.CloseWithError(err)
+			err = fmt.Errorf("Failed to decompress: %s", err)
 		}
-		io.Copy(writeCloser, decompressReader)
-		done <- true
+		// any further writes fail with the error
+		pr.CloseWithError(err)
+		done <- err
 	}()
 	return &DecompressWriteCloser{
 		dst: writeCloser,
 
-		cpw: pw,
-		cpr: pr,
+		cpw:  pw,
+		cpr:  pr,
 		done: done,
 	}, nil
 }
@@ -46,11 +51,10 @@ func (w *DecompressWriteCloser) Write(p []byte) (int, error) {
 
 func (w *DecompressWriteCloser) Close() error {
 	// Close pipe, block until decompress is done, then close dest
-	w.cpr.Close()
-	_ = <-w.done
-	return w.dst.Close()
-}
-
-func (w *DecompressWriteCloser) open() error {
-	return nil
+	w.cpw.Close()
+	err := <-w.done
+	if cerr := w.dst.Close(); err == nil {
+		err = cerr
+	}
+	return err
 }
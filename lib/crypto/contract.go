@@ -12,6 +12,8 @@ type ICrypto interface {
 	EncryptFile(plainFilePath string, key, iv []byte) (string, error)
 	NewEncryptReader(reader io.Reader, key, iv []byte) (*gcm.EncryptReader, error)
 	NewDecryptWriteCloser(writeCloser io.WriteCloser, key, iv string) (*gcm.DecryptWriteCloser, error)
+	NewEncryptToWriteCloser(dst io.WriteCloser, recipient Recipient) (io.WriteCloser, error)
+	NewDecryptFromReader(src io.Reader, identity Identity) (io.Reader, error)
 	Hex(src []byte, maxLen int) []byte
 	Unhex(src []byte, maxLen int) []byte
 	Base64Encode(src []byte, maxLen int) []byte
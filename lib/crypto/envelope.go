@@ -0,0 +1,232 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// An encrypted file starts with a text header holding the file key encrypted
+// to each recipient, such as
+//
+//	datica-encrypted/v1
+//	-> X25519 <ephemeral public key>
+//	<encrypted file key>
+//	---
+//
+// followed by the file encrypted with AES-256-GCM in chunks. Unlike backups,
+// the last chunk is marked in its nonce so a truncated file fails to decrypt.
+// Keys use the age encodings, but the file format is not age's and only
+// NewDecryptFromReader can read it.
+const (
+	headerLine = "datica-encrypted/v1"
+	headerEnd  = "---"
+	// envelopeChunkSize is the size of each chunk of plaintext
+	envelopeChunkSize = 64 * 1024
+)
+
+// ErrNoIdentity is returned when a file was not encrypted to the identity
+var ErrNoIdentity = errors.New("The file was not encrypted to this key")
+
+// stanza is the file key encrypted to one recipient
+type stanza struct {
+	Type string
+	Args []string
+	Body []byte
+}
+
+// NewEncryptToWriteCloser encrypts everything written to it to the recipient,
+// writing the encrypted file to dst. Close must be called to write the last
+// chunk.
+func (c *SCrypto) NewEncryptToWriteCloser(dst io.WriteCloser, recipient Recipient) (io.WriteCloser, error) {
+	fileKey := make([]byte, KeySize)
+	if _, err := rand.Read(fileKey); err != nil {
+		return nil, err
+	}
+	s, err := recipient.wrap(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("%s\n-> %s\n%s\n%s\n", headerLine, strings.Join(append([]string{s.Type}, s.Args...), " "), b64.EncodeToString(s.Body), headerEnd)
+	if _, err = io.WriteString(dst, header); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &envelopeWriteCloser{dst: dst, aead: aead, buf: make([]byte, 0, envelopeChunkSize+1)}, nil
+}
+
+type envelopeWriteCloser struct {
+	dst     io.WriteCloser
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+}
+
+func (w *envelopeWriteCloser) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// a full chunk is only sealed once more follows it, so that the last
+		// chunk is never empty unless the whole file is
+		if len(w.buf) == envelopeChunkSize {
+			if err := w.seal(false); err != nil {
+				return n - len(p), err
+			}
+		}
+		room := envelopeChunkSize - len(w.buf)
+		if room > len(p) {
+			room = len(p)
+		}
+		w.buf = append(w.buf, p[:room]...)
+		p = p[room:]
+	}
+	return n, nil
+}
+
+func (w *envelopeWriteCloser) Close() error {
+	if err := w.seal(true); err != nil {
+		w.dst.Close()
+		return err
+	}
+	return w.dst.Close()
+}
+
+func (w *envelopeWriteCloser) seal(last bool) error {
+	sealed := w.aead.Seal(nil, chunkNonce(w.counter, last), w.buf, nil)
+	w.counter++
+	w.buf = w.buf[:0]
+	_, err := w.dst.Write(sealed)
+	return err
+}
+
+// NewDecryptFromReader reads the header of an encrypted file from src and
+// returns a reader of the decrypted file. Every chunk is authenticated before
+// it is returned, and a file that ends early fails with an error.
+func (c *SCrypto) NewDecryptFromReader(src io.Reader, identity Identity) (io.Reader, error) {
+	br := bufio.NewReader(src)
+	stanzas, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	var fileKey []byte
+	for _, s := range stanzas {
+		fileKey, err = identity.unwrap(s)
+		if err != nil {
+			return nil, err
+		}
+		if fileKey != nil {
+			break
+		}
+	}
+	if len(fileKey) != KeySize {
+		return nil, ErrNoIdentity
+	}
+	aead, err := newAEAD(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &envelopeReader{src: br, aead: aead, sealed: make([]byte, envelopeChunkSize+aead.Overhead())}, nil
+}
+
+func readHeader(br *bufio.Reader) ([]*stanza, error) {
+	invalid := errors.New("The file is not a datica encrypted file")
+	line, err := br.ReadString('\n')
+	if err != nil || strings.TrimSuffix(line, "\n") != headerLine {
+		return nil, invalid
+	}
+	stanzas := []*stanza{}
+	for {
+		line, err = br.ReadString('\n')
+		if err != nil {
+			return nil, invalid
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == headerEnd {
+			return stanzas, nil
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "->" {
+			return nil, invalid
+		}
+		body, err := br.ReadString('\n')
+		if err != nil {
+			return nil, invalid
+		}
+		decoded, err := b64.DecodeString(strings.TrimSuffix(body, "\n"))
+		if err != nil {
+			return nil, invalid
+		}
+		stanzas = append(stanzas, &stanza{Type: fields[1], Args: fields[2:], Body: decoded})
+	}
+}
+
+type envelopeReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	sealed  []byte
+	plain   []byte
+	counter uint64
+	done    bool
+}
+
+func (r *envelopeReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *envelopeReader) open() error {
+	n, err := io.ReadFull(r.src, r.sealed)
+	last := false
+	switch {
+	case err == io.EOF:
+		return errors.New("The encrypted file is incomplete")
+	case err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		return err
+	default:
+		if _, err = r.src.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+	plain, err := r.aead.Open(r.sealed[:0:0], chunkNonce(r.counter, last), r.sealed[:n], nil)
+	if err != nil {
+		if last {
+			return errors.New("The encrypted file is incomplete or has been modified")
+		}
+		return errors.New("The encrypted file has been modified")
+	}
+	r.counter++
+	r.plain = plain
+	r.done = last
+	return nil
+}
+
+// chunkNonce is the nonce of a chunk, its number followed by whether it is
+// the last chunk
+func chunkNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
@@ -0,0 +1,242 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// bech32Encode is the inverse of bech32Decode, for making age keys in tests
+func bech32Encode(hrp string, data []byte) string {
+	values := []byte{}
+	acc, bits := 0, uint(0)
+	for _, b := range data {
+		acc = (acc<<8 | int(b)) & 0xfff
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			values = append(values, byte(acc>>bits)&31)
+		}
+	}
+	if bits > 0 {
+		values = append(values, byte(acc<<(5-bits))&31)
+	}
+	polymod := bech32Polymod(append(append(bech32ExpandHRP(hrp), values...), 0, 0, 0, 0, 0, 0)) ^ 1
+	for i := 0; i < 6; i++ {
+		values = append(values, byte(polymod>>uint(5*(5-i)))&31)
+	}
+	out := hrp + "1"
+	for _, v := range values {
+		out += string(bech32Charset[v])
+	}
+	return out
+}
+
+// writeKeyFile writes a key file for a test, returning its path
+func writeKeyFile(t *testing.T, contents []byte) string {
+	f, err := ioutil.TempFile("", "datica-key-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(contents)
+	f.Close()
+	return f.Name()
+}
+
+// testKeys makes a new age and RSA key pair, returning the recipients and the
+// paths to the identity files
+func testKeys(t *testing.T) ([]Recipient, []string) {
+	var private, public [32]byte
+	rand.Read(private[:])
+	curve25519.ScalarBaseMult(&public, &private)
+	ageRecipient, err := ParseRecipient(bech32Encode("age", public[:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ageIdentity := writeKeyFile(t, []byte("# created: 2026-10-17\n# public key: "+bech32Encode("age", public[:])+"\n"+strings.ToUpper(bech32Encode("age-secret-key-", private[:]))+"\n"))
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPublic := writeKeyFile(t, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	defer os.Remove(rsaPublic)
+	rsaRecipient, err := ParseRecipient(rsaPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaIdentity := writeKeyFile(t, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+	return []Recipient{ageRecipient, rsaRecipient}, []string{ageIdentity, rsaIdentity}
+}
+
+type closeBuffer struct {
+	bytes.Buffer
+}
+
+func (*closeBuffer) Close() error { return nil }
+
+func encryptTo(t *testing.T, recipient Recipient, plaintext []byte) []byte {
+	out := &closeBuffer{}
+	w, err := New().NewEncryptToWriteCloser(out, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// write in odd sizes to cross chunk boundaries
+	for len(plaintext) > 0 {
+		n := 1000
+		if n > len(plaintext) {
+			n = len(plaintext)
+		}
+		if _, err = w.Write(plaintext[:n]); err != nil {
+			t.Fatal(err)
+		}
+		plaintext = plaintext[n:]
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func decryptFrom(identity Identity, encrypted []byte) ([]byte, error) {
+	r, err := New().NewDecryptFromReader(bytes.NewReader(encrypted), identity)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestEncryptTo(t *testing.T) {
+	recipients, identityPaths := testKeys(t)
+	for _, p := range identityPaths {
+		defer os.Remove(p)
+	}
+	plaintext := make([]byte, 3*envelopeChunkSize+10)
+	rand.Read(plaintext)
+	for i, recipient := range recipients {
+		identity, err := LoadIdentity(identityPaths[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := LoadIdentity(identityPaths[1-i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, size := range []int{0, 1, envelopeChunkSize, envelopeChunkSize + 1, len(plaintext)} {
+			t.Logf("Data: recipient %d, size %d", i, size)
+
+			// test
+			encrypted := encryptTo(t, recipient, plaintext[:size])
+			decrypted, err := decryptFrom(identity, encrypted)
+
+			// assert
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			} else if !bytes.Equal(decrypted, plaintext[:size]) {
+				t.Errorf("Decrypted %d bytes that do not match the %d bytes encrypted", len(decrypted), size)
+			}
+			if _, err = decryptFrom(other, encrypted); err != ErrNoIdentity {
+				t.Errorf("Expected %s with another key but got %v", ErrNoIdentity, err)
+			}
+		}
+	}
+}
+
+func TestDecryptFromModified(t *testing.T) {
+	recipients, identityPaths := testKeys(t)
+	for _, p := range identityPaths {
+		defer os.Remove(p)
+	}
+	identity, err := LoadIdentity(identityPaths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := make([]byte, 2*envelopeChunkSize)
+	encrypted := encryptTo(t, recipients[0], plaintext)
+	headerSize := len(encrypted) - (2*envelopeChunkSize + 2*16)
+	flipped := append([]byte{}, encrypted...)
+	flipped[len(flipped)-1] ^= 1
+	tests := map[string][]byte{
+		"truncated at a chunk":  encrypted[:headerSize+envelopeChunkSize+16],
+		"truncated in a chunk":  encrypted[:len(encrypted)-1],
+		"truncated header":      encrypted[:headerSize-1],
+		"modified":              flipped,
+		"not encrypted":         plaintext,
+		"extra data at the end": append(append([]byte{}, encrypted...), 0),
+	}
+	for description, data := range tests {
+		t.Logf("Data: %s", description)
+
+		// test
+		_, err := decryptFrom(identity, data)
+
+		// assert
+		if err == nil {
+			t.Errorf("Expected an error decrypting a file that is %s", description)
+		}
+	}
+}
+
+func TestParseRecipient(t *testing.T) {
+	// the example recipient from the age documentation
+	if _, err := ParseRecipient("age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	for _, recipient := range []string{
+		"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8q", // bad checksum
+		"age1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq",
+		"does-not-exist.pem",
+	} {
+		if _, err := ParseRecipient(recipient); err == nil {
+			t.Errorf("Expected an error parsing %s", recipient)
+		}
+	}
+}
+
+// byteRange returns the bytes from start up to and including end
+func byteRange(start, end byte) []byte {
+	b := []byte{}
+	for i := int(start); i <= int(end); i++ {
+		b = append(b, byte(i))
+	}
+	return b
+}
+
+// hkdfTests are the HKDF-SHA256 test cases from RFC 5869 appendix A
+var hkdfTests = []struct {
+	secret   []byte
+	salt     []byte
+	info     []byte
+	expected string
+}{
+	{bytes.Repeat([]byte{0x0b}, 22), byteRange(0x00, 0x0c), byteRange(0xf0, 0xf9), "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865"},
+	{byteRange(0x00, 0x4f), byteRange(0x60, 0xaf), byteRange(0xb0, 0xff), "b11e398dc80327a1c8e7f78c596a49344f012eda2d4efad8a050cc4c19afa97c59045a99cac7827271cb41c65e590e09da3275600c2f09b8367793a9aca3db71cc30c58179ec3e87c14c01d5c1f3434f1d87"},
+	{bytes.Repeat([]byte{0x0b}, 22), []byte{}, []byte{}, "8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8"},
+}
+
+func TestHKDF(t *testing.T) {
+	for _, data := range hkdfTests {
+		t.Logf("Data: %+v", data)
+
+		// test
+		actual := hkdf(data.secret, data.salt, data.info, len(data.expected)/2)
+
+		// assert
+		if hex.EncodeToString(actual) != data.expected {
+			t.Errorf("Expected %s but got %x", data.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/curve25519"
+)
+
+const (
+	// x25519Label is the stanza type and key derivation label for X25519
+	// recipients
+	x25519Label = "X25519"
+	// rsaLabel is the stanza type and OAEP label for RSA recipients
+	rsaLabel = "RSA"
+	// minRSABits is the smallest RSA key accepted as a recipient
+	minRSABits = 2048
+)
+
+// Recipient is a public key that a file can be encrypted to
+type Recipient interface {
+	// wrap encrypts a file key into a header stanza
+	wrap(fileKey []byte) (*stanza, error)
+}
+
+// Identity is a private key that can decrypt files encrypted to its public key
+type Identity interface {
+	// unwrap decrypts the file key from a header stanza. A nil key means the
+	// stanza is not for this identity.
+	unwrap(s *stanza) ([]byte, error)
+}
+
+// ParseRecipient reads a recipient from an X25519 public key such as
+// age1..., or from a file holding an X25519 public key or an RSA public key in
+// PEM format
+func ParseRecipient(recipient string) (Recipient, error) {
+	if strings.HasPrefix(recipient, "age1") {
+		return parseX25519Recipient(recipient)
+	}
+	path, err := homedir.Expand(recipient)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if block, _ := pem.Decode(b); block != nil {
+		return parseRSARecipient(block)
+	}
+	for _, line := range keyLines(b) {
+		if strings.HasPrefix(line, "age1") {
+			return parseX25519Recipient(line)
+		}
+	}
+	return nil, fmt.Errorf("%s does not contain an X25519 public key or an RSA public key in PEM format", recipient)
+}
+
+// LoadIdentity reads an X25519 private key such as one made by age-keygen,
+// or an RSA private key in PEM format, from a file
+func LoadIdentity(identityPath string) (Identity, error) {
+	path, err := homedir.Expand(identityPath)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if block, _ := pem.Decode(b); block != nil {
+		return parseRSAIdentity(block)
+	}
+	for _, line := range keyLines(b) {
+		if strings.HasPrefix(line, "AGE-SECRET-KEY-1") {
+			return parseX25519Identity(line)
+		}
+	}
+	return nil, fmt.Errorf("%s does not contain an X25519 private key or an RSA private key in PEM format", identityPath)
+}
+
+// keyLines returns the lines of a key file, skipping blank lines and comments
+func keyLines(b []byte) []string {
+	lines := []string{}
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+type x25519Recipient struct {
+	publicKey [32]byte
+}
+
+func parseX25519Recipient(recipient string) (*x25519Recipient, error) {
+	hrp, data, err := bech32Decode(recipient)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid X25519 public key %s: %s", recipient, err)
+	}
+	if hrp != "age" || len(data) != 32 {
+		return nil, fmt.Errorf("Invalid X25519 public key %s", recipient)
+	}
+	r := &x25519Recipient{}
+	copy(r.publicKey[:], data)
+	return r, nil
+}
+
+func (r *x25519Recipient) wrap(fileKey []byte) (*stanza, error) {
+	var ephemeral, ephemeralShare, shared [32]byte
+	if _, err := rand.Read(ephemeral[:]); err != nil {
+		return nil, err
+	}
+	curve25519.ScalarBaseMult(&ephemeralShare, &ephemeral)
+	curve25519.ScalarMult(&shared, &ephemeral, &r.publicKey)
+	if shared == [32]byte{} {
+		return nil, errors.New("Invalid X25519 public key")
+	}
+	wrapped, err := sealKey(x25519WrapKey(shared, ephemeralShare, r.publicKey), fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &stanza{Type: x25519Label, Args: []string{b64.EncodeToString(ephemeralShare[:])}, Body: wrapped}, nil
+}
+
+type x25519Identity struct {
+	privateKey [32]byte
+	publicKey  [32]byte
+}
+
+func parseX25519Identity(identity string) (*x25519Identity, error) {
+	hrp, data, err := bech32Decode(identity)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid X25519 private key: %s", err)
+	}
+	if hrp != "age-secret-key-" || len(data) != 32 {
+		return nil, errors.New("Invalid X25519 private key")
+	}
+	i := &x25519Identity{}
+	copy(i.privateKey[:], data)
+	curve25519.ScalarBaseMult(&i.publicKey, &i.privateKey)
+	return i, nil
+}
+
+func (i *x25519Identity) unwrap(s *stanza) ([]byte, error) {
+	if s.Type != x25519Label {
+		return nil, nil
+	}
+	if len(s.Args) != 1 {
+		return nil, errors.New("invalid X25519 recipient in the header")
+	}
+	share, err := b64.DecodeString(s.Args[0])
+	if err != nil || len(share) != 32 {
+		return nil, errors.New("invalid X25519 recipient in the header")
+	}
+	var ephemeralShare, shared [32]byte
+	copy(ephemeralShare[:], share)
+	curve25519.ScalarMult(&shared, &i.privateKey, &ephemeralShare)
+	if shared == [32]byte{} {
+		return nil, errors.New("invalid X25519 recipient in the header")
+	}
+	// a stanza for another recipient fails to open
+	fileKey, err := openKey(x25519WrapKey(shared, ephemeralShare, i.publicKey), s.Body)
+	if err != nil {
+		return nil, nil
+	}
+	return fileKey, nil
+}
+
+// x25519WrapKey derives the key that wraps the file key from the shared
+// secret, bound to both public keys
+func x25519WrapKey(shared, ephemeralShare, publicKey [32]byte) []byte {
+	salt := append(append([]byte{}, ephemeralShare[:]...), publicKey[:]...)
+	return hkdf(shared[:], salt, []byte(headerLine+" "+x25519Label), KeySize)
+}
+
+type rsaRecipient struct {
+	publicKey *rsa.PublicKey
+}
+
+func parseRSARecipient(block *pem.Block) (*rsaRecipient, error) {
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("Expected an RSA public key but found a PEM %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("Only RSA public keys are supported in PEM format")
+	}
+	if publicKey.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSABits)
+	}
+	return &rsaRecipient{publicKey: publicKey}, nil
+}
+
+func (r *rsaRecipient) wrap(fileKey []byte) (*stanza, error) {
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, r.publicKey, fileKey, []byte(headerLine+" "+rsaLabel))
+	if err != nil {
+		return nil, err
+	}
+	return &stanza{Type: rsaLabel, Body: wrapped}, nil
+}
+
+type rsaIdentity struct {
+	privateKey *rsa.PrivateKey
+}
+
+func parseRSAIdentity(block *pem.Block) (*rsaIdentity, error) {
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("Expected an RSA private key but found a PEM %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("Only RSA private keys are supported in PEM format")
+	}
+	return &rsaIdentity{privateKey: privateKey}, nil
+}
+
+func (i *rsaIdentity) unwrap(s *stanza) ([]byte, error) {
+	if s.Type != rsaLabel {
+		return nil, nil
+	}
+	fileKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, i.privateKey, s.Body, []byte(headerLine+" "+rsaLabel))
+	if err != nil {
+		// encrypted to another key
+		return nil, nil
+	}
+	return fileKey, nil
+}
+
+var b64 = base64.RawStdEncoding
+
+// sealKey encrypts a file key with a key that is only ever used once, so the
+// nonce is always zero
+func sealKey(key, fileKey []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, make([]byte, aead.NonceSize()), fileKey, nil), nil
+}
+
+func openKey(key, wrapped []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, make([]byte, aead.NonceSize()), wrapped, nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// hkdf is HKDF-SHA256 as described in RFC 5869
+func hkdf(secret, salt, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	prk := extract.Sum(nil)
+	out := []byte{}
+	var previous []byte
+	for i := byte(1); len(out) < length; i++ {
+		expand := hmac.New(sha256.New, prk)
+		expand.Write(previous)
+		expand.Write(info)
+		expand.Write([]byte{i})
+		previous = expand.Sum(nil)
+		out = append(out, previous...)
+	}
+	return out[:length]
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32Decode decodes a bech32 string as described in BIP 173, without its
+// length limit since age private keys are longer than 90 characters
+func bech32Decode(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, errors.New("mixed case")
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndex(s, "1")
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, errors.New("invalid separator")
+	}
+	hrp := s[:sep]
+	values := []byte{}
+	for _, c := range s[sep+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return "", nil, fmt.Errorf("invalid character %q", c)
+		}
+		values = append(values, byte(v))
+	}
+	if bech32Polymod(append(bech32ExpandHRP(hrp), values...)) != 1 {
+		return "", nil, errors.New("invalid checksum")
+	}
+	// convert the 5 bit groups, less the checksum, to bytes
+	data := []byte{}
+	acc, bits := 0, uint(0)
+	for _, v := range values[:len(values)-6] {
+		acc = (acc<<5 | int(v)) & 0xfff
+		bits += 5
+		for bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return "", nil, errors.New("invalid padding")
+	}
+	return hrp, data, nil
+}
+
+func bech32ExpandHRP(hrp string) []byte {
+	out := []byte{}
+	for _, c := range hrp {
+		out = append(out, byte(c>>5))
+	}
+	out = append(out, 0)
+	for _, c := range hrp {
+		out = append(out, byte(c&31))
+	}
+	return out
+}
+
+func bech32Polymod(values []byte) int {
+	gen := []int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := 1
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ int(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
@@ -0,0 +1,378 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/models"
+)
+
+const redacted = "REDACTED"
+
+// redactedHeaders are never written to a cassette
+var redactedHeaders = map[string]struct{}{
+	"authorization":   struct{}{},
+	"cookie":          struct{}{},
+	"x-request-nonce": struct{}{},
+}
+
+// redactedFields are JSON body fields whose values are never written to a
+// cassette
+var redactedFields = map[string]struct{}{
+	"password":        struct{}{},
+	"token":           struct{}{},
+	"sessiontoken":    struct{}{},
+	"otp":             struct{}{},
+	"key":             struct{}{},
+	"keylogs":         struct{}{},
+	"keyinternallogs": struct{}{},
+	"iv":              struct{}{},
+	"encryptionkey":   struct{}{},
+	"encryptioniv":    struct{}{},
+	"sslpkfile":       struct{}{},
+	"privatekey":      struct{}{},
+	"value":           struct{}{},
+	"url":             struct{}{},
+}
+
+// redactedQueryParams are URL query parameters that sign temporary upload and
+// download URLs
+var redactedQueryParams = map[string]struct{}{
+	"signature":            struct{}{},
+	"temp_url_sig":         struct{}{},
+	"x-amz-signature":      struct{}{},
+	"x-amz-credential":     struct{}{},
+	"x-amz-security-token": struct{}{},
+	"awsaccesskeyid":       struct{}{},
+}
+
+// envVarsPath is the end of the environment variables endpoint, whose bodies
+// are maps of variable names to values
+const envVarsPath = "/env"
+
+// Interaction is a single request and response pair stored in a cassette
+type Interaction struct {
+	Method         string              `json:"method"`
+	URL            string              `json:"url"`
+	RequestHeaders map[string][]string `json:"request_headers"`
+	RequestBody    string              `json:"request_body,omitempty"`
+	RequestFile    string              `json:"request_file,omitempty"`
+	StatusCode     int                 `json:"status_code"`
+	ResponseBody   string              `json:"response_body,omitempty"`
+	Error          string              `json:"error,omitempty"`
+}
+
+// RecordingHTTPManager is an HTTPManager that sends every request through
+// another HTTPManager and saves each request and response to a cassette
+// directory. Secrets are redacted before anything is written to disk.
+type RecordingHTTPManager struct {
+	models.HTTPManager
+	dir  string
+	next int
+	lock sync.Mutex
+}
+
+// NewRecordingHTTPManager returns an HTTPManager that records all requests
+// made through m into dir.
+func NewRecordingHTTPManager(m models.HTTPManager, dir string) (models.HTTPManager, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordingHTTPManager{
+		HTTPManager: m,
+		dir:         dir,
+		next:        len(files),
+	}, nil
+}
+
+// Get performs a GET request and records it
+func (r *RecordingHTTPManager) Get(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	resp, statusCode, err := r.HTTPManager.Get(ctx, body, url, headers)
+	r.record("GET", url, headers, body, "", resp, statusCode, err)
+	return resp, statusCode, err
+}
+
+// Post performs a POST request and records it
+func (r *RecordingHTTPManager) Post(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	resp, statusCode, err := r.HTTPManager.Post(ctx, body, url, headers)
+	r.record("POST", url, headers, body, "", resp, statusCode, err)
+	return resp, statusCode, err
+}
+
+// PostFile uploads a file with a POST and records it. The file contents are
+// not recorded.
+func (r *RecordingHTTPManager) PostFile(ctx context.Context, filepath string, url string, headers map[string][]string) ([]byte, int, error) {
+	resp, statusCode, err := r.HTTPManager.PostFile(ctx, filepath, url, headers)
+	r.record("POST", url, headers, nil, filepath, resp, statusCode, err)
+	return resp, statusCode, err
+}
+
+// PutFile uploads a file with a PUT and records it. The file contents are not
+// recorded.
+func (r *RecordingHTTPManager) PutFile(ctx context.Context, filepath string, url string, headers map[string][]string) ([]byte, int, error) {
+	resp, statusCode, err := r.HTTPManager.PutFile(ctx, filepath, url, headers)
+	r.record("PUT", url, headers, nil, filepath, resp, statusCode, err)
+	return resp, statusCode, err
+}
+
+// Put performs a PUT request and records it
+func (r *RecordingHTTPManager) Put(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	resp, statusCode, err := r.HTTPManager.Put(ctx, body, url, headers)
+	r.record("PUT", url, headers, body, "", resp, statusCode, err)
+	return resp, statusCode, err
+}
+
+// Delete performs a DELETE request and records it
+func (r *RecordingHTTPManager) Delete(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	resp, statusCode, err := r.HTTPManager.Delete(ctx, body, url, headers)
+	r.record("DELETE", url, headers, body, "", resp, statusCode, err)
+	return resp, statusCode, err
+}
+
+// record writes a single interaction to the next file in the cassette. A
+// failure to record never fails the request itself.
+func (r *RecordingHTTPManager) record(method, rawURL string, headers map[string][]string, body []byte, file string, resp []byte, statusCode int, reqErr error) {
+	redactBodyFunc := redactBody
+	if isEnvVarsURL(rawURL) {
+		redactBodyFunc = redactAllValues
+	}
+	interaction := Interaction{
+		Method:         method,
+		URL:            redactURL(rawURL),
+		RequestHeaders: redactHeaders(headers),
+		RequestBody:    redactBodyFunc(body),
+		RequestFile:    file,
+		StatusCode:     statusCode,
+		ResponseBody:   redactBodyFunc(resp),
+	}
+	if reqErr != nil {
+		interaction.Error = reqErr.Error()
+	}
+	b, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		logrus.Debugf("Failed to record %s %s: %s", method, interaction.URL, err)
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	name := filepath.Join(r.dir, fmt.Sprintf("%05d.json", r.next))
+	r.next++
+	if err = ioutil.WriteFile(name, b, 0600); err != nil {
+		logrus.Debugf("Failed to record %s %s: %s", method, interaction.URL, err)
+		return
+	}
+	logrus.Debugf("Recorded %s %s to %s", method, interaction.URL, name)
+}
+
+// ReplayHTTPManager is an HTTPManager that never touches the network. Every
+// request is answered with the next matching interaction from a cassette
+// directory created by a RecordingHTTPManager.
+type ReplayHTTPManager struct {
+	models.HTTPManager
+	interactions []Interaction
+	used         []bool
+	lock         sync.Mutex
+}
+
+// NewReplayHTTPManager loads the cassette in dir and returns an HTTPManager
+// that replays it. Header and response conversion is handled by m.
+func NewReplayHTTPManager(m models.HTTPManager, dir string) (models.HTTPManager, error) {
+	files, err := cassetteFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("No recorded requests found in %s", dir)
+	}
+	interactions := []Interaction{}
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		var interaction Interaction
+		if err = json.Unmarshal(b, &interaction); err != nil {
+			return nil, fmt.Errorf("Invalid recording %s: %s", f, err)
+		}
+		interactions = append(interactions, interaction)
+	}
+	return &ReplayHTTPManager{
+		HTTPManager:  m,
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}, nil
+}
+
+// Get replays a GET request
+func (r *ReplayHTTPManager) Get(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	return r.replay("GET", url)
+}
+
+// Post replays a POST request
+func (r *ReplayHTTPManager) Post(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	return r.replay("POST", url)
+}
+
+// PostFile replays a file upload with a POST
+func (r *ReplayHTTPManager) PostFile(ctx context.Context, filepath string, url string, headers map[string][]string) ([]byte, int, error) {
+	return r.replay("POST", url)
+}
+
+// PutFile replays a file upload with a PUT
+func (r *ReplayHTTPManager) PutFile(ctx context.Context, filepath string, url string, headers map[string][]string) ([]byte, int, error) {
+	return r.replay("PUT", url)
+}
+
+// Put replays a PUT request
+func (r *ReplayHTTPManager) Put(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	return r.replay("PUT", url)
+}
+
+// Delete replays a DELETE request
+func (r *ReplayHTTPManager) Delete(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	return r.replay("DELETE", url)
+}
+
+// replay finds the first unused interaction with the given method and URL.
+// Interactions are used in the order they were recorded so repeated requests,
+// such as polling for a job, see the same sequence of responses.
+func (r *ReplayHTTPManager) replay(method, rawURL string) ([]byte, int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	// recorded URLs have their signatures redacted
+	url := redactURL(rawURL)
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Method != method || interaction.URL != url {
+			continue
+		}
+		r.used[i] = true
+		logrus.Debugf("Replaying %s %s", method, url)
+		if interaction.Error != "" {
+			return nil, 0, fmt.Errorf("%s", interaction.Error)
+		}
+		return []byte(interaction.ResponseBody), interaction.StatusCode, nil
+	}
+	return nil, 0, fmt.Errorf("No recorded response left for %s %s", method, url)
+}
+
+// cassetteFiles lists the recorded interactions in dir in the order they were
+// recorded.
+func cassetteFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// redactHeaders copies headers, replacing the value of any credential header
+func redactHeaders(headers map[string][]string) map[string][]string {
+	result := map[string][]string{}
+	for k, v := range headers {
+		if _, ok := redactedHeaders[strings.ToLower(k)]; ok {
+			result[k] = []string{redacted}
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// redactURL replaces the value of any signature in the query of a temporary
+// URL. URLs that cannot be parsed are stored as is.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	query := u.Query()
+	for k := range query {
+		if _, ok := redactedQueryParams[strings.ToLower(k)]; ok {
+			query.Set(k, redacted)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// isEnvVarsURL reports whether rawURL is a service's environment variables
+// endpoint
+func isEnvVarsURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(strings.TrimSuffix(u.Path, "/"), envVarsPath)
+}
+
+// redactAllValues replaces every value in a JSON object body, keeping only the
+// keys. Bodies that are not JSON objects are replaced entirely.
+func redactAllValues(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return redacted
+	}
+	for k := range v {
+		v[k] = redacted
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return redacted
+	}
+	return string(b)
+}
+
+// redactBody replaces the value of any secret field in a JSON body. Bodies
+// that are not JSON are stored as is.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return string(body)
+	}
+	b, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return string(body)
+	}
+	return string(b)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if _, ok := redactedFields[strings.ToLower(k)]; ok {
+				t[k] = redacted
+				continue
+			}
+			t[k] = redactValue(val)
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = redactValue(val)
+		}
+	}
+	return v
+}
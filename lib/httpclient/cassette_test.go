@@ -0,0 +1,218 @@
+package httpclient_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daticahealth/cli/commands/vars"
+	"github.com/daticahealth/cli/lib/httpclient"
+	"github.com/daticahealth/cli/test"
+)
+
+func TestRecordReplay(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	status := "running"
+	mux.HandleFunc("/jobs/"+test.JobID,
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","status":"%s"}`, test.JobID, status))
+			status = "finished"
+		},
+	)
+	mux.HandleFunc("/auth/signin",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "POST")
+			fmt.Fprint(w, `{"sessionToken":"secret-token"}`)
+		},
+	)
+	settings := test.GetSettings(baseURL.String())
+	headers := settings.HTTPManager.GetHeaders("secret-token", "dev", test.Pod, "user")
+	jobURL := baseURL.String() + "/jobs/" + test.JobID
+	signinURL := baseURL.String() + "/auth/signin"
+
+	// record
+	recorder, err := httpclient.NewRecordingHTTPManager(settings.HTTPManager, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Post(context.Background(), []byte(`{"identifier":"user","password":"secret-password"}`), signinURL, headers)
+	recorder.Get(context.Background(), nil, jobURL, headers)
+	recorder.Get(context.Background(), nil, jobURL, headers)
+	test.Teardown(server)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 recorded interactions, got %d", len(files))
+	}
+	for _, f := range files {
+		b, _ := ioutil.ReadFile(f)
+		if strings.Contains(string(b), "secret-") {
+			t.Errorf("Secret was not redacted from %s: %s", f, string(b))
+		}
+	}
+
+	// replay
+	replayer, err := httpclient.NewReplayHTTPManager(settings.HTTPManager, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"running", "finished"} {
+		resp, statusCode, err := replayer.Get(context.Background(), nil, jobURL, headers)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		test.AssertEquals(t, "200", fmt.Sprintf("%d", statusCode))
+		test.AssertEquals(t, fmt.Sprintf(`{"id":"%s","status":"%s"}`, test.JobID, expected), string(resp))
+	}
+	if _, _, err = replayer.Get(context.Background(), nil, jobURL, headers); err == nil {
+		t.Error("Expected an error once the recording is exhausted, got nil")
+	}
+}
+
+func TestRecordEnvVars(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+test.SvcID+"/env",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"DATABASE_URL":"postgres://admin:secret-db@db:5432","API_KEY":"secret-api"}`)
+		},
+	)
+	settings := test.GetSettings(baseURL.String())
+	settings.HTTPManager, err = httpclient.NewRecordingHTTPManager(settings.HTTPManager, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// test
+	envVars, err := vars.New(settings).List(test.SvcID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = vars.New(settings).Set(test.SvcID, map[string]string{"PASSWORD_SALT": "secret-salt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	test.AssertEquals(t, "secret-api", envVars["API_KEY"])
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 recorded interactions, got %d", len(files))
+	}
+	for _, f := range files {
+		b, _ := ioutil.ReadFile(f)
+		if strings.Contains(string(b), "secret-") {
+			t.Errorf("Environment variable value was not redacted from %s: %s", f, string(b))
+		}
+	}
+	b, _ := ioutil.ReadFile(files[0])
+	if !strings.Contains(string(b), "DATABASE_URL") {
+		t.Errorf("Expected environment variable names to be kept in %s", string(b))
+	}
+}
+
+func TestRecordImportKeys(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mux.HandleFunc("/environments/"+test.EnvID+"/services/"+test.SvcID+"/import",
+		func(w http.ResponseWriter, r *http.Request) {
+			test.AssertEquals(t, r.Method, "POST")
+			fmt.Fprint(w, fmt.Sprintf(`{"id":"%s","status":"queued"}`, test.JobID))
+		},
+	)
+	settings := test.GetSettings(baseURL.String())
+	headers := settings.HTTPManager.GetHeaders("token", "dev", test.Pod, "user")
+	importURL := baseURL.String() + "/environments/" + test.EnvID + "/services/" + test.SvcID + "/import"
+
+	// record
+	recorder, err := httpclient.NewRecordingHTTPManager(settings.HTTPManager, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Post(context.Background(), []byte(`{"filename":"import.sql","encryptionKey":"secret-key","encryptionIV":"secret-iv","dropDatabase":false}`), importURL, headers)
+
+	// assert
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 recorded interaction, got %d", len(files))
+	}
+	b, _ := ioutil.ReadFile(files[0])
+	if strings.Contains(string(b), "secret-") {
+		t.Errorf("Import encryption key was not redacted from %s: %s", files[0], string(b))
+	}
+	if !strings.Contains(string(b), "import.sql") {
+		t.Errorf("Expected the other import parameters to be kept in %s", string(b))
+	}
+}
+
+func TestRecordTempURL(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mux.HandleFunc("/backup-url",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"url":"https://storage.example.com/backup?temp_url_sig=secret-sig&temp_url_expires=1"}`)
+		},
+	)
+	settings := test.GetSettings(baseURL.String())
+	headers := settings.HTTPManager.GetHeaders("token", "dev", test.Pod, "user")
+	backupURL := baseURL.String() + "/backup-url"
+	signedURL := baseURL.String() + "/backup?X-Amz-Signature=secret-sig&X-Amz-Expires=60"
+
+	// record
+	recorder, err := httpclient.NewRecordingHTTPManager(settings.HTTPManager, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.Get(context.Background(), nil, backupURL, headers)
+	recorder.Get(context.Background(), nil, signedURL, headers)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 recorded interactions, got %d", len(files))
+	}
+	for _, f := range files {
+		b, _ := ioutil.ReadFile(f)
+		if strings.Contains(string(b), "secret-") {
+			t.Errorf("Temporary URL was not redacted from %s: %s", f, string(b))
+		}
+	}
+
+	// replay matches the signed URL even though its signature was redacted
+	replayer, err := httpclient.NewReplayHTTPManager(settings.HTTPManager, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = replayer.Get(context.Background(), nil, signedURL, headers); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
@@ -2,13 +2,13 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -26,11 +26,19 @@ const defaultRedirectLimit = 10
 // TLSHTTPManager is a extension of HTTPManager with explicit TLSv1.2 support.
 type TLSHTTPManager struct {
 	client *http.Client
+	retry  RetryPolicy
 }
 
 // NewTLSHTTPManager constructs and returns a new instance of HTTPManager
-// with TLSv1.2 and redirect support.
+// with TLSv1.2 and redirect support using the default retry policy.
 func NewTLSHTTPManager(skipVerify bool) models.HTTPManager {
+	return NewTLSHTTPManagerWithRetryPolicy(skipVerify, DefaultRetryPolicy())
+}
+
+// NewTLSHTTPManagerWithRetryPolicy constructs and returns a new instance of
+// HTTPManager with TLSv1.2 and redirect support that retries transient failures
+// according to the given policy.
+func NewTLSHTTPManagerWithRetryPolicy(skipVerify bool, policy RetryPolicy) models.HTTPManager {
 	var tr = &http.Transport{
 		TLSClientConfig: &tls.Config{
 			MinVersion: tls.VersionTLS12,
@@ -44,6 +52,7 @@ func NewTLSHTTPManager(skipVerify bool) models.HTTPManager {
 			Transport:     tr,
 			CheckRedirect: redirectPolicyFunc,
 		},
+		retry: policy,
 	}
 }
 
@@ -145,80 +154,122 @@ func convertError(b []byte, statusCode int) error {
 }
 
 // Get performs a GET request
-func (m *TLSHTTPManager) Get(body []byte, url string, headers map[string][]string) ([]byte, int, error) {
-	reader := bytes.NewReader(body)
-	return m.makeRequest("GET", url, reader, headers)
+func (m *TLSHTTPManager) Get(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	return m.makeRequest(ctx, "GET", url, body, headers)
 }
 
 // Post performs a POST request
-func (m *TLSHTTPManager) Post(body []byte, url string, headers map[string][]string) ([]byte, int, error) {
-	reader := bytes.NewReader(body)
-	return m.makeRequest("POST", url, reader, headers)
+func (m *TLSHTTPManager) Post(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	return m.makeRequest(ctx, "POST", url, body, headers)
 }
 
 // PostFile uploads a file with a POST
-func (m *TLSHTTPManager) PostFile(filepath string, url string, headers map[string][]string) ([]byte, int, error) {
-	return m.uploadFile("POST", filepath, url, headers)
+func (m *TLSHTTPManager) PostFile(ctx context.Context, filepath string, url string, headers map[string][]string) ([]byte, int, error) {
+	return m.uploadFile(ctx, "POST", filepath, url, headers)
 }
 
 // PutFile uploads a file with a PUT
-func (m *TLSHTTPManager) PutFile(filepath string, url string, headers map[string][]string) ([]byte, int, error) {
-	return m.uploadFile("PUT", filepath, url, headers)
+func (m *TLSHTTPManager) PutFile(ctx context.Context, filepath string, url string, headers map[string][]string) ([]byte, int, error) {
+	return m.uploadFile(ctx, "PUT", filepath, url, headers)
 }
 
-func (m *TLSHTTPManager) uploadFile(method, filepath, url string, headers map[string][]string) ([]byte, int, error) {
+func (m *TLSHTTPManager) uploadFile(ctx context.Context, method, filepath, url string, headers map[string][]string) ([]byte, int, error) {
 	logrus.Debugf("%s %s", method, url)
 	logrus.Debugf("%+v", headers)
 	logrus.Debugf("%s", filepath)
-	file, err := os.Open(filepath)
-	defer file.Close()
-	if err != nil {
-		return nil, 0, err
-	}
-	info, _ := file.Stat()
-	req, _ := http.NewRequest(method, url, file)
-	req.ContentLength = info.Size()
-
-	resp, err := m.client.Do(req)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer resp.Body.Close()
-	respBody, _ := ioutil.ReadAll(resp.Body)
-	return respBody, resp.StatusCode, nil
+	return m.do(ctx, method, url, func() (*http.Request, error) {
+		file, err := os.Open(filepath)
+		if err != nil {
+			return nil, err
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		// the file is closed by the http client once the request is sent
+		req, err := http.NewRequest(method, url, file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		req.ContentLength = info.Size()
+		return req, nil
+	})
 }
 
 // Put performs a PUT request
-func (m *TLSHTTPManager) Put(body []byte, url string, headers map[string][]string) ([]byte, int, error) {
-	reader := bytes.NewReader(body)
-	return m.makeRequest("PUT", url, reader, headers)
+func (m *TLSHTTPManager) Put(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	return m.makeRequest(ctx, "PUT", url, body, headers)
 }
 
 // Delete performs a DELETE request
-func (m *TLSHTTPManager) Delete(body []byte, url string, headers map[string][]string) ([]byte, int, error) {
-	reader := bytes.NewReader(body)
-	return m.makeRequest("DELETE", url, reader, headers)
+func (m *TLSHTTPManager) Delete(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error) {
+	return m.makeRequest(ctx, "DELETE", url, body, headers)
 }
 
 // MakeRequest is a generic HTTP runner that performs a request and returns
 // the result body as a byte array. It's up to the caller to transform them
 // into an object.
-func (m *TLSHTTPManager) makeRequest(method string, url string, body io.Reader, headers map[string][]string) ([]byte, int, error) {
+func (m *TLSHTTPManager) makeRequest(ctx context.Context, method string, url string, body []byte, headers map[string][]string) ([]byte, int, error) {
 	logrus.Debugf("%s %s", method, url)
 	logrus.Debugf("%+v", headers)
 	logrus.Debugf("%s", body)
-	req, _ := http.NewRequest(method, url, body)
-	req.Header = headers
-
-	resp, err := m.client.Do(req)
+	respBody, statusCode, err := m.do(ctx, method, url, func() (*http.Request, error) {
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header = headers
+		return req, nil
+	})
 	if err != nil {
 		return nil, 0, err
 	}
-	defer resp.Body.Close()
-	respBody, _ := ioutil.ReadAll(resp.Body)
-	if resp.StatusCode == 412 {
+	if statusCode == 412 {
 		updater.AutoUpdater.ForcedUpgrade()
 		return nil, 0, fmt.Errorf("A required update has been applied. Please re-run this command.")
 	}
-	return respBody, resp.StatusCode, nil
+	return respBody, statusCode, nil
+}
+
+// do sends the request built by newRequest, retrying connection failures and
+// transient error responses according to the manager's retry policy. A fresh
+// request is built for every attempt so the body can be sent again. Once ctx
+// is cancelled the request in flight is aborted and no more retries are made.
+func (m *TLSHTTPManager) do(ctx context.Context, method, url string, newRequest func() (*http.Request, error)) ([]byte, int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, 0, err
+		}
+		resp, err := m.client.Do(req.WithContext(ctx))
+		statusCode := 0
+		var respBody []byte
+		if err == nil {
+			respBody, err = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			statusCode = resp.StatusCode
+		}
+		if ctx.Err() != nil || !m.retry.shouldRetry(method, attempt, err, statusCode) {
+			if err != nil {
+				return nil, 0, err
+			}
+			return respBody, statusCode, nil
+		}
+		delay := m.retry.delay(attempt, resp)
+		if err != nil {
+			logrus.Debugf("%s %s failed: %s. Retrying in %s (attempt %d of %d)", method, url, err, delay, attempt+1, m.retry.MaxRetries)
+		} else {
+			logrus.Debugf("%s %s returned %d. Retrying in %s (attempt %d of %d)", method, url, statusCode, delay, attempt+1, m.retry.MaxRetries)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
 }
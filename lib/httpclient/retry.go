@@ -0,0 +1,109 @@
+package httpclient
+
+import (
+	"math"
+	mathrand "math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/daticahealth/cli/config"
+)
+
+// RetryPolicy controls how failed requests are retried. Requests are retried
+// when the connection fails or the API responds with one of the
+// RetryStatusCodes. GET, PUT, and DELETE requests are always considered safe to
+// retry, POST requests only if RetryPOST is set.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	RetryPOST  bool
+}
+
+// RetryStatusCodes are the response codes that indicate a transient failure
+var RetryStatusCodes = map[int]struct{}{
+	http.StatusTooManyRequests:    struct{}{},
+	http.StatusBadGateway:         struct{}{},
+	http.StatusServiceUnavailable: struct{}{},
+	http.StatusGatewayTimeout:     struct{}{},
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: config.MaxRetries,
+		BaseDelay:  config.RetryBaseDelay * time.Millisecond,
+		MaxDelay:   config.RetryMaxDelay * time.Second,
+		RetryPOST:  false,
+	}
+}
+
+// retryable reports whether a request with the given method may be sent again
+func (p RetryPolicy) retryable(method string) bool {
+	switch method {
+	case "GET", "PUT", "DELETE":
+		return true
+	case "POST":
+		return p.RetryPOST
+	}
+	return false
+}
+
+// shouldRetry decides whether the given attempt should be retried based on the
+// connection error or response status.
+func (p RetryPolicy) shouldRetry(method string, attempt int, err error, statusCode int) bool {
+	if attempt >= p.MaxRetries || !p.retryable(method) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	_, ok := RetryStatusCodes[statusCode]
+	return ok
+}
+
+// delay calculates how long to wait before the next attempt. An exponential
+// backoff with full jitter is used unless the server sent a Retry-After header,
+// which is honored up to MaxDelay.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if d > p.MaxDelay {
+				return p.MaxDelay
+			}
+			return d
+		}
+	}
+	backoff := float64(p.BaseDelay) * math.Pow(2, float64(attempt))
+	if backoff > float64(p.MaxDelay) {
+		backoff = float64(p.MaxDelay)
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return time.Duration(mathrand.Int63n(int64(backoff)) + 1)
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date.
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		d := t.Sub(time.Now())
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+func init() {
+	mathrand.Seed(time.Now().UnixNano())
+}
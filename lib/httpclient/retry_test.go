@@ -0,0 +1,138 @@
+package httpclient_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/daticahealth/cli/lib/httpclient"
+	"github.com/daticahealth/cli/test"
+)
+
+var retryTests = []struct {
+	method         string
+	retryPOST      bool
+	failures       int
+	failStatus     int
+	expectAttempts int
+	expectStatus   int
+}{
+	{"GET", false, 0, http.StatusBadGateway, 1, 200},
+	{"GET", false, 2, http.StatusBadGateway, 3, 200},
+	{"GET", false, 2, http.StatusTooManyRequests, 3, 200},
+	{"GET", false, 5, http.StatusServiceUnavailable, 4, http.StatusServiceUnavailable},
+	{"GET", false, 1, http.StatusInternalServerError, 1, http.StatusInternalServerError},
+	{"PUT", false, 1, http.StatusGatewayTimeout, 2, 200},
+	{"DELETE", false, 1, http.StatusGatewayTimeout, 2, 200},
+	{"POST", false, 1, http.StatusBadGateway, 1, http.StatusBadGateway},
+	{"POST", true, 1, http.StatusBadGateway, 2, 200},
+}
+
+func TestRetry(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+
+	attempts := 0
+	failures := 0
+	failStatus := 0
+	mux.HandleFunc("/retry",
+		func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts <= failures {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(failStatus)
+				return
+			}
+			fmt.Fprint(w, `{}`)
+		},
+	)
+
+	for _, data := range retryTests {
+		t.Logf("Data: %+v", data)
+		attempts = 0
+		failures = data.failures
+		failStatus = data.failStatus
+		m := httpclient.NewTLSHTTPManagerWithRetryPolicy(false, httpclient.RetryPolicy{
+			MaxRetries: 3,
+			BaseDelay:  time.Millisecond,
+			MaxDelay:   10 * time.Millisecond,
+			RetryPOST:  data.retryPOST,
+		})
+		url := baseURL.String() + "/retry"
+
+		// test
+		var statusCode int
+		var err error
+		switch data.method {
+		case "GET":
+			_, statusCode, err = m.Get(context.Background(), nil, url, map[string][]string{})
+		case "POST":
+			_, statusCode, err = m.Post(context.Background(), nil, url, map[string][]string{})
+		case "PUT":
+			_, statusCode, err = m.Put(context.Background(), nil, url, map[string][]string{})
+		case "DELETE":
+			_, statusCode, err = m.Delete(context.Background(), nil, url, map[string][]string{})
+		}
+
+		// assert
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if statusCode != data.expectStatus {
+			t.Errorf("Expected status %d, got %d", data.expectStatus, statusCode)
+		}
+		if attempts != data.expectAttempts {
+			t.Errorf("Expected %d attempts, got %d", data.expectAttempts, attempts)
+		}
+	}
+}
+
+func TestRetryConnectionFailure(t *testing.T) {
+	_, server, baseURL := test.Setup()
+	test.Teardown(server)
+
+	m := httpclient.NewTLSHTTPManagerWithRetryPolicy(false, httpclient.RetryPolicy{
+		MaxRetries: 2,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   10 * time.Millisecond,
+	})
+
+	// test
+	_, _, err := m.Get(context.Background(), nil, baseURL.String()+"/retry", map[string][]string{})
+
+	// assert
+	if err == nil {
+		t.Error("Expected an error from a closed server, got nil")
+	}
+}
+
+func TestRetryCancelled(t *testing.T) {
+	mux, server, baseURL := test.Setup()
+	defer test.Teardown(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	mux.HandleFunc("/retry",
+		func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			cancel()
+			w.WriteHeader(http.StatusServiceUnavailable)
+		},
+	)
+	m := httpclient.NewTLSHTTPManagerWithRetryPolicy(false, httpclient.RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   10 * time.Millisecond,
+	})
+
+	// test
+	m.Get(ctx, nil, baseURL.String()+"/retry", map[string][]string{})
+
+	// assert
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt after cancelling, got %d", attempts)
+	}
+}
@@ -9,7 +9,7 @@ import (
 // DeleteTag deletes a tag for an image.
 func (d *SImages) DeleteTag(imageName, tagName string) error {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Delete(nil, fmt.Sprintf("%s%s/environments/%s/images/%s/tags/%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, url.PathEscape(url.PathEscape(imageName)), tagName), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Delete(d.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/images/%s/tags/%s", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID, url.PathEscape(url.PathEscape(imageName)), tagName), headers)
 	if err != nil {
 		return err
 	}
@@ -5,7 +5,7 @@ import "fmt"
 // ListImages lists images for an environment.
 func (d *SImages) ListImages() (*[]string, error) {
 	headers := d.Settings.HTTPManager.GetHeaders(d.Settings.SessionToken, d.Settings.Version, d.Settings.Pod, d.Settings.UsersID)
-	resp, statusCode, err := d.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/images", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID), headers)
+	resp, statusCode, err := d.Settings.HTTPManager.Get(d.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/images", d.Settings.PaasHost, d.Settings.PaasHostVersion, d.Settings.EnvironmentID), headers)
 	if err != nil {
 		return nil, err
 	}
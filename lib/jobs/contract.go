@@ -1,6 +1,10 @@
 package jobs
 
-import "github.com/daticahealth/cli/models"
+import (
+	"context"
+
+	"github.com/daticahealth/cli/models"
+)
 
 // IJobs
 type IJobs interface {
@@ -13,10 +17,10 @@ type IJobs interface {
 	RetrieveByStatus(svcID, status string) (*[]models.Job, error)
 	RetrieveByType(svcID, jobType string, page, pageSize int) (*[]models.Job, error)
 	RetrieveByTarget(svcID, target string, page, pageSize int) (*[]models.Job, error)
-	PollForStatus(statuses []string, jobID, svcID string) (string, error)
-	PollTillFinished(jobID, svcID string) (string, error)
+	PollForStatus(ctx context.Context, statuses []string, jobID, svcID string) (string, error)
+	PollTillFinished(ctx context.Context, jobID, svcID string) (string, error)
 	List(svcID string, page, pageSize int) (*[]models.Job, error)
-	WaitToAppear(jobID, svcID string) error
+	WaitToAppear(ctx context.Context, jobID, svcID string) error
 }
 
 // SJobs is a concrete implementation of IJobs
@@ -29,7 +29,7 @@ func (j *SJobs) Deploy(redeploy bool, releaseName, target, svcID string) error {
 		params = append(params, fmt.Sprintf("target=%s", target))
 	}
 	headers := j.Settings.HTTPManager.GetHeaders(j.Settings.SessionToken, j.Settings.Version, j.Settings.Pod, j.Settings.UsersID)
-	resp, statusCode, err := j.Settings.HTTPManager.Post(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/deploy?%s", j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID, strings.Join(params, "&")), headers)
+	resp, statusCode, err := j.Settings.HTTPManager.Post(j.Settings.Context, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/deploy?%s", j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID, strings.Join(params, "&")), headers)
 	if err != nil {
 		return err
 	}
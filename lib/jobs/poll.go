@@ -1,6 +1,7 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -18,32 +19,48 @@ func contains(v string, a []string) bool {
 	return false
 }
 
-func (j *SJobs) PollTillFinished(jobID, svcID string) (string, error) {
-	return j.PollForStatus([]string{"finished"}, jobID, svcID)
+// interrupted builds the error returned when polling for a job is stopped
+// before the job reaches the desired state. The job itself keeps running.
+func interrupted(ctx context.Context, jobID string) error {
+	return fmt.Errorf("\nStopped waiting for job %s (%s). The job is still running, you can check its status with the \"datica jobs list\" command.", jobID, ctx.Err())
 }
 
-func (j *SJobs) WaitToAppear(jobID, svcID string) error {
+func (j *SJobs) PollTillFinished(ctx context.Context, jobID, svcID string) (string, error) {
+	return j.PollForStatus(ctx, []string{"finished"}, jobID, svcID)
+}
+
+func (j *SJobs) WaitToAppear(ctx context.Context, jobID, svcID string) error {
 	for {
 		headers := j.Settings.HTTPManager.GetHeaders(j.Settings.SessionToken, j.Settings.Version, j.Settings.Pod, j.Settings.UsersID)
-		_, statusCode, err := j.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/jobs/%s", j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID, jobID), headers)
+		_, statusCode, err := j.Settings.HTTPManager.Get(ctx, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/jobs/%s", j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID, jobID), headers)
+		if ctx.Err() != nil {
+			return interrupted(ctx, jobID)
+		}
 		if err != nil {
 			return err
 		}
 		if statusCode >= 200 && statusCode < 300 {
 			return nil
 		}
-		time.Sleep(time.Second * 2)
+		select {
+		case <-ctx.Done():
+			return interrupted(ctx, jobID)
+		case <-time.After(time.Second * 2):
+		}
 	}
 }
 
-func (j *SJobs) PollForStatus(statuses []string, jobID, svcID string) (string, error) {
+func (j *SJobs) PollForStatus(ctx context.Context, statuses []string, jobID, svcID string) (string, error) {
 	var job models.Job
 	failedAttempts := 0
 poll:
 	for {
 		failed := false
 		headers := j.Settings.HTTPManager.GetHeaders(j.Settings.SessionToken, j.Settings.Version, j.Settings.Pod, j.Settings.UsersID)
-		resp, statusCode, err := j.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/environments/%s/services/%s/jobs/%s", j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID, jobID), headers)
+		resp, statusCode, err := j.Settings.HTTPManager.Get(ctx, nil, fmt.Sprintf("%s%s/environments/%s/services/%s/jobs/%s", j.Settings.PaasHost, j.Settings.PaasHostVersion, j.Settings.EnvironmentID, svcID, jobID), headers)
+		if ctx.Err() != nil {
+			return "", interrupted(ctx, jobID)
+		}
 		if err != nil {
 			failed = true
 		}
@@ -64,7 +81,11 @@ poll:
 			}
 			// all because logrus treats print, println, and printf the same
 			logrus.StandardLogger().Out.Write([]byte("."))
-			time.Sleep(config.JobPollTime * time.Second)
+			select {
+			case <-ctx.Done():
+				return "", interrupted(ctx, jobID)
+			case <-time.After(config.JobPollTime * time.Second):
+			}
 		default:
 			return "", fmt.Errorf("Error - ended in status '%s'.", job.Status)
 		}
@@ -0,0 +1,47 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daticahealth/cli/models"
+)
+
+// Supported values for the global --output option
+const (
+	Table = "table"
+	JSON  = "json"
+	YAML  = "yaml"
+)
+
+// Formats lists every supported output format
+var Formats = []string{Table, JSON, YAML}
+
+// IOutput renders the result of a list or show command in the format chosen
+// with the global --output option.
+type IOutput interface {
+	Render(data interface{}, table func() error) error
+	Format() string
+}
+
+// SOutput is a concrete implementation of IOutput
+type SOutput struct {
+	Settings *models.Settings
+}
+
+// New returns an instance of IOutput
+func New(settings *models.Settings) IOutput {
+	return &SOutput{
+		Settings: settings,
+	}
+}
+
+// Validate ensures the given format is one of the supported output formats.
+func Validate(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid output format \"%s\". Please specify one of: %s", format, strings.Join(Formats, ", "))
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// Format returns the output format for the current command. An empty format
+// falls back to the human readable table.
+func (o *SOutput) Format() string {
+	if o.Settings.OutputFormat == "" {
+		return Table
+	}
+	return o.Settings.OutputFormat
+}
+
+// Render prints the given data as JSON or YAML. When the table format is
+// selected, the given table func is called instead so each command keeps its
+// own human readable layout.
+func (o *SOutput) Render(data interface{}, table func() error) error {
+	switch o.Format() {
+	case JSON:
+		b, err := json.MarshalIndent(data, "", "    ")
+		if err != nil {
+			return err
+		}
+		logrus.Println(string(b))
+		return nil
+	case YAML:
+		// round trip through JSON so YAML keys match the API field names
+		// declared in the models' json tags
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		if err = json.Unmarshal(b, &generic); err != nil {
+			return err
+		}
+		b, err = yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		logrus.Println(strings.TrimSuffix(string(b), "\n"))
+		return nil
+	}
+	return table()
+}
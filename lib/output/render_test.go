@@ -0,0 +1,134 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/daticahealth/cli/models"
+	"github.com/daticahealth/cli/test"
+	"gopkg.in/yaml.v2"
+)
+
+// messageFormatter prints only the message of each entry, like the CLI's own
+// formatter does for info logs
+type messageFormatter struct{}
+
+func (f *messageFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return []byte(entry.Message + "\n"), nil
+}
+
+// captureOutput sends logrus output to a buffer until the returned func is
+// called
+func captureOutput() (*bytes.Buffer, func()) {
+	var buf bytes.Buffer
+	logger := logrus.StandardLogger()
+	oldOut, oldFormatter := logger.Out, logger.Formatter
+	logrus.SetOutput(&buf)
+	logrus.SetFormatter(&messageFormatter{})
+	return &buf, func() {
+		logrus.SetOutput(oldOut)
+		logrus.SetFormatter(oldFormatter)
+	}
+}
+
+type renderItem struct {
+	Name    string `json:"name"`
+	Service string `json:"service,omitempty"`
+	Count   int    `json:"count"`
+}
+
+var renderData = []renderItem{{Name: "cert0", Service: "code-1", Count: 2}, {Name: "cert1", Count: 0}}
+
+var renderTests = []struct {
+	format    string
+	unmarshal func([]byte, interface{}) error
+}{
+	{JSON, json.Unmarshal},
+	{YAML, yaml.Unmarshal},
+}
+
+func TestRender(t *testing.T) {
+	for _, data := range renderTests {
+		t.Logf("Data: %s", data.format)
+		buf, restore := captureOutput()
+		tableCalled := false
+
+		// test
+		err := New(&models.Settings{OutputFormat: data.format}).Render(renderData, func() error {
+			tableCalled = true
+			return nil
+		})
+		restore()
+
+		// assert
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if tableCalled {
+			t.Errorf("Expected the table not to be printed for %s", data.format)
+		}
+		var actual []map[string]interface{}
+		if err = data.unmarshal(buf.Bytes(), &actual); err != nil {
+			t.Errorf("Output is not valid %s: %s\n%s", data.format, err, buf.String())
+			continue
+		}
+		if len(actual) != 2 {
+			t.Errorf("Expected 2 items but got %d", len(actual))
+			continue
+		}
+		// keys follow the json tags in both formats
+		test.AssertEquals(t, "cert0", actual[0]["name"].(string))
+		test.AssertEquals(t, "code-1", actual[0]["service"].(string))
+		if _, ok := actual[1]["service"]; ok {
+			t.Errorf("Expected omitempty fields to be left out of %s", data.format)
+		}
+		if _, ok := actual[0]["count"]; !ok {
+			t.Errorf("Expected the count field in %s", data.format)
+		}
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	for _, format := range []string{"", Table} {
+		t.Logf("Data: %q", format)
+		buf, restore := captureOutput()
+		tableCalled := false
+
+		// test
+		err := New(&models.Settings{OutputFormat: format}).Render(renderData, func() error {
+			tableCalled = true
+			return nil
+		})
+		restore()
+
+		// assert
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if !tableCalled {
+			t.Errorf("Expected the table to be printed")
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Expected nothing but the table to be printed but got %s", buf.String())
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, format := range Formats {
+		if err := Validate(format); err != nil {
+			t.Errorf("Unexpected error for %s: %s", format, err)
+		}
+	}
+	for _, format := range []string{"", "JSON", "xml", "csv"} {
+		err := Validate(format)
+		if err == nil {
+			t.Errorf("Expected an error for %q", format)
+			continue
+		}
+		test.AssertEquals(t, `Invalid output format "`+format+`". Please specify one of: table, json, yaml`, err.Error())
+	}
+}
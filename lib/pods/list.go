@@ -8,7 +8,7 @@ import (
 
 func (p *SPods) List() (*[]models.Pod, error) {
 	headers := p.Settings.HTTPManager.GetHeaders(p.Settings.SessionToken, p.Settings.Version, p.Settings.Pod, p.Settings.UsersID)
-	resp, statusCode, err := p.Settings.HTTPManager.Get(nil, fmt.Sprintf("%s%s/pods", p.Settings.PaasHost, p.Settings.PaasHostVersion), headers)
+	resp, statusCode, err := p.Settings.HTTPManager.Get(p.Settings.Context, nil, fmt.Sprintf("%s%s/pods", p.Settings.PaasHost, p.Settings.PaasHostVersion), headers)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,299 @@
+package redact
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxDocumentSize is the largest BSON document mongo allows
+const maxDocumentSize = 16 * 1024 * 1024
+
+// BSON element types that are redacted or walked into
+const (
+	bsonDouble   = 0x01
+	bsonString   = 0x02
+	bsonDocument = 0x03
+	bsonArray    = 0x04
+	bsonNull     = 0x0A
+	bsonInt32    = 0x10
+	bsonInt64    = 0x12
+)
+
+// redactArchive redacts the .bson files of a .tar.gz archive made by
+// mongodump. Every other file is copied unchanged. A redacted file is written
+// to a temporary file first because its size must be known before it can be
+// added to the archive, so only redacted data is ever written to disk.
+func redactArchive(r io.Reader, w io.Writer, rules *Rules) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		var fields map[string]*Rule
+		if header.Typeflag == tar.TypeReg && strings.HasSuffix(header.Name, ".bson") {
+			dir, file := path.Split(strings.TrimSuffix(header.Name, ".bson"))
+			fields = rules.fieldRules(path.Base(dir), file)
+		}
+		if len(fields) == 0 {
+			if err = tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if _, err = io.Copy(tw, tr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = redactArchiveFile(header, tr, tw, fields, rules.Salt); err != nil {
+			return fmt.Errorf("%s: %s", header.Name, err)
+		}
+	}
+	// read to the end so the gzip checksum is verified
+	if _, err = io.Copy(ioutil.Discard, gz); err != nil {
+		return err
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func redactArchiveFile(header *tar.Header, r io.Reader, tw *tar.Writer, fields map[string]*Rule, salt string) error {
+	tmp, err := ioutil.TempFile("", "datica-redact-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	size, err := redactBSON(r, tmp, fields, salt)
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	redacted := *header
+	redacted.Size = size
+	if err = tw.WriteHeader(&redacted); err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, tmp, size)
+	return err
+}
+
+// redactBSON redacts a stream of BSON documents, returning the size of the
+// redacted stream
+func redactBSON(r io.Reader, w io.Writer, fields map[string]*Rule, salt string) (int64, error) {
+	var written int64
+	length := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, length); err == io.EOF {
+			return written, nil
+		} else if err != nil {
+			return written, err
+		}
+		size := int(binary.LittleEndian.Uint32(length))
+		if size < 5 || size > maxDocumentSize {
+			return written, fmt.Errorf("invalid document size %d", size)
+		}
+		doc := make([]byte, size)
+		copy(doc, length)
+		if _, err := io.ReadFull(r, doc[4:]); err != nil {
+			return written, err
+		}
+		redacted, err := redactDocument(doc, "", false, fields, salt)
+		if err != nil {
+			return written, err
+		}
+		n, err := w.Write(redacted)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// redactDocument redacts the fields of a BSON document. Path is the dotted
+// path of the document, and the elements of an array share the path of the
+// array so a rule applies to every document in it.
+func redactDocument(doc []byte, docPath string, array bool, fields map[string]*Rule, salt string) ([]byte, error) {
+	out := make([]byte, 4, len(doc))
+	pos := 4
+	for {
+		if pos >= len(doc) {
+			return nil, errors.New("truncated document")
+		}
+		t := doc[pos]
+		if t == 0 {
+			break
+		}
+		nameEnd := bytes.IndexByte(doc[pos+1:], 0)
+		if nameEnd < 0 {
+			return nil, errors.New("truncated document")
+		}
+		name := doc[pos+1 : pos+1+nameEnd]
+		valueStart := pos + 1 + nameEnd + 1
+		size, err := bsonValueSize(t, doc[valueStart:])
+		if err != nil {
+			return nil, err
+		}
+		value := doc[valueStart : valueStart+size]
+		pos = valueStart + size
+
+		elementPath := docPath
+		if !array {
+			elementPath = joinPath(docPath, string(name))
+		}
+		rule := fields[elementPath]
+		switch {
+		case rule != nil:
+			t, value = rule.replaceBSON(t, value, salt)
+		case (t == bsonDocument || t == bsonArray) && hasFieldUnder(fields, elementPath):
+			value, err = redactDocument(value, elementPath, t == bsonArray, fields, salt)
+			if err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, t)
+		out = append(out, name...)
+		out = append(out, 0)
+		out = append(out, value...)
+	}
+	out = append(out, 0)
+	binary.LittleEndian.PutUint32(out, uint32(len(out)))
+	return out, nil
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func hasFieldUnder(fields map[string]*Rule, parent string) bool {
+	for field := range fields {
+		if strings.HasPrefix(field, parent+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// bsonValueSize is the size of a BSON value of the given type at the start of b
+func bsonValueSize(t byte, b []byte) (int, error) {
+	size := 0
+	switch t {
+	case 0x06, bsonNull, 0x7F, 0xFF:
+		size = 0
+	case 0x08:
+		size = 1
+	case bsonInt32:
+		size = 4
+	case bsonDouble, 0x09, 0x11, bsonInt64:
+		size = 8
+	case 0x07:
+		size = 12
+	case 0x13:
+		size = 16
+	case bsonString, 0x0D, 0x0E, 0x0C:
+		if len(b) < 4 {
+			return 0, errors.New("truncated document")
+		}
+		size = 4 + int(binary.LittleEndian.Uint32(b))
+		if t == 0x0C {
+			size += 12
+		}
+	case bsonDocument, bsonArray, 0x0F:
+		if len(b) < 4 {
+			return 0, errors.New("truncated document")
+		}
+		size = int(binary.LittleEndian.Uint32(b))
+	case 0x05:
+		if len(b) < 4 {
+			return 0, errors.New("truncated document")
+		}
+		size = 5 + int(binary.LittleEndian.Uint32(b))
+	case 0x0B:
+		pattern := bytes.IndexByte(b, 0)
+		if pattern < 0 {
+			return 0, errors.New("truncated document")
+		}
+		options := bytes.IndexByte(b[pattern+1:], 0)
+		if options < 0 {
+			return 0, errors.New("truncated document")
+		}
+		size = pattern + 1 + options + 1
+	default:
+		return 0, fmt.Errorf("unknown BSON type 0x%02x", t)
+	}
+	if size < 0 || size > len(b) {
+		return 0, errors.New("truncated document")
+	}
+	return size, nil
+}
+
+// replaceBSON redacts a BSON value, returning its new type and value. Hashes
+// and constants are strings. Faked strings and integers keep their type, and
+// any other faked value becomes null. Null stays null.
+func (r *Rule) replaceBSON(t byte, value []byte, salt string) (byte, []byte) {
+	if t == bsonNull {
+		return t, value
+	}
+	if r.Strategy == Fake {
+		switch t {
+		case bsonString:
+			return bsonString, bsonStringValue(fakeValue(value[4:len(value)-1], salt))
+		case bsonInt32:
+			n := int32(binary.LittleEndian.Uint32(value))
+			faked, _ := strconv.ParseInt(string(fakeValue([]byte(strconv.Itoa(int(n))), salt)), 10, 64)
+			out := make([]byte, 4)
+			binary.LittleEndian.PutUint32(out, uint32(int32(faked)))
+			return bsonInt32, out
+		case bsonInt64:
+			n := int64(binary.LittleEndian.Uint64(value))
+			faked, err := strconv.ParseInt(string(fakeValue([]byte(strconv.FormatInt(n, 10)), salt)), 10, 64)
+			if err != nil {
+				faked = n ^ int64(binary.LittleEndian.Uint64([]byte(hashValue(value, salt))[:8]))
+			}
+			out := make([]byte, 8)
+			binary.LittleEndian.PutUint64(out, uint64(faked))
+			return bsonInt64, out
+		}
+		return bsonNull, nil
+	}
+	text := value
+	if t == bsonString {
+		text = value[4 : len(value)-1]
+	}
+	redacted := r.replace(text, salt)
+	if redacted == nil {
+		return bsonNull, nil
+	}
+	return bsonString, bsonStringValue(redacted)
+}
+
+func bsonStringValue(s []byte) []byte {
+	out := make([]byte, 4, 4+len(s)+1)
+	binary.LittleEndian.PutUint32(out, uint32(len(s)+1))
+	out = append(out, s...)
+	return append(out, 0)
+}
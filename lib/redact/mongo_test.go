@@ -0,0 +1,134 @@
+package redact
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+)
+
+func bsonElement(t byte, name string, value []byte) []byte {
+	return append(append([]byte{t}, append([]byte(name), 0)...), value...)
+}
+
+func bsonDocumentOf(elements ...[]byte) []byte {
+	doc := make([]byte, 4)
+	for _, e := range elements {
+		doc = append(doc, e...)
+	}
+	doc = append(doc, 0)
+	binary.LittleEndian.PutUint32(doc, uint32(len(doc)))
+	return doc
+}
+
+func bsonInt32Value(n int32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(n))
+	return b
+}
+
+// archiveOf makes a .tar.gz archive of the given files in order
+func archiveOf(t *testing.T, files ...[2]string) []byte {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f[0], Mode: 0600, Size: int64(len(f[1])), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(f[1]))
+	}
+	tw.Close()
+	gw.Close()
+	return buf.Bytes()
+}
+
+// archiveFiles reads the files of a .tar.gz archive
+func archiveFiles(t *testing.T, archive []byte) map[string]string {
+	gr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(b)
+	}
+	return files
+}
+
+func TestRedactMongo(t *testing.T) {
+	patient := func(name, street, number, ssn []byte, nameType, numberType byte) []byte {
+		return bsonDocumentOf(
+			bsonElement(bsonInt32, "_id", bsonInt32Value(1)),
+			bsonElement(nameType, "name", name),
+			bsonElement(bsonDocument, "address", bsonDocumentOf(bsonElement(bsonString, "street", street))),
+			bsonElement(bsonArray, "phones", bsonDocumentOf(
+				bsonElement(bsonDocument, "0", bsonDocumentOf(bsonElement(numberType, "number", number))),
+				bsonElement(bsonDocument, "1", bsonDocumentOf(bsonElement(numberType, "number", number))),
+			)),
+			bsonElement(bsonInt32, "ssn", ssn),
+		)
+	}
+	original := patient(bsonStringValue([]byte("Ann")), bsonStringValue([]byte("1 Main St")), bsonStringValue([]byte("555-1234")), bsonInt32Value(123456789), bsonString, bsonString)
+	_, fakedSSN := (&Rule{Strategy: Fake}).replaceBSON(bsonInt32, bsonInt32Value(123456789), testSalt)
+	expected := patient(bsonStringValue([]byte(hashValue([]byte("Ann"), testSalt))), bsonStringValue([]byte("x")), nil, fakedSSN, bsonString, bsonNull)
+
+	rules := &Rules{Salt: testSalt, Rules: []*Rule{
+		{Collection: "patients", Field: "name", Strategy: Hash},
+		{Collection: "clinic.patients", Field: "address.street", Strategy: Constant, Value: constant("x")},
+		{Collection: "patients", Field: "phones.number", Strategy: Null},
+		{Collection: "patients", Field: "ssn", Strategy: Fake},
+		{Collection: "other.patients", Field: "name", Strategy: Null},
+	}}
+	metadata := `{"indexes":[]}`
+	archive := archiveOf(t,
+		[2]string{"dump/clinic/patients.metadata.json", metadata},
+		[2]string{"dump/clinic/patients.bson", string(original) + string(original)},
+		[2]string{"dump/clinic/visits.bson", string(original)},
+	)
+
+	// test
+	actual, err := redactString(rules, "mongodb", string(archive))
+
+	// assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	files := archiveFiles(t, []byte(actual))
+	if files["dump/clinic/patients.metadata.json"] != metadata {
+		t.Errorf("Unexpected metadata: %q", files["dump/clinic/patients.metadata.json"])
+	}
+	if files["dump/clinic/visits.bson"] != string(original) {
+		t.Errorf("Unexpected change to a collection without rules: %x", files["dump/clinic/visits.bson"])
+	}
+	if files["dump/clinic/patients.bson"] != string(expected)+string(expected) {
+		t.Errorf("Unexpected redacted collection. Expected: %x, actual: %x", string(expected)+string(expected), files["dump/clinic/patients.bson"])
+	}
+	if unmatched := rules.Unmatched("mongodb"); len(unmatched) != 1 || unmatched[0] != rules.Rules[4] {
+		t.Errorf("Unexpected unmatched rules: %v", unmatched)
+	}
+}
+
+func TestRedactMongoInvalidDocument(t *testing.T) {
+	rules := &Rules{Rules: []*Rule{{Collection: "patients", Field: "name", Strategy: Null}}}
+	archive := archiveOf(t, [2]string{"dump/clinic/patients.bson", "\x06\x00\x00\x00\x99"})
+
+	// test
+	_, err := redactString(rules, "mongodb", string(archive))
+
+	// assert
+	if err == nil {
+		t.Error("Expected an error redacting an invalid document")
+	}
+}
@@ -0,0 +1,88 @@
+package redact
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// WriteCloser redacts a backup as it is written to it, writing the redacted
+// backup to dst. SQL dumps are redacted line by line, and mongo backups are
+// redacted as a .tar.gz archive of mongodump files, so nothing unredacted is
+// ever written to dst.
+type WriteCloser struct {
+	dst io.WriteCloser
+
+	pw   *io.PipeWriter
+	done chan error
+	err  error
+}
+
+// NewWriteCloser starts redacting a backup of a database of the given type,
+// such as postgresql, mysql, or mongodb
+func NewWriteCloser(dst io.WriteCloser, rules *Rules, serviceName string) (*WriteCloser, error) {
+	var redact func(r io.Reader, w io.Writer) error
+	switch serviceName {
+	case "postgresql", "mysql":
+		redact = func(r io.Reader, w io.Writer) error {
+			return newSQLRedactor(rules, serviceName == "mysql").redact(r, w)
+		}
+	case "mongodb":
+		redact = func(r io.Reader, w io.Writer) error {
+			return redactArchive(r, w, rules)
+		}
+	default:
+		return nil, fmt.Errorf("Backups of %s databases cannot be redacted", serviceName)
+	}
+	pr, pw := io.Pipe()
+	w := &WriteCloser{dst: dst, pw: pw, done: make(chan error, 1)}
+	go func() {
+		bw := bufio.NewWriter(dst)
+		err := redact(pr, bw)
+		if err == nil {
+			err = bw.Flush()
+		}
+		if err == nil {
+			// anything after the end of the dump or archive is dropped
+			io.Copy(ioutil.Discard, pr)
+		} else {
+			err = fmt.Errorf("Failed to redact the backup: %s", err)
+		}
+		// the result is ready before any further writes fail with the error
+		w.done <- err
+		pr.CloseWithError(err)
+	}()
+	return w, nil
+}
+
+func (w *WriteCloser) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+// Close waits for the rest of the backup to be redacted, then closes dst
+func (w *WriteCloser) Close() error {
+	w.pw.Close()
+	if w.done != nil {
+		w.err = <-w.done
+		w.done = nil
+	}
+	err := w.err
+	if cerr := w.dst.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Err returns the error redacting the backup if redacting has already failed.
+// This tells a failure to redact apart from a failure writing the backup.
+func (w *WriteCloser) Err() error {
+	if w.done != nil {
+		select {
+		case w.err = <-w.done:
+			w.done = nil
+		default:
+		}
+	}
+	return w.err
+}
@@ -0,0 +1,172 @@
+package redact
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Strategies for replacing a redacted value
+const (
+	// Null replaces the value with NULL
+	Null = "null"
+	// Hash replaces the value with the hex SHA-256 of the salt and the value,
+	// so equal values stay equal across tables
+	Hash = "hash"
+	// Fake replaces every letter and digit with a random one, keeping the
+	// length, case, and punctuation of the value. The same value is always
+	// faked the same way.
+	Fake = "fake"
+	// Constant replaces the value with the rule's value
+	Constant = "constant"
+)
+
+// Strategies lists every supported strategy
+var Strategies = []string{Null, Hash, Fake, Constant}
+
+// Rules is a redaction rules file. Each rule names either a table and column
+// of a SQL database or a collection and field of a mongo database.
+type Rules struct {
+	Salt  string  `yaml:"salt"`
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule redacts a single column or field. Tables and collections may be given
+// with or without their schema or database, such as public.patients or
+// patients. Fields of embedded documents are given as a dotted path, such as
+// address.street, and apply to every document in an array.
+type Rule struct {
+	Table      string  `yaml:"table"`
+	Column     string  `yaml:"column"`
+	Collection string  `yaml:"collection"`
+	Field      string  `yaml:"field"`
+	Strategy   string  `yaml:"strategy"`
+	Value      *string `yaml:"value"`
+
+	matched bool
+}
+
+func (r *Rule) String() string {
+	if r.Collection != "" {
+		return fmt.Sprintf("collection %s field %s", r.Collection, r.Field)
+	}
+	return fmt.Sprintf("table %s column %s", r.Table, r.Column)
+}
+
+// Load reads and validates the rules file at the given path
+func Load(filePath string) (*Rules, error) {
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var rules Rules
+	if err = yaml.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("Invalid redaction rules %s: %s", filePath, err)
+	}
+	if len(rules.Rules) == 0 {
+		return nil, fmt.Errorf("Invalid redaction rules %s: no rules were found", filePath)
+	}
+	for i, r := range rules.Rules {
+		sql := r.Table != "" || r.Column != ""
+		mongo := r.Collection != "" || r.Field != ""
+		if sql == mongo || (sql && (r.Table == "" || r.Column == "")) || (mongo && (r.Collection == "" || r.Field == "")) {
+			return nil, fmt.Errorf("Invalid redaction rules %s: rule %d needs either a table and column or a collection and field", filePath, i+1)
+		}
+		valid := false
+		for _, s := range Strategies {
+			if r.Strategy == s {
+				valid = true
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("Invalid redaction rules %s: the strategy for %s must be one of %s", filePath, r, strings.Join(Strategies, ", "))
+		}
+		if r.Strategy == Constant && r.Value == nil {
+			return nil, fmt.Errorf("Invalid redaction rules %s: %s needs a value for the constant strategy", filePath, r)
+		}
+		// without a secret salt, anyone could hash or fake guesses of the
+		// original values and compare them against the export
+		if (r.Strategy == Hash || r.Strategy == Fake) && rules.Salt == "" {
+			return nil, fmt.Errorf("Invalid redaction rules %s: %s needs a salt for the %s strategy", filePath, r, r.Strategy)
+		}
+	}
+	return &rules, nil
+}
+
+// Check makes sure the rules can be applied to a database of the given type
+func (rs *Rules) Check(serviceName string) error {
+	for _, r := range rs.Rules {
+		if (serviceName == "mongodb") == (r.Collection != "") {
+			return nil
+		}
+	}
+	if serviceName == "mongodb" {
+		return fmt.Errorf("The redaction rules have no collection rules, so nothing would be redacted from this mongo database")
+	}
+	if serviceName != "postgresql" && serviceName != "mysql" {
+		return fmt.Errorf("Backups of %s databases cannot be redacted", serviceName)
+	}
+	return fmt.Errorf("The redaction rules have no table rules, so nothing would be redacted from this %s database", serviceName)
+}
+
+// Unmatched returns the rules for the type of database that did not match any
+// table or collection that was redacted
+func (rs *Rules) Unmatched(serviceName string) []*Rule {
+	unmatched := []*Rule{}
+	for _, r := range rs.Rules {
+		if !r.matched && (serviceName == "mongodb") == (r.Collection != "") {
+			unmatched = append(unmatched, r)
+		}
+	}
+	return unmatched
+}
+
+// columnRules finds the rule for each column of a table, keyed by the index of
+// the column. Identifiers may still be quoted.
+func (rs *Rules) columnRules(table string, columns []string) map[int]*Rule {
+	table = unquoteIdentifier(table)
+	short := table
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		short = table[i+1:]
+	}
+	found := map[int]*Rule{}
+	for _, r := range rs.Rules {
+		if r.Table == "" || (!strings.EqualFold(r.Table, table) && !strings.EqualFold(r.Table, short)) {
+			continue
+		}
+		for i, c := range columns {
+			if strings.EqualFold(r.Column, unquoteIdentifier(c)) {
+				found[i] = r
+				r.matched = true
+			}
+		}
+	}
+	return found
+}
+
+// fieldRules finds the rules for a collection of a mongo database, keyed by
+// field path
+func (rs *Rules) fieldRules(database, collection string) map[string]*Rule {
+	found := map[string]*Rule{}
+	for _, r := range rs.Rules {
+		if r.Collection == collection || r.Collection == database+"."+collection {
+			found[r.Field] = r
+			r.matched = true
+		}
+	}
+	return found
+}
+
+// unquoteIdentifier removes the quotes around each part of a SQL identifier,
+// such as "public"."patients" or `patients`
+func unquoteIdentifier(identifier string) string {
+	parts := strings.Split(identifier, ".")
+	for i, p := range parts {
+		if len(p) >= 2 && (p[0] == '"' || p[0] == '`') && p[len(p)-1] == p[0] {
+			parts[i] = p[1 : len(p)-1]
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,451 @@
+package redact
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sqlRedactor redacts a plain SQL dump made by pg_dump or mysqldump. Rows are
+// found in COPY blocks and INSERT statements. The columns of an INSERT without
+// a column list are taken from the CREATE TABLE statement before it. Every
+// other line is written out unchanged.
+type sqlRedactor struct {
+	rules *Rules
+	mysql bool
+	// columns of each table from its CREATE TABLE statement
+	tables map[string][]string
+}
+
+func newSQLRedactor(rules *Rules, mysql bool) *sqlRedactor {
+	return &sqlRedactor{rules: rules, mysql: mysql, tables: map[string][]string{}}
+}
+
+// createTableKeywords start a line of a CREATE TABLE statement that is not a
+// column
+var createTableKeywords = []string{"PRIMARY", "KEY", "UNIQUE", "CONSTRAINT", "INDEX", "FULLTEXT", "SPATIAL", "FOREIGN", "CHECK", "EXCLUDE", "LIKE"}
+
+func (s *sqlRedactor) redact(r io.Reader, w io.Writer) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	if head, _ := br.Peek(5); bytes.Equal(head, []byte("PGDMP")) {
+		return errors.New("PostgreSQL custom format dumps cannot be redacted")
+	}
+	var copyRules map[int]*Rule
+	inCopy := false
+	creating := ""
+	var columns []string
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(line) > 0 {
+			switch {
+			case inCopy:
+				if bytes.Equal(bytes.TrimRight(line, "\r\n"), []byte(`\.`)) {
+					inCopy = false
+				} else if len(copyRules) > 0 {
+					line = s.redactCopyRow(line, copyRules)
+				}
+			case creating != "":
+				trimmed := strings.TrimSpace(string(line))
+				if strings.HasPrefix(trimmed, ")") {
+					s.tables[creating] = columns
+					creating = ""
+				} else if column := columnName(trimmed); column != "" {
+					columns = append(columns, column)
+				}
+			case bytes.HasPrefix(line, []byte("COPY ")):
+				table, cols := parseCopy(string(line))
+				if cols == nil {
+					cols = s.tables[unquoteIdentifier(table)]
+				}
+				copyRules = s.rules.columnRules(table, cols)
+				inCopy = true
+			case bytes.HasPrefix(line, []byte("CREATE TABLE ")) && bytes.HasSuffix(bytes.TrimRight(line, " \r\n"), []byte("(")):
+				// both pg_dump and mysqldump put each column on its own line
+				creating = unquoteIdentifier(parseCreateTable(string(line)))
+				columns = []string{}
+			case bytes.HasPrefix(line, []byte("INSERT INTO ")):
+				// a statement continues onto the next line when a string in it
+				// contains a newline
+				for err == nil && !s.statementComplete(line) {
+					var more []byte
+					more, err = br.ReadBytes('\n')
+					if err != nil && err != io.EOF {
+						return err
+					}
+					line = append(line, more...)
+				}
+				line, err = s.redactInsert(line, err)
+				if err != nil && err != io.EOF {
+					return err
+				}
+			}
+			if _, werr := w.Write(line); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// parseCopy reads the table and columns from a line such as
+// COPY public.patients (id, name) FROM stdin;
+// The columns are nil when they are not listed.
+func parseCopy(line string) (string, []string) {
+	rest := strings.TrimPrefix(line, "COPY ")
+	end := strings.IndexAny(rest, " (")
+	if end < 0 {
+		return strings.TrimSpace(rest), nil
+	}
+	table := rest[:end]
+	rest = strings.TrimSpace(rest[end:])
+	if !strings.HasPrefix(rest, "(") {
+		return table, nil
+	}
+	close := strings.Index(rest, ")")
+	if close < 0 {
+		return table, nil
+	}
+	return table, splitColumns(rest[1:close])
+}
+
+// parseCreateTable reads the table name from the first line of a CREATE TABLE
+// statement
+func parseCreateTable(line string) string {
+	rest := strings.TrimPrefix(line, "CREATE TABLE ")
+	rest = strings.TrimPrefix(rest, "IF NOT EXISTS ")
+	if end := strings.IndexAny(rest, " ("); end >= 0 {
+		rest = rest[:end]
+	}
+	return strings.TrimSpace(rest)
+}
+
+// columnName reads the column name from a line inside a CREATE TABLE
+// statement, or an empty string if the line is a key or constraint
+func columnName(line string) string {
+	if line == "" {
+		return ""
+	}
+	if line[0] == '"' || line[0] == '`' {
+		if end := strings.IndexByte(line[1:], line[0]); end >= 0 {
+			return line[1 : end+1]
+		}
+		return ""
+	}
+	word := line
+	if end := strings.IndexAny(line, " \t,"); end >= 0 {
+		word = line[:end]
+	}
+	for _, k := range createTableKeywords {
+		if word == k {
+			return ""
+		}
+	}
+	return word
+}
+
+func splitColumns(list string) []string {
+	columns := []string{}
+	for _, c := range strings.Split(list, ",") {
+		columns = append(columns, unquoteIdentifier(strings.TrimSpace(c)))
+	}
+	return columns
+}
+
+// redactCopyRow redacts a tab separated row of a COPY block
+func (s *sqlRedactor) redactCopyRow(line []byte, rules map[int]*Rule) []byte {
+	ending := line[len(bytes.TrimRight(line, "\r\n")):]
+	fields := bytes.Split(line[:len(line)-len(ending)], []byte("\t"))
+	for i, rule := range rules {
+		if i >= len(fields) || bytes.Equal(fields[i], []byte(`\N`)) {
+			continue
+		}
+		redacted := rule.replace(unescapeCopy(fields[i]), s.rules.Salt)
+		if redacted == nil {
+			fields[i] = []byte(`\N`)
+		} else {
+			fields[i] = escapeCopy(redacted)
+		}
+	}
+	return append(bytes.Join(fields, []byte("\t")), ending...)
+}
+
+// unescapeCopy decodes the backslash escapes of a COPY text field
+func unescapeCopy(field []byte) []byte {
+	if bytes.IndexByte(field, '\\') < 0 {
+		return field
+	}
+	out := make([]byte, 0, len(field))
+	for i := 0; i < len(field); i++ {
+		c := field[i]
+		if c != '\\' || i+1 == len(field) {
+			out = append(out, c)
+			continue
+		}
+		i++
+		switch c = field[i]; c {
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'v':
+			out = append(out, '\v')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			v := 0
+			j := i
+			for ; j < len(field) && j < i+3 && field[j] >= '0' && field[j] <= '7'; j++ {
+				v = v*8 + int(field[j]-'0')
+			}
+			out = append(out, byte(v))
+			i = j - 1
+		case 'x':
+			v, j := 0, i+1
+			for ; j < len(field) && j < i+3 && isHex(field[j]); j++ {
+				v = v*16 + hexValue(field[j])
+			}
+			if j == i+1 {
+				out = append(out, 'x')
+			} else {
+				out = append(out, byte(v))
+				i = j - 1
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// escapeCopy encodes a value as a COPY text field
+func escapeCopy(value []byte) []byte {
+	out := make([]byte, 0, len(value))
+	for _, c := range value {
+		switch c {
+		case '\\':
+			out = append(out, '\\', '\\')
+		case '\n':
+			out = append(out, '\\', 'n')
+		case '\r':
+			out = append(out, '\\', 'r')
+		case '\t':
+			out = append(out, '\\', 't')
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func hexValue(c byte) int {
+	switch {
+	case c >= 'a':
+		return int(c-'a') + 10
+	case c >= 'A':
+		return int(c-'A') + 10
+	}
+	return int(c - '0')
+}
+
+// statementComplete reports whether an INSERT statement ends on its last line,
+// that is with a semicolon outside of any string
+func (s *sqlRedactor) statementComplete(statement []byte) bool {
+	inString := false
+	for i := 0; i < len(statement); i++ {
+		switch c := statement[i]; {
+		case inString && c == '\\' && s.mysql:
+			i++
+		case c == '\'':
+			inString = !inString
+		}
+	}
+	return !inString && bytes.HasSuffix(bytes.TrimRight(statement, " \t\r\n"), []byte(";"))
+}
+
+// redactInsert redacts the values of an INSERT statement such as
+// INSERT INTO `patients` VALUES (1,'a'),(2,'b');
+// readErr is passed through so the caller still sees the end of the dump.
+func (s *sqlRedactor) redactInsert(statement []byte, readErr error) ([]byte, error) {
+	rest := statement[len("INSERT INTO "):]
+	end := bytes.IndexAny(rest, " (")
+	if end < 0 {
+		return statement, readErr
+	}
+	table := string(rest[:end])
+	pos := len("INSERT INTO ") + end
+	for pos < len(statement) && statement[pos] == ' ' {
+		pos++
+	}
+	var columns []string
+	if pos < len(statement) && statement[pos] == '(' {
+		close := bytes.IndexByte(statement[pos:], ')')
+		if close < 0 {
+			return statement, readErr
+		}
+		columns = splitColumns(string(statement[pos+1 : pos+close]))
+		pos += close + 1
+	} else {
+		columns = s.tables[unquoteIdentifier(table)]
+	}
+	rules := s.rules.columnRules(table, columns)
+	if len(rules) == 0 {
+		return statement, readErr
+	}
+	values := bytes.Index(statement[pos:], []byte("VALUES"))
+	if values < 0 {
+		return statement, readErr
+	}
+	pos += values + len("VALUES")
+
+	out := append([]byte{}, statement[:pos]...)
+	for {
+		// find the start of the next row
+		start := bytes.IndexByte(statement[pos:], '(')
+		if start < 0 {
+			break
+		}
+		out = append(out, statement[pos:pos+start+1]...)
+		pos += start + 1
+		for column := 0; ; column++ {
+			valueEnd, err := s.valueEnd(statement, pos)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse an INSERT into %s: %s", table, err)
+			}
+			value := statement[pos:valueEnd]
+			if rule, ok := rules[column]; ok {
+				value = s.redactLiteral(value, rule)
+			}
+			out = append(out, value...)
+			out = append(out, statement[valueEnd])
+			pos = valueEnd + 1
+			if statement[valueEnd] == ')' {
+				break
+			}
+		}
+	}
+	return append(out, statement[pos:]...), readErr
+}
+
+// valueEnd finds the comma or closing parenthesis after the value starting at
+// pos, skipping over strings and parentheses inside the value
+func (s *sqlRedactor) valueEnd(statement []byte, pos int) (int, error) {
+	depth := 0
+	inString := false
+	for i := pos; i < len(statement); i++ {
+		c := statement[i]
+		switch {
+		case inString && c == '\\' && s.mysql:
+			i++
+		case c == '\'':
+			inString = !inString
+		case inString:
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case (c == ',' || c == ')') && depth == 0:
+			return i, nil
+		}
+	}
+	return 0, errors.New("the statement ended in the middle of a row")
+}
+
+// redactLiteral replaces a SQL literal, keeping any whitespace around it.
+// NULL stays NULL.
+func (s *sqlRedactor) redactLiteral(literal []byte, rule *Rule) []byte {
+	trimmed := bytes.TrimSpace(literal)
+	if bytes.EqualFold(trimmed, []byte("NULL")) {
+		return literal
+	}
+	leading := literal[:bytes.Index(literal, trimmed)]
+	trailing := literal[len(leading)+len(trimmed):]
+	redacted := rule.replace(s.unquote(trimmed), s.rules.Salt)
+	out := append([]byte{}, leading...)
+	if redacted == nil {
+		out = append(out, "NULL"...)
+	} else {
+		out = append(out, s.quote(redacted)...)
+	}
+	return append(out, trailing...)
+}
+
+// unquote decodes the string in a quoted literal, such as E'a\nb', where a
+// doubled quote is a single quote.
+// Anything else, such as a number, is used as is.
+func (s *sqlRedactor) unquote(literal []byte) []byte {
+	start := bytes.IndexByte(literal, '\'')
+	if start < 0 || literal[len(literal)-1] != '\'' || start == len(literal)-1 {
+		return literal
+	}
+	backslashes := s.mysql || (start > 0 && (literal[start-1] == 'E' || literal[start-1] == 'e'))
+	body := literal[start+1 : len(literal)-1]
+	out := make([]byte, 0, len(body))
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '\'' && i+1 < len(body) && body[i+1] == '\'':
+			out = append(out, '\'')
+			i++
+		case c == '\\' && backslashes && i+1 < len(body):
+			i++
+			switch body[i] {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case '0':
+				out = append(out, 0)
+			case 'Z':
+				out = append(out, 26)
+			default:
+				out = append(out, body[i])
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// quote encodes a value as a string literal for the database
+func (s *sqlRedactor) quote(value []byte) []byte {
+	out := make([]byte, 0, len(value)+2)
+	out = append(out, '\'')
+	for _, c := range value {
+		switch {
+		case c == '\'' && s.mysql:
+			out = append(out, '\\', '\'')
+		case c == '\'':
+			out = append(out, '\'', '\'')
+		case c == '\\' && s.mysql:
+			out = append(out, '\\', '\\')
+		case c == '\n' && s.mysql:
+			out = append(out, '\\', 'n')
+		case c == '\r' && s.mysql:
+			out = append(out, '\\', 'r')
+		case c == 0 && s.mysql:
+			out = append(out, '\\', '0')
+		default:
+			out = append(out, c)
+		}
+	}
+	return append(out, '\'')
+}
@@ -0,0 +1,196 @@
+package redact
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testSalt = "salt"
+
+// closeBuffer collects a redacted backup
+type closeBuffer struct {
+	bytes.Buffer
+}
+
+func (*closeBuffer) Close() error { return nil }
+
+func constant(value string) *string {
+	return &value
+}
+
+func redactString(rules *Rules, serviceName, backup string) (string, error) {
+	out := &closeBuffer{}
+	w, err := NewWriteCloser(out, rules, serviceName)
+	if err != nil {
+		return "", err
+	}
+	_, err = w.Write([]byte(backup))
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return out.String(), err
+}
+
+var sqlRedactTests = []struct {
+	description string
+	serviceName string
+	rules       []*Rule
+	backup      string
+	expected    string
+	expectErr   bool
+}{
+	{
+		"postgres COPY",
+		"postgresql",
+		[]*Rule{
+			{Table: "patients", Column: "name", Strategy: Hash},
+			{Table: "public.patients", Column: "email", Strategy: Constant, Value: constant("a\tb")},
+		},
+		"SET client_encoding = 'UTF8';\nCOPY public.patients (id, name, email) FROM stdin;\n1\tAnn\tann@example.com\n2\tBob\t\\N\n\\.\nCOPY public.visits (id, name) FROM stdin;\n1\tAnn\n\\.\n",
+		"SET client_encoding = 'UTF8';\nCOPY public.patients (id, name, email) FROM stdin;\n1\t" + hashValue([]byte("Ann"), testSalt) + "\ta\\tb\n2\t" + hashValue([]byte("Bob"), testSalt) + "\t\\N\n\\.\nCOPY public.visits (id, name) FROM stdin;\n1\tAnn\n\\.\n",
+		false,
+	},
+	{
+		"postgres COPY escapes",
+		"postgresql",
+		[]*Rule{{Table: "notes", Column: "body", Strategy: Fake}},
+		"COPY notes (id, body) FROM stdin;\n1\tAb\\n12\n\\.\n",
+		"COPY notes (id, body) FROM stdin;\n1\t" + string(escapeCopy(fakeValue([]byte("Ab\n12"), testSalt))) + "\n\\.\n",
+		false,
+	},
+	{
+		"postgres INSERT with columns",
+		"postgresql",
+		[]*Rule{{Table: "patients", Column: "name", Strategy: Constant, Value: constant("it's")}},
+		"INSERT INTO public.patients (id, name) VALUES (1, 'O''Brien');\nINSERT INTO public.patients (id, name) VALUES (2, NULL);\n",
+		"INSERT INTO public.patients (id, name) VALUES (1, 'it''s');\nINSERT INTO public.patients (id, name) VALUES (2, NULL);\n",
+		false,
+	},
+	{
+		"postgres INSERT across lines",
+		"postgresql",
+		[]*Rule{{Table: "notes", Column: "body", Strategy: Null}},
+		"CREATE TABLE public.notes (\n    id integer NOT NULL,\n    body text\n);\n\nINSERT INTO public.notes VALUES (1, 'first;\nsecond');\nINSERT INTO public.notes VALUES (2, 'third');\n",
+		"CREATE TABLE public.notes (\n    id integer NOT NULL,\n    body text\n);\n\nINSERT INTO public.notes VALUES (1, NULL);\nINSERT INTO public.notes VALUES (2, NULL);\n",
+		false,
+	},
+	{
+		"mysql extended INSERT",
+		"mysql",
+		[]*Rule{{Table: "patients", Column: "ssn", Strategy: Constant, Value: constant("x'y")}},
+		"CREATE TABLE `patients` (\n  `id` int(11) NOT NULL,\n  `ssn` varchar(11) DEFAULT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB;\nINSERT INTO `patients` VALUES (1,'123-45-6789'),(2,NULL),(3,'it\\'s (a), b');\n",
+		"CREATE TABLE `patients` (\n  `id` int(11) NOT NULL,\n  `ssn` varchar(11) DEFAULT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB;\nINSERT INTO `patients` VALUES (1,'x\\'y'),(2,NULL),(3,'x\\'y');\n",
+		false,
+	},
+	{
+		"mysql hash of escaped string",
+		"mysql",
+		[]*Rule{{Table: "patients", Column: "name", Strategy: Hash}},
+		"INSERT INTO `patients` (`id`, `name`) VALUES (1,'O\\'Brien');",
+		"INSERT INTO `patients` (`id`, `name`) VALUES (1,'" + hashValue([]byte("O'Brien"), testSalt) + "');",
+		false,
+	},
+	{
+		"postgres custom format",
+		"postgresql",
+		[]*Rule{{Table: "patients", Column: "name", Strategy: Null}},
+		"PGDMP\x01\x0e\x00",
+		"",
+		true,
+	},
+	{
+		"unsupported database",
+		"redis",
+		[]*Rule{{Table: "patients", Column: "name", Strategy: Null}},
+		"",
+		"",
+		true,
+	},
+}
+
+func TestRedactSQL(t *testing.T) {
+	for _, data := range sqlRedactTests {
+		t.Logf("Data: %+v", data)
+
+		// test
+		actual, err := redactString(&Rules{Salt: testSalt, Rules: data.rules}, data.serviceName, data.backup)
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if !data.expectErr && actual != data.expected {
+			t.Errorf("Unexpected redacted backup. Expected: %q, actual: %q", data.expected, actual)
+		}
+	}
+}
+
+func TestRedactUnmatched(t *testing.T) {
+	rules := &Rules{Rules: []*Rule{
+		{Table: "patients", Column: "name", Strategy: Null},
+		{Table: "visits", Column: "notes", Strategy: Null},
+		{Collection: "patients", Field: "name", Strategy: Null},
+	}}
+
+	// test
+	_, err := redactString(rules, "postgresql", "COPY public.patients (id, name) FROM stdin;\n\\.\n")
+
+	// assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	unmatched := rules.Unmatched("postgresql")
+	if len(unmatched) != 1 || unmatched[0] != rules.Rules[1] {
+		t.Errorf("Unexpected unmatched rules: %v", unmatched)
+	}
+}
+
+var loadTests = []struct {
+	description string
+	rules       string
+	expectErr   bool
+}{
+	{"valid", "salt: s\nrules:\n  - table: patients\n    column: ssn\n    strategy: \"null\"\n  - collection: visits\n    field: notes\n    strategy: constant\n    value: \"\"\n", false},
+	{"no rules", "salt: s\n", true},
+	{"table without column", "rules:\n  - table: patients\n    strategy: hash\n", true},
+	{"table and collection", "rules:\n  - table: patients\n    column: ssn\n    collection: patients\n    field: ssn\n    strategy: hash\n", true},
+	{"invalid strategy", "rules:\n  - table: patients\n    column: ssn\n    strategy: scramble\n", true},
+	{"constant without value", "rules:\n  - table: patients\n    column: ssn\n    strategy: constant\n", true},
+	{"invalid yaml", "rules: [", true},
+	{"no salt needed", "rules:\n  - table: patients\n    column: ssn\n    strategy: \"null\"\n  - table: patients\n    column: name\n    strategy: constant\n    value: x\n", false},
+	{"hash with salt", "salt: s\nrules:\n  - table: patients\n    column: ssn\n    strategy: hash\n  - collection: visits\n    field: notes\n    strategy: fake\n", false},
+	{"hash without salt", "rules:\n  - table: patients\n    column: ssn\n    strategy: \"null\"\n  - table: patients\n    column: email\n    strategy: hash\n", true},
+	{"fake without salt", "salt: \"\"\nrules:\n  - table: patients\n    column: ssn\n    strategy: \"null\"\n  - collection: visits\n    field: notes\n    strategy: fake\n", true},
+}
+
+func TestLoad(t *testing.T) {
+	f, err := ioutil.TempFile("", "redact-rules-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	for _, data := range loadTests {
+		t.Logf("Data: %+v", data)
+		ioutil.WriteFile(f.Name(), []byte(data.rules), 0600)
+
+		// test
+		rules, err := Load(f.Name())
+
+		// assert
+		if err != nil != data.expectErr {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if err != nil && !strings.HasPrefix(err.Error(), "Invalid redaction rules") {
+			t.Errorf("Unexpected error message: %s", err)
+		}
+		if err == nil && len(rules.Rules) != 2 {
+			t.Errorf("Expected 2 rules but found %d", len(rules.Rules))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand"
+	"unicode"
+	"unicode/utf8"
+)
+
+// replace returns the redacted form of value. A nil result means NULL.
+func (r *Rule) replace(value []byte, salt string) []byte {
+	switch r.Strategy {
+	case Hash:
+		return []byte(hashValue(value, salt))
+	case Fake:
+		return fakeValue(value, salt)
+	case Constant:
+		return []byte(*r.Value)
+	}
+	return nil
+}
+
+func hashValue(value []byte, salt string) string {
+	h := sha256.New()
+	h.Write([]byte(salt))
+	h.Write(value)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// fakeValue replaces every letter with a random lowercase or uppercase letter
+// and every digit with a random digit. Everything else is kept, so the result
+// keeps the shape of the original, such as an email address or phone number.
+// The random letters and digits are seeded from the salt and the value.
+func fakeValue(value []byte, salt string) []byte {
+	seed := sha256.Sum256(append([]byte(salt), value...))
+	rnd := rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(seed[:8]))))
+	faked := make([]byte, 0, len(value))
+	for len(value) > 0 {
+		c, size := utf8.DecodeRune(value)
+		original := value[:size]
+		value = value[size:]
+		switch {
+		case c >= '0' && c <= '9':
+			faked = append(faked, byte('0'+rnd.Intn(10)))
+		case unicode.IsUpper(c):
+			faked = append(faked, byte('A'+rnd.Intn(26)))
+		case unicode.IsLetter(c):
+			faked = append(faked, byte('a'+rnd.Intn(26)))
+		default:
+			faked = append(faked, original...)
+		}
+	}
+	return faked
+}
@@ -40,6 +40,7 @@ type ReaderTransfer struct {
 	length ByteSize
 	read   uint64
 	reader io.Reader
+	parent *ReaderTransfer
 }
 
 // NewReaderTransfer instantiates a ReadTransfer struct
@@ -54,9 +55,29 @@ func NewReaderTransfer(reader io.Reader, length int) *ReaderTransfer {
 func (rt *ReaderTransfer) Read(p []byte) (int, error) {
 	n, err := rt.reader.Read(p)
 	atomic.AddUint64(&rt.read, uint64(n))
+	if rt.parent != nil {
+		atomic.AddUint64(&rt.parent.read, uint64(n))
+	}
 	return n, err
 }
 
+// Part returns a ReaderTransfer for reader that also counts towards rt. Any
+// number of parts can be read at once, and rt reports their combined progress.
+func (rt *ReaderTransfer) Part(reader io.Reader, length int) *ReaderTransfer {
+	part := NewReaderTransfer(reader, length)
+	part.parent = rt
+	return part
+}
+
+// Reset takes what has been read so far back out of the parent's progress,
+// such as when a part failed and has to be read again
+func (rt *ReaderTransfer) Reset() {
+	read := atomic.SwapUint64(&rt.read, 0)
+	if rt.parent != nil {
+		atomic.AddUint64(&rt.parent.read, ^(read - 1))
+	}
+}
+
 func (rt *ReaderTransfer) Transferred() ByteSize {
 	return ByteSize(atomic.LoadUint64(&rt.read))
 }
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"context"
+
 	"github.com/jault3/mow.cli"
 )
 
@@ -119,12 +121,12 @@ type HTTPManager interface {
 	GetHeaders(sessionToken, version, pod, userID string) map[string][]string
 	ConvertResp(b []byte, statusCode int, s interface{}) error
 	ConvertError(b []byte, statuseCode int) (*Error, error)
-	Get(body []byte, url string, headers map[string][]string) ([]byte, int, error)
-	Post(body []byte, url string, headers map[string][]string) ([]byte, int, error)
-	PostFile(filepath string, url string, headers map[string][]string) ([]byte, int, error)
-	PutFile(filepath string, url string, headers map[string][]string) ([]byte, int, error)
-	Put(body []byte, url string, headers map[string][]string) ([]byte, int, error)
-	Delete(body []byte, url string, headers map[string][]string) ([]byte, int, error)
+	Get(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error)
+	Post(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error)
+	PostFile(ctx context.Context, filepath string, url string, headers map[string][]string) ([]byte, int, error)
+	PutFile(ctx context.Context, filepath string, url string, headers map[string][]string) ([]byte, int, error)
+	Put(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error)
+	Delete(ctx context.Context, body []byte, url string, headers map[string][]string) ([]byte, int, error)
 }
 
 // Image contains data about an image
@@ -284,6 +286,7 @@ type Job struct {
 	Backup           *EncryptionStore `json:"backup,omitempty"`
 	Restore          *EncryptionStore `json:"restore,omitempty"`
 	CreatedAt        string           `json:"created_at"`
+	UpdatedAt        string           `json:"updated_at,omitempty"`
 	MetricsData      *[]MetricsData   `json:"metrics"`
 	Spec             *Spec            `json:"spec"`
 	Target           string           `json:"target,omitempty"`
@@ -308,6 +311,20 @@ type Release struct {
 	Notes      string `json:"metadata,omitempty"`
 }
 
+// Profile holds the credentials, API hosts, and cached environments for a
+// single named account in the settings file
+type Profile struct {
+	AccountsHost   string                     `json:"accounts_host,omitempty"`
+	AuthHost       string                     `json:"auth_host,omitempty"`
+	PaasHost       string                     `json:"paas_host,omitempty"`
+	PrivateKeyPath string                     `json:"private_key_path"`
+	SessionToken   string                     `json:"token,omitempty"` // only set by older versions, tokens now live in the credential store
+	UsersID        string                     `json:"user_id"`
+	Environments   map[string]AssociatedEnvV2 `json:"environments"`
+	Pods           *[]Pod                     `json:"pods"`
+	PodCheck       int64                      `json:"pod_check"`
+}
+
 // ReportedError is the standard error model sent back from the API
 type ReportedError struct {
 	Code    int    `json:"id"`
@@ -403,6 +420,14 @@ type SettingsV2 struct {
 	Version         string      `json:"-"`
 	HTTPManager     HTTPManager `json:"-"`
 	GivenEnvName    string      `json:"-"`
+	OutputFormat    string      `json:"-"`
+	// Context is cancelled when the command is interrupted or its --timeout
+	// expires. All requests and polling for the current command use it.
+	Context context.Context `json:"-"`
+
+	Profile        string             `json:"-"` // the name of the profile used for the current command
+	CurrentProfile string             `json:"-"` // the profile used when no --profile is given
+	Profiles       map[string]Profile `json:"-"` // every profile in the settings file
 
 	Email           string                     `json:"-"`
 	Password        string                     `json:"-"`
@@ -419,6 +444,15 @@ type SettingsV2 struct {
 	Format          string                     `json:"format"`
 }
 
+// SettingsV3 is the format of the settings file. It holds any number of named
+// profiles, each with their own credentials and hosts. When loaded, the
+// selected profile is flattened onto a Settings object.
+type SettingsV3 struct {
+	CurrentProfile string             `json:"current_profile"`
+	Profiles       map[string]Profile `json:"profiles"`
+	Format         string             `json:"format"`
+}
+
 type Site struct {
 	ID              int                    `json:"id,omitempty"`
 	Name            string                 `json:"name"`
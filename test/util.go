@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -61,6 +62,7 @@ func GetSettings(baseURL string) *models.Settings {
 		SessionToken:   "token",
 		PrivateKeyPath: "ssh_rsa",
 		HTTPManager:    httpclient.NewTLSHTTPManager(false),
+		Context:        context.Background(),
 		PaasHost:       baseURL,
 		Environments: map[string]models.AssociatedEnvV2{
 			Alias: models.AssociatedEnvV2{